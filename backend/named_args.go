@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlaceholderStyle is the positional parameter syntax of a database driver
+type PlaceholderStyle int
+
+const (
+	// PlaceholderQuestion is the ? placeholder used by MySQL, SQLite and DuckDB
+	PlaceholderQuestion PlaceholderStyle = iota
+	// PlaceholderDollar is the $1, $2... placeholder used by PostgreSQL
+	PlaceholderDollar
+)
+
+// PlaceholderStyleForConnectionString returns the placeholder style of the backend of the connection string
+func PlaceholderStyleForConnectionString(connString string) PlaceholderStyle {
+	if IsPostgresConnectionString(connString) {
+		return PlaceholderDollar
+	}
+	return PlaceholderQuestion
+}
+
+// SqlDialect is the syntax of the queries of a database backend which affects how they are scanned
+type SqlDialect struct {
+	// Placeholders is the positional parameter syntax, dollar quoted strings are only recognised with the dollar style
+	Placeholders PlaceholderStyle
+	// BackslashEscapes is set when a backslash escapes the next character of a string literal, as it does in MySQL
+	BackslashEscapes bool
+}
+
+// SqlDialectForConnectionString returns the dialect of the backend of the connection string
+func SqlDialectForConnectionString(connString string) SqlDialect {
+	return SqlDialect{
+		Placeholders:     PlaceholderStyleForConnectionString(connString),
+		BackslashEscapes: IsMySqlConnectionString(connString),
+	}
+}
+
+// BindNamedArgs rewrites the :name parameters of the query to the positional placeholders of the dialect and returns
+// the args in placeholder order.
+//
+// Parameters inside string literals, quoted identifiers, comments and dollar quoted strings are left untouched, as
+// are PostgreSQL :: casts. A parameter used more than once is bound to a single $n placeholder with the dollar style
+// and repeated in the args with the question style. It is an error for a parameter to be missing from the args or
+// for an arg not to be used by the query.
+func BindNamedArgs(query string, args map[string]any, dialect SqlDialect) (string, []any, error) {
+	var sb strings.Builder
+	var positionalArgs []any
+	// the $n index of each parameter, for the dollar style
+	indexes := map[string]int{}
+	used := map[string]bool{}
+
+	for i := 0; i < len(query); {
+		if end := skipLiteral(query, i, dialect); end > i {
+			sb.WriteString(query[i:end])
+			i = end
+			continue
+		}
+
+		c := query[i]
+		if c == ':' {
+			// a :: cast
+			if strings.HasPrefix(query[i:], "::") {
+				sb.WriteString("::")
+				i += 2
+				continue
+			}
+
+			end := i + 1
+			for end < len(query) && isNameChar(query[end], end == i+1) {
+				end++
+			}
+			if end == i+1 {
+				sb.WriteByte(c)
+				i++
+				continue
+			}
+
+			name := query[i+1 : end]
+			value, ok := args[name]
+			if !ok {
+				return "", nil, fmt.Errorf("missing value for the named parameter :%s", name)
+			}
+			used[name] = true
+
+			switch dialect.Placeholders {
+			case PlaceholderDollar:
+				index, ok := indexes[name]
+				if !ok {
+					positionalArgs = append(positionalArgs, value)
+					index = len(positionalArgs)
+					indexes[name] = index
+				}
+				sb.WriteString("$" + strconv.Itoa(index))
+			default:
+				positionalArgs = append(positionalArgs, value)
+				sb.WriteString("?")
+			}
+			i = end
+			continue
+		}
+
+		sb.WriteByte(c)
+		i++
+	}
+
+	var unused []string
+	for name := range args {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", nil, fmt.Errorf("the named args %s are not used by the query", strings.Join(unused, ", "))
+	}
+
+	return sb.String(), positionalArgs, nil
+}
+
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+// skipLiteral returns the index after the string literal, quoted identifier, comment or dollar quoted string starting
+// at start, or start if there is none
+func skipLiteral(query string, start int, dialect SqlDialect) int {
+	c := query[start]
+	switch {
+	case c == '\'' || c == '"':
+		return skipQuoted(query, start, c, dialect.BackslashEscapes)
+
+	case c == '`':
+		return skipQuoted(query, start, c, false)
+
+	case c == '-' && strings.HasPrefix(query[start:], "--"):
+		end := strings.IndexByte(query[start:], '\n')
+		if end < 0 {
+			return len(query)
+		}
+		return start + end
+
+	case c == '/' && strings.HasPrefix(query[start:], "/*"):
+		end := strings.Index(query[start+2:], "*/")
+		if end < 0 {
+			return len(query)
+		}
+		return start + 2 + end + 2
+
+	case c == '$' && dialect.Placeholders == PlaceholderDollar:
+		if end, ok := skipDollarQuoted(query, start); ok {
+			return end
+		}
+	}
+	return start
+}
+
+// skipQuoted returns the index after the closing quote. A doubled quote is an escaped quote, as is a quote after a
+// backslash if backslashEscapes is set.
+func skipQuoted(query string, start int, quote byte, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		if backslashEscapes && query[i] == '\\' {
+			i++
+			continue
+		}
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// skipDollarQuoted returns the index after a PostgreSQL $tag$...$tag$ string starting at start
+func skipDollarQuoted(query string, start int) (int, bool) {
+	end := start + 1
+	for end < len(query) && isNameChar(query[end], end == start+1) {
+		end++
+	}
+	if end >= len(query) || query[end] != '$' {
+		return 0, false
+	}
+
+	tag := query[start : end+1]
+	closing := strings.Index(query[end+1:], tag)
+	if closing < 0 {
+		return len(query), true
+	}
+	return end + 1 + closing + len(tag), true
+}
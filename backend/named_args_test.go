@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindNamedArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		args     map[string]any
+		dialect  SqlDialect
+		wantSql  string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{
+			name:     "dollar",
+			query:    "select * from foo where a = :a and (b = :b or c = :b)",
+			args:     map[string]any{"a": 1, "b": "two"},
+			dialect:  SqlDialect{Placeholders: PlaceholderDollar},
+			wantSql:  "select * from foo where a = $1 and (b = $2 or c = $2)",
+			wantArgs: []any{1, "two"},
+		},
+		{
+			name:     "question",
+			query:    "select * from foo where a = :a and (b = :b or c = :b)",
+			args:     map[string]any{"a": 1, "b": "two"},
+			dialect:  SqlDialect{Placeholders: PlaceholderQuestion},
+			wantSql:  "select * from foo where a = ? and (b = ? or c = ?)",
+			wantArgs: []any{1, "two", "two"},
+		},
+		{
+			name:     "literals, comments and casts",
+			query:    "select ':x', \":x\", $tag$ :x $tag$, a::int -- :x\nfrom foo /* :x */ where a = :a",
+			args:     map[string]any{"a": 1},
+			dialect:  SqlDialect{Placeholders: PlaceholderDollar},
+			wantSql:  "select ':x', \":x\", $tag$ :x $tag$, a::int -- :x\nfrom foo /* :x */ where a = $1",
+			wantArgs: []any{1},
+		},
+		{
+			name:     "escaped quote",
+			query:    "select 'it''s :x' where a = :a",
+			args:     map[string]any{"a": 1},
+			dialect:  SqlDialect{Placeholders: PlaceholderQuestion},
+			wantSql:  "select 'it''s :x' where a = ?",
+			wantArgs: []any{1},
+		},
+		{
+			name:     "mysql backslash escape",
+			query:    "select 'it\\'s :x', \"a \\\" :x\" where a = :a",
+			args:     map[string]any{"a": 1},
+			dialect:  SqlDialect{Placeholders: PlaceholderQuestion, BackslashEscapes: true},
+			wantSql:  "select 'it\\'s :x', \"a \\\" :x\" where a = ?",
+			wantArgs: []any{1},
+		},
+		{
+			name:    "missing arg",
+			query:   "select * from foo where a = :a",
+			args:    map[string]any{},
+			dialect: SqlDialect{Placeholders: PlaceholderQuestion},
+			wantErr: true,
+		},
+		{
+			name:    "unused arg",
+			query:   "select * from foo where a = :a",
+			args:    map[string]any{"a": 1, "b": 2},
+			dialect: SqlDialect{Placeholders: PlaceholderQuestion},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSql, gotArgs, err := BindNamedArgs(tt.query, tt.args, tt.dialect)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("BindNamedArgs() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindNamedArgs() error = %v", err)
+			}
+			if gotSql != tt.wantSql {
+				t.Errorf("BindNamedArgs() sql = %q, want %q", gotSql, tt.wantSql)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("BindNamedArgs() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSqlDialectForConnectionString(t *testing.T) {
+	tests := map[string]SqlDialect{
+		"postgres://localhost:5432/db": {Placeholders: PlaceholderDollar},
+		"mysql://localhost:3306/db":    {Placeholders: PlaceholderQuestion, BackslashEscapes: true},
+		"sqlite:./my.db":               {Placeholders: PlaceholderQuestion},
+	}
+	for connString, want := range tests {
+		if got := SqlDialectForConnectionString(connString); got != want {
+			t.Errorf("SqlDialectForConnectionString(%s) = %v, want %v", connString, got, want)
+		}
+	}
+}
+
+func TestPlaceholderStyleForConnectionString(t *testing.T) {
+	tests := map[string]PlaceholderStyle{
+		"postgres://localhost:5432/db":   PlaceholderDollar,
+		"postgresql://localhost:5432/db": PlaceholderDollar,
+		"mysql://localhost:3306/db":      PlaceholderQuestion,
+		"sqlite:./my.db":                 PlaceholderQuestion,
+		"duckdb:./my.duckdb":             PlaceholderQuestion,
+	}
+	for connString, want := range tests {
+		if got := PlaceholderStyleForConnectionString(connString); got != want {
+			t.Errorf("PlaceholderStyleForConnectionString(%s) = %v, want %v", connString, got, want)
+		}
+	}
+}
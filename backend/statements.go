@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/sperr"
+)
+
+// IsolationLevels are the transaction isolation levels supported by the query step, in increasing strictness
+var IsolationLevels = []string{"default", "read_uncommitted", "read_committed", "repeatable_read", "serializable"}
+
+// ParseIsolationLevel returns the database/sql isolation level of the given isolation level name
+func ParseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch level {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read_uncommitted":
+		return sql.LevelReadUncommitted, nil
+	case "read_committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable_read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("invalid isolation level %s: must be one of %s", level, strings.Join(IsolationLevels, ", "))
+}
+
+// Statement is a single statement of a query step, with positional args
+type Statement struct {
+	Sql  string `json:"sql"`
+	Args []any  `json:"args,omitempty"`
+}
+
+// StatementResult is the output of a statement: the rows returned by a query or the number of rows affected by a
+// data modification statement
+type StatementResult struct {
+	Rows         []map[string]any `json:"rows"`
+	RowsAffected int64            `json:"rows_affected"`
+}
+
+type ExecuteOptions struct {
+	// Transaction runs the statements atomically, rolling back all of them if one fails
+	Transaction    bool
+	IsolationLevel sql.IsolationLevel
+	// Dialect is the syntax of the statements, used to find the statements which return rows
+	Dialect SqlDialect
+}
+
+// the leading keywords of the statements which return rows
+var rowsStatementKeywords = map[string]bool{
+	"select":   true,
+	"values":   true,
+	"table":    true,
+	"show":     true,
+	"pragma":   true,
+	"describe": true,
+	"desc":     true,
+	"explain":  true,
+}
+
+// the keywords which may start the main statement of a WITH query
+var withStatementKeywords = map[string]bool{
+	"select": true,
+	"values": true,
+	"table":  true,
+	"insert": true,
+	"update": true,
+	"delete": true,
+	"merge":  true,
+}
+
+// sqlKeyword is a word of a statement outside its literals and comments, with its parenthesis depth
+type sqlKeyword struct {
+	word  string
+	depth int
+}
+
+// sqlKeywords returns the lower cased words of the statement, skipping its literals and comments with the scanner of
+// BindNamedArgs
+func sqlKeywords(query string, dialect SqlDialect) []sqlKeyword {
+	var res []sqlKeyword
+	depth := 0
+	for i := 0; i < len(query); {
+		if end := skipLiteral(query, i, dialect); end > i {
+			i = end
+			continue
+		}
+
+		c := query[i]
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isNameChar(c, true):
+			end := i + 1
+			for end < len(query) && isNameChar(query[end], false) {
+				end++
+			}
+			res = append(res, sqlKeyword{word: strings.ToLower(query[i:end]), depth: depth})
+			i = end
+			continue
+		case c == '$' || (c >= '0' && c <= '9'):
+			// skip the rest of a placeholder or number, so it is not read as a word
+			end := i + 1
+			for end < len(query) && isNameChar(query[end], false) {
+				end++
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return res
+}
+
+// statementReturnsRows returns whether the statement is a query returning rows. The leading comments and parentheses
+// are skipped, the main statement of a WITH query is found after its common table expressions, and a data
+// modification statement returns rows if it has a RETURNING clause.
+func statementReturnsRows(query string, dialect SqlDialect) bool {
+	keywords := sqlKeywords(query, dialect)
+	if len(keywords) == 0 {
+		return false
+	}
+
+	first := keywords[0]
+	if rowsStatementKeywords[first.word] {
+		return true
+	}
+
+	mainStatement := keywords
+	if first.word == "with" {
+		mainStatement = nil
+		for i, keyword := range keywords[1:] {
+			if keyword.depth == first.depth && withStatementKeywords[keyword.word] {
+				mainStatement = keywords[i+1:]
+				break
+			}
+		}
+		if len(mainStatement) == 0 {
+			return false
+		}
+		if rowsStatementKeywords[mainStatement[0].word] {
+			return true
+		}
+	}
+
+	for _, keyword := range mainStatement {
+		if keyword.depth == first.depth && keyword.word == "returning" {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteStatements runs the statements in order, stopping at the first error, and returns a result per statement
+func ExecuteStatements(ctx context.Context, db *sql.DB, statements []Statement, opts ExecuteOptions) ([]StatementResult, error) {
+	if !opts.Transaction {
+		return executeStatements(ctx, db, statements, opts.Dialect)
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.IsolationLevel})
+	if err != nil {
+		return nil, sperr.WrapWithMessage(err, "could not start transaction")
+	}
+
+	results, err := executeStatements(ctx, tx, statements, opts.Dialect)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, sperr.WrapWithMessage(err, "rollback failed: %s", rollbackErr.Error())
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, sperr.WrapWithMessage(err, "could not commit transaction")
+	}
+	return results, nil
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func executeStatements(ctx context.Context, q queryer, statements []Statement, dialect SqlDialect) ([]StatementResult, error) {
+	results := make([]StatementResult, 0, len(statements))
+
+	for i, statement := range statements {
+		result, err := executeStatement(ctx, q, statement, dialect)
+		if err != nil {
+			return nil, sperr.WrapWithMessage(err, "statement %d failed", i+1)
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+func executeStatement(ctx context.Context, q queryer, statement Statement, dialect SqlDialect) (*StatementResult, error) {
+	if !statementReturnsRows(statement.Sql, dialect) {
+		res, err := q.ExecContext(ctx, statement.Sql, statement.Args...)
+		if err != nil {
+			return nil, err
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		return &StatementResult{Rows: []map[string]any{}, RowsAffected: rowsAffected}, nil
+	}
+
+	rows, err := q.QueryContext(ctx, statement.Sql, statement.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StatementResult{Rows: []map[string]any{}}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			// text columns may be returned as bytes
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// the rows of a RETURNING clause are the rows affected by the statement
+	result.RowsAffected = int64(len(result.Rows))
+	return result, nil
+}
@@ -0,0 +1,268 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingDriver is a database/sql driver which records the statements and transaction calls. Statements containing
+// "fail" return an error and queries return a single row with the query args.
+type recordingDriver struct {
+	mu  sync.Mutex
+	log []string
+}
+
+func (d *recordingDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("use BeginTx")
+}
+
+func (c *recordingConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.driver.record("begin " + sql.IsolationLevel(opts.Isolation).String())
+	return &recordingTx{driver: c.driver}, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("statement failed")
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query)
+	values := make([]driver.Value, len(args))
+	columns := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+		columns[i] = "col" + string(rune('a'+i))
+	}
+	return &recordingRows{columns: columns, values: values}, nil
+}
+
+type recordingTx struct {
+	driver *recordingDriver
+}
+
+func (t *recordingTx) Commit() error {
+	t.driver.record("commit")
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.driver.record("rollback")
+	return nil
+}
+
+type recordingRows struct {
+	columns []string
+	values  []driver.Value
+	done    bool
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.done = true
+	return nil
+}
+
+func TestExecuteStatements(t *testing.T) {
+	tests := []struct {
+		name        string
+		statements  []Statement
+		opts        ExecuteOptions
+		wantLog     []string
+		wantResults []StatementResult
+		wantErr     bool
+	}{
+		{
+			name: "transaction",
+			statements: []Statement{
+				{Sql: "insert into orders values (?)", Args: []any{"widget"}},
+				{Sql: "select ?, ?", Args: []any{"widget", []byte("bytes")}},
+			},
+			opts:    ExecuteOptions{Transaction: true, IsolationLevel: sql.LevelSerializable},
+			wantLog: []string{"begin Serializable", "insert into orders values (?)", "select ?, ?", "commit"},
+			wantResults: []StatementResult{
+				{Rows: []map[string]any{}, RowsAffected: 2},
+				{Rows: []map[string]any{{"cola": "widget", "colb": "bytes"}}, RowsAffected: 1},
+			},
+		},
+		{
+			name: "transaction rollback",
+			statements: []Statement{
+				{Sql: "insert into orders values (1)"},
+				{Sql: "update fail"},
+				{Sql: "update stock"},
+			},
+			opts:    ExecuteOptions{Transaction: true},
+			wantLog: []string{"begin Default", "insert into orders values (1)", "update fail", "rollback"},
+			wantErr: true,
+		},
+		{
+			name: "no transaction",
+			statements: []Statement{
+				{Sql: "insert into orders values (1)"},
+				{Sql: "update fail"},
+				{Sql: "update stock"},
+			},
+			wantLog: []string{"insert into orders values (1)", "update fail"},
+			wantErr: true,
+		},
+		{
+			name: "returning",
+			statements: []Statement{
+				{Sql: "insert into orders values (?) returning id", Args: []any{int64(1)}},
+			},
+			wantLog: []string{"insert into orders values (?) returning id"},
+			wantResults: []StatementResult{
+				{Rows: []map[string]any{{"cola": int64(1)}}, RowsAffected: 1},
+			},
+		},
+		{
+			name: "leading comment",
+			statements: []Statement{
+				{Sql: "-- note\nselect ?", Args: []any{"widget"}},
+			},
+			wantLog: []string{"-- note\nselect ?"},
+			wantResults: []StatementResult{
+				{Rows: []map[string]any{{"cola": "widget"}}, RowsAffected: 1},
+			},
+		},
+		{
+			name: "returning in a string",
+			statements: []Statement{
+				{Sql: "update orders set note = 'returning'"},
+			},
+			wantLog: []string{"update orders set note = 'returning'"},
+			wantResults: []StatementResult{
+				{Rows: []map[string]any{}, RowsAffected: 2},
+			},
+		},
+		{
+			name: "with delete",
+			statements: []Statement{
+				{Sql: "with old as (select id from orders where done) delete from orders where id in (select id from old)"},
+			},
+			wantLog: []string{"with old as (select id from orders where done) delete from orders where id in (select id from old)"},
+			wantResults: []StatementResult{
+				{Rows: []map[string]any{}, RowsAffected: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &recordingDriver{}
+			db := sql.OpenDB(driverConnector{d})
+			defer db.Close()
+
+			results, err := ExecuteStatements(context.Background(), db, tt.statements, tt.opts)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ExecuteStatements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("ExecuteStatements() results = %v, want %v", results, tt.wantResults)
+			}
+			if !reflect.DeepEqual(d.log, tt.wantLog) {
+				t.Errorf("ExecuteStatements() log = %v, want %v", d.log, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestStatementReturnsRows(t *testing.T) {
+	mysql := SqlDialect{Placeholders: PlaceholderQuestion, BackslashEscapes: true}
+	postgres := SqlDialect{Placeholders: PlaceholderDollar}
+
+	tests := []struct {
+		query   string
+		dialect SqlDialect
+		want    bool
+	}{
+		{query: "select 1", want: true},
+		{query: "  SELECT 1", want: true},
+		{query: "-- note\nselect 1", want: true},
+		{query: "/* note */ select 1", want: true},
+		{query: "(select 1) union (select 2)", want: true},
+		{query: "values (1), (2)", want: true},
+		{query: "pragma table_info(orders)", want: true},
+		{query: "with recent as (select * from orders) select * from recent", want: true},
+		{query: "with recent as (select * from orders) delete from orders where id in (select id from recent)", want: false},
+		{query: "with recent as (delete from orders returning id) select * from recent", want: true},
+		{query: "with recent as (delete from orders returning id) insert into archive select * from recent", want: false},
+		{query: "with recent as (select id from orders) delete from orders returning id", want: true},
+		{query: "insert into orders values (1)", want: false},
+		{query: "insert into orders values (1) returning id", want: true},
+		{query: "INSERT INTO orders VALUES ($1) RETURNING *", dialect: postgres, want: true},
+		{query: "update orders set note = 'returning'", want: false},
+		{query: `update orders set "returning" = 1`, want: false},
+		{query: "update orders set note = $$ returning $$", dialect: postgres, want: false},
+		{query: "update orders set note = 'it\\'s returning'", dialect: mysql, want: false},
+		{query: "delete from orders -- returning id", want: false},
+		{query: "create table returning_orders (id int)", want: false},
+		{query: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := statementReturnsRows(tt.query, tt.dialect); got != tt.want {
+			t.Errorf("statementReturnsRows(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	for _, level := range IsolationLevels {
+		if _, err := ParseIsolationLevel(level); err != nil {
+			t.Errorf("ParseIsolationLevel(%s) error = %v", level, err)
+		}
+	}
+	if _, err := ParseIsolationLevel("snapshot"); err == nil {
+		t.Errorf("ParseIsolationLevel(snapshot) expected an error")
+	}
+}
+
+type driverConnector struct {
+	driver *recordingDriver
+}
+
+func (c driverConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c driverConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -2,11 +2,18 @@ package connection
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/app_specific"
 	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
@@ -15,6 +22,11 @@ import (
 const (
 	AwsConnectionType = "aws"
 	defaultAwsTtl     = 5 * 60
+	defaultAwsRegion  = "us-east-1"
+
+	// the limits STS puts on the duration of an assumed role session
+	minAwsSessionDuration = 15 * time.Minute
+	maxAwsSessionDuration = 12 * time.Hour
 )
 
 type AwsConnection struct {
@@ -23,7 +35,24 @@ type AwsConnection struct {
 	AccessKey    *string `json:"access_key,omitempty" cty:"access_key" hcl:"access_key,optional"`
 	SecretKey    *string `json:"secret_key,omitempty" cty:"secret_key" hcl:"secret_key,optional"`
 	SessionToken *string `json:"session_token,omitempty" cty:"session_token" hcl:"session_token,optional"`
-	Profile      *string `json:"profile,omitempty" cty:"profile" hcl:"profile,optional"`
+	// Profile may be any profile of the shared config, including an SSO profile with a cached SSO login
+	Profile *string `json:"profile,omitempty" cty:"profile" hcl:"profile,optional"`
+	Region  *string `json:"region,omitempty" cty:"region" hcl:"region,optional"`
+
+	// RoleArn is the role assumed with the credentials above, or with the web identity token
+	RoleArn    *string `json:"role_arn,omitempty" cty:"role_arn" hcl:"role_arn,optional"`
+	ExternalId *string `json:"external_id,omitempty" cty:"external_id" hcl:"external_id,optional"`
+	// RoleChain holds the roles assumed in order before RoleArn, each with the session of the previous one
+	RoleChain       []string `json:"role_chain,omitempty" cty:"role_chain" hcl:"role_chain,optional"`
+	SessionName     *string  `json:"session_name,omitempty" cty:"session_name" hcl:"session_name,optional"`
+	SessionDuration *string  `json:"session_duration,omitempty" cty:"session_duration" hcl:"session_duration,optional"`
+	// WebIdentityTokenFile holds the OIDC token the first role is assumed with
+	WebIdentityTokenFile *string `json:"web_identity_token_file,omitempty" cty:"web_identity_token_file" hcl:"web_identity_token_file,optional"`
+	// StsEndpoint overrides the endpoint of the STS calls
+	StsEndpoint *string `json:"sts_endpoint,omitempty" cty:"sts_endpoint" hcl:"sts_endpoint,optional"`
+
+	// Expiration of the resolved temporary credentials, nil if they do not expire
+	Expiration *time.Time `json:"expiration,omitempty"`
 }
 
 func NewAwsConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -45,48 +74,167 @@ func (c *AwsConnection) Resolve(ctx context.Context) (PipelingConnection, error)
 		return c.Pipes.Resolve(ctx, &AwsConnection{})
 	}
 
-	// if access key and secret key are provided, just return it
-	if c.AccessKey != nil && c.SecretKey != nil {
+	// if access key and secret key are provided and there is no role to assume, just return it
+	if c.AccessKey != nil && c.SecretKey != nil && c.RoleArn == nil {
 		return c, nil
 	}
 
-	var cfg aws.Config
-	var err error
-
-	// Load the AWS configuration from the shared credentials file
+	opts := []func(*config.LoadOptions) error{config.WithRegion(c.GetRegion())}
 	if c.Profile != nil {
-		cfg, err = config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(*c.Profile), config.WithRegion("us-east-1"))
-		if err != nil {
-			return nil, err
+		// Load the AWS configuration from the shared config, this resolves SSO profiles from the cached SSO login
+		opts = append(opts, config.WithSharedConfigProfile(*c.Profile))
+	}
+	if c.AccessKey != nil && c.SecretKey != nil {
+		sessionToken := ""
+		if c.SessionToken != nil {
+			sessionToken = *c.SessionToken
 		}
-	} else {
-		cfg, err = config.LoadDefaultConfig(context.TODO(), config.WithRegion("us-east-1"))
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(*c.AccessKey, *c.SecretKey, sessionToken)))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RoleArn != nil {
+		cfg.Credentials, err = c.assumeRoleProvider(cfg)
 		if err != nil {
 			return nil, err
 		}
-
 	}
 
 	// Access the credentials from the configuration
-	creds, err := cfg.Credentials.Retrieve(context.TODO())
+	creds, err := cfg.Credentials.Retrieve(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Don't modify existing credential, resolve to a new one
+	// Don't modify existing connection, resolve to a new one
 	newConnection := &AwsConnection{
 		ConnectionImpl: c.ConnectionImpl,
 		AccessKey:      &creds.AccessKeyID,
 		SecretKey:      &creds.SecretAccessKey,
+		Region:         c.Region,
+		StsEndpoint:    c.StsEndpoint,
 	}
 
 	if creds.SessionToken != "" {
 		newConnection.SessionToken = &creds.SessionToken
 	}
 
+	if creds.CanExpire {
+		newConnection.Expiration = &creds.Expires
+		// do not cache the connection beyond the expiry of its credentials
+		if ttl := int(time.Until(creds.Expires).Seconds()); ttl < newConnection.Ttl {
+			newConnection.Ttl = max(ttl, 0)
+		}
+	}
+
 	return newConnection, nil
 }
 
+// assumeRoleProvider returns the provider of the credentials of RoleArn, assumed at the end of RoleChain from the
+// credentials of the config or from the web identity token
+func (c *AwsConnection) assumeRoleProvider(cfg aws.Config) (aws.CredentialsProvider, error) {
+	duration, err := c.sessionDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionName := c.sessionName()
+	roles := append(append([]string{}, c.RoleChain...), *c.RoleArn)
+
+	provider := cfg.Credentials
+	for i, role := range roles {
+		client := sts.NewFromConfig(cfg, func(o *sts.Options) {
+			o.Credentials = provider
+			if c.StsEndpoint != nil {
+				o.BaseEndpoint = c.StsEndpoint
+			}
+		})
+
+		var next aws.CredentialsProvider
+		if i == 0 && c.WebIdentityTokenFile != nil {
+			next = stscreds.NewWebIdentityRoleProvider(client, role, stscreds.IdentityTokenFile(*c.WebIdentityTokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = sessionName
+				o.Duration = duration
+			})
+		} else {
+			next = stscreds.NewAssumeRoleProvider(client, role, func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = sessionName
+				if duration != 0 {
+					o.Duration = duration
+				}
+				// the external id is given by the owner of the target role
+				if i == len(roles)-1 {
+					o.ExternalID = c.ExternalId
+				}
+			})
+		}
+		provider = aws.NewCredentialsCache(next)
+	}
+
+	return provider, nil
+}
+
+// Test calls sts GetCallerIdentity with the credentials of the resolved connection
+func (c *AwsConnection) Test(ctx context.Context) error {
+	if c.AccessKey == nil || c.SecretKey == nil {
+		return fmt.Errorf("access_key and secret_key must be set")
+	}
+
+	sessionToken := ""
+	if c.SessionToken != nil {
+		sessionToken = *c.SessionToken
+	}
+	client := sts.New(sts.Options{
+		Region:       c.GetRegion(),
+		Credentials:  credentials.NewStaticCredentialsProvider(*c.AccessKey, *c.SecretKey, sessionToken),
+		BaseEndpoint: c.StsEndpoint,
+	})
+
+	_, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	return err
+}
+
+func (c *AwsConnection) GetExpiration() *time.Time {
+	return c.Expiration
+}
+
+func (c *AwsConnection) GetRegion() string {
+	if c.Region != nil {
+		return *c.Region
+	}
+	return defaultAwsRegion
+}
+
+func (c *AwsConnection) sessionName() string {
+	if c.SessionName != nil {
+		return *c.SessionName
+	}
+	name := app_specific.AppName
+	if name == "" {
+		name = "pipeling"
+	}
+	return fmt.Sprintf("%s-%s-%d", name, c.ShortName, time.Now().Unix())
+}
+
+// sessionDuration returns the duration of the assumed role sessions, zero for the STS default
+func (c *AwsConnection) sessionDuration() (time.Duration, error) {
+	if c.SessionDuration == nil {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(*c.SessionDuration)
+	if err != nil {
+		return 0, err
+	}
+	if d < minAwsSessionDuration || d > maxAwsSessionDuration {
+		return 0, fmt.Errorf("session_duration %s must be between %s and %s", *c.SessionDuration, minAwsSessionDuration, maxAwsSessionDuration)
+	}
+	return d, nil
+}
+
 func (c *AwsConnection) Equals(otherConnection PipelingConnection) bool {
 	// If both pointers are nil, they are considered equal
 	if c == nil && helpers.IsNil(otherConnection) {
@@ -114,11 +262,44 @@ func (c *AwsConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.Region, other.Region) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.RoleArn, other.RoleArn) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.ExternalId, other.ExternalId) {
+		return false
+	}
+
+	if !slices.Equal(c.RoleChain, other.RoleChain) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.SessionName, other.SessionName) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.SessionDuration, other.SessionDuration) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.WebIdentityTokenFile, other.WebIdentityTokenFile) {
+		return false
+	}
+
+	if !utils.PtrEqual(c.StsEndpoint, other.StsEndpoint) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
 func (c *AwsConnection) Validate() hcl.Diagnostics {
-	if c.Pipes != nil && (c.AccessKey != nil || c.SecretKey != nil || c.Profile != nil || c.SessionToken != nil) {
+	if c.Pipes != nil && (c.AccessKey != nil || c.SecretKey != nil || c.Profile != nil || c.SessionToken != nil ||
+		c.RoleArn != nil || c.WebIdentityTokenFile != nil) {
 		return hcl.Diagnostics{
 			{
 				Severity: hcl.DiagError,
@@ -148,6 +329,49 @@ func (c *AwsConnection) Validate() hcl.Diagnostics {
 		}
 	}
 
+	if c.RoleArn == nil {
+		for _, attr := range []struct {
+			name string
+			set  bool
+		}{
+			{"external_id", c.ExternalId != nil},
+			{"role_chain", len(c.RoleChain) > 0},
+			{"session_name", c.SessionName != nil},
+			{"session_duration", c.SessionDuration != nil},
+			{"web_identity_token_file", c.WebIdentityTokenFile != nil},
+		} {
+			if attr.set {
+				return hcl.Diagnostics{
+					{
+						Severity: hcl.DiagError,
+						Summary:  attr.name + " defined without role_arn",
+						Subject:  c.DeclRange.HclRangePointer(),
+					},
+				}
+			}
+		}
+	}
+
+	if c.WebIdentityTokenFile != nil && (c.AccessKey != nil || c.Profile != nil) {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "web_identity_token_file cannot be defined with access_key or profile",
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
+	if _, err := c.sessionDuration(); err != nil {
+		return hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "invalid session_duration: " + err.Error(),
+				Subject:  c.DeclRange.HclRangePointer(),
+			},
+		}
+	}
+
 	return hcl.Diagnostics{}
 }
 
@@ -159,6 +383,9 @@ func (c *AwsConnection) CtyValue() (cty.Value, error) {
 
 	valueMap := ctyValue.AsValueMap()
 	valueMap["env"] = cty.ObjectVal(c.GetEnv())
+	if c.Expiration != nil {
+		valueMap["expiration"] = cty.StringVal(c.Expiration.Format(time.RFC3339))
+	}
 
 	return cty.ObjectVal(valueMap), nil
 }
@@ -174,5 +401,9 @@ func (c *AwsConnection) GetEnv() map[string]cty.Value {
 	if c.SessionToken != nil {
 		env["AWS_SESSION_TOKEN"] = cty.StringVal(*c.SessionToken)
 	}
+	if c.Region != nil {
+		env["AWS_REGION"] = cty.StringVal(*c.Region)
+		env["AWS_DEFAULT_REGION"] = cty.StringVal(*c.Region)
+	}
 	return env
 }
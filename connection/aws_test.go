@@ -0,0 +1,203 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+type stsCall struct {
+	action     string
+	roleArn    string
+	externalId string
+	duration   string
+	token      string
+	// access key the request is signed with, empty for unsigned requests
+	signedWith string
+}
+
+// stsStandIn answers AssumeRole and AssumeRoleWithWebIdentity with credentials named after the call number
+type stsStandIn struct {
+	mut        sync.Mutex
+	calls      []stsCall
+	expiration time.Time
+	// release, if set, holds the responses until it is closed
+	release chan struct{}
+}
+
+func (s *stsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	call := stsCall{
+		action:     r.Form.Get("Action"),
+		roleArn:    r.Form.Get("RoleArn"),
+		externalId: r.Form.Get("ExternalId"),
+		duration:   r.Form.Get("DurationSeconds"),
+		token:      r.Form.Get("WebIdentityToken"),
+	}
+	if _, after, ok := strings.Cut(r.Header.Get("Authorization"), "Credential="); ok {
+		call.signedWith, _, _ = strings.Cut(after, "/")
+	}
+
+	s.mut.Lock()
+	s.calls = append(s.calls, call)
+	n := len(s.calls)
+	s.mut.Unlock()
+
+	if s.release != nil {
+		<-s.release
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	if call.action == "GetCallerIdentity" {
+		_, _ = fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>arn:aws:iam::111111111111:user/test</Arn>
+    <UserId>AIDA%[1]d</UserId>
+    <Account>111111111111</Account>
+  </GetCallerIdentityResult>
+  <ResponseMetadata>
+    <RequestId>request%[1]d</RequestId>
+  </ResponseMetadata>
+</GetCallerIdentityResponse>`, n)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <%[1]sResult>
+    <Credentials>
+      <AccessKeyId>ASIA%[2]d</AccessKeyId>
+      <SecretAccessKey>secret%[2]d</SecretAccessKey>
+      <SessionToken>token%[2]d</SessionToken>
+      <Expiration>%[3]s</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>%[4]s/session</Arn>
+      <AssumedRoleId>AROA%[2]d:session</AssumedRoleId>
+    </AssumedRoleUser>
+  </%[1]sResult>
+  <ResponseMetadata>
+    <RequestId>request%[2]d</RequestId>
+  </ResponseMetadata>
+</%[1]sResponse>`, call.action, n, s.expiration.UTC().Format(time.RFC3339), call.roleArn)
+}
+
+func newStsStandIn(t *testing.T) (*stsStandIn, string) {
+	t.Helper()
+
+	// do not pick up the shared config, the environment or the instance metadata of the machine running the tests
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE"} {
+		t.Setenv(env, "")
+	}
+
+	standIn := &stsStandIn{expiration: time.Now().Add(10 * time.Minute).Truncate(time.Second)}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+	return standIn, server.URL
+}
+
+func TestAwsConnectionAssumeRoleChain(t *testing.T) {
+	assert := assert.New(t)
+	standIn, endpoint := newStsStandIn(t)
+
+	conn := &AwsConnection{
+		ConnectionImpl:  ConnectionImpl{ShortName: "deploy", Ttl: defaultAwsTtl},
+		AccessKey:       utils.ToStringPointer("AKIASOURCE"),
+		SecretKey:       utils.ToStringPointer("source"),
+		Region:          utils.ToStringPointer("eu-west-2"),
+		RoleChain:       []string{"arn:aws:iam::111111111111:role/jump"},
+		RoleArn:         utils.ToStringPointer("arn:aws:iam::222222222222:role/deploy"),
+		ExternalId:      utils.ToStringPointer("partner"),
+		SessionDuration: utils.ToStringPointer("1h"),
+		StsEndpoint:     &endpoint,
+	}
+	require.Len(t, conn.Validate(), 0)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+
+	// each role is assumed with the credentials of the previous one, the external id is only given to the last role
+	require.Len(t, standIn.calls, 2)
+	assert.Equal(stsCall{action: "AssumeRole", roleArn: "arn:aws:iam::111111111111:role/jump", duration: "3600", signedWith: "AKIASOURCE"}, standIn.calls[0])
+	assert.Equal(stsCall{action: "AssumeRole", roleArn: "arn:aws:iam::222222222222:role/deploy", externalId: "partner", duration: "3600", signedWith: "ASIA1"}, standIn.calls[1])
+
+	awsConn := resolved.(*AwsConnection)
+	assert.Equal("ASIA2", *awsConn.AccessKey)
+	assert.Equal("secret2", *awsConn.SecretKey)
+	assert.Equal("token2", *awsConn.SessionToken)
+	assert.Equal("eu-west-2", awsConn.GetRegion())
+	require.NotNil(t, awsConn.Expiration)
+	assert.True(standIn.expiration.Equal(*awsConn.Expiration))
+	// the resolved connection is not cached beyond the expiry of its credentials
+	assert.Equal(defaultAwsTtl, awsConn.GetTtl())
+
+	ctyValue, err := awsConn.CtyValue()
+	require.NoError(t, err)
+	assert.Equal(standIn.expiration.UTC().Format(time.RFC3339), ctyValue.GetAttr("expiration").AsString())
+	assert.Equal("eu-west-2", ctyValue.GetAttr("env").GetAttr("AWS_REGION").AsString())
+}
+
+func TestAwsConnectionWebIdentity(t *testing.T) {
+	assert := assert.New(t)
+	standIn, endpoint := newStsStandIn(t)
+	standIn.expiration = time.Now().Add(2 * time.Minute)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("oidc-token"), 0600))
+
+	conn := &AwsConnection{
+		ConnectionImpl:       ConnectionImpl{ShortName: "ci", Ttl: defaultAwsTtl},
+		RoleArn:              utils.ToStringPointer("arn:aws:iam::111111111111:role/ci"),
+		SessionName:          utils.ToStringPointer("ci-run"),
+		WebIdentityTokenFile: &tokenFile,
+		StsEndpoint:          &endpoint,
+	}
+	require.Len(t, conn.Validate(), 0)
+
+	resolved, err := conn.Resolve(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, standIn.calls, 1)
+	assert.Equal("AssumeRoleWithWebIdentity", standIn.calls[0].action)
+	assert.Equal("oidc-token", standIn.calls[0].token)
+
+	awsConn := resolved.(*AwsConnection)
+	assert.Equal("ASIA1", *awsConn.AccessKey)
+	assert.Equal("us-east-1", awsConn.GetRegion())
+	// the credentials expire before the default ttl, so does the resolved connection
+	assert.LessOrEqual(awsConn.GetTtl(), 2*60)
+}
+
+func TestAwsConnectionResolveCancelled(t *testing.T) {
+	_, endpoint := newStsStandIn(t)
+
+	conn := &AwsConnection{
+		AccessKey:   utils.ToStringPointer("AKIASOURCE"),
+		SecretKey:   utils.ToStringPointer("source"),
+		RoleArn:     utils.ToStringPointer("arn:aws:iam::111111111111:role/deploy"),
+		StsEndpoint: &endpoint,
+	}
+
+	// the context given to Resolve is used for the STS calls
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := conn.Resolve(ctx)
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package connection
 
 import (
 	"context"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
@@ -9,7 +10,11 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-const DuckDbConnectionType = "duckdb"
+const (
+	DuckDbConnectionType = "duckdb"
+
+	duckDBConnectionStringPrefix = "duckdb:"
+)
 
 type DuckDbConnection struct {
 	ConnectionImpl
@@ -88,3 +93,12 @@ func (c *DuckDbConnection) GetConnectionString() string {
 	}
 	return ""
 }
+
+// Test opens the database read only and pings it, it fails if the database file does not exist
+func (c *DuckDbConnection) Test(ctx context.Context) error {
+	filePath, query, err := databaseFile(strings.TrimPrefix(c.GetConnectionString(), duckDBConnectionStringPrefix))
+	if err != nil {
+		return err
+	}
+	return testDatabase(ctx, duckDBConnectionStringPrefix+filePath+"?"+withQueryParam(query, "access_mode=READ_ONLY"))
+}
@@ -2,21 +2,28 @@ package connection
 
 import (
 	"context"
-	"github.com/turbot/pipe-fittings/cty_helpers"
+	"net/http"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const GithubConnectionType = "github"
+const (
+	GithubConnectionType = "github"
+
+	defaultGithubBaseUrl = "https://api.github.com"
+)
 
 type GithubConnection struct {
 	ConnectionImpl
 
 	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	// BaseURL is the base URL of the API, e.g. of GitHub Enterprise Server, it defaults to https://api.github.com
+	BaseURL *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 }
 
 func (c *GithubConnection) Resolve(ctx context.Context) (PipelingConnection, error) {
@@ -32,6 +39,7 @@ func (c *GithubConnection) Resolve(ctx context.Context) (PipelingConnection, err
 		newConnection := &GithubConnection{
 			ConnectionImpl: c.ConnectionImpl,
 			Token:          &githubAccessTokenEnvVar,
+			BaseURL:        c.BaseURL,
 		}
 
 		return newConnection, nil
@@ -67,6 +75,10 @@ func (c *GithubConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.BaseURL, other.BaseURL) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
@@ -102,3 +114,15 @@ func (c *GithubConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Test gets the authenticated user
+func (c *GithubConnection) Test(ctx context.Context) error {
+	headers, err := bearerAuthHeaders(c.Token)
+	if err != nil {
+		return err
+	}
+	headers["Accept"] = "application/vnd.github+json"
+
+	_, err = testApi(ctx, http.MethodGet, apiUrl(c.BaseURL, defaultGithubBaseUrl, "/user"), headers)
+	return err
+}
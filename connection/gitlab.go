@@ -2,6 +2,8 @@ package connection
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
@@ -11,12 +13,18 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-const GitLabConnectionType = "gitlab"
+const (
+	GitLabConnectionType = "gitlab"
+
+	defaultGitLabBaseUrl = "https://gitlab.com/api/v4"
+)
 
 type GitLabConnection struct {
 	ConnectionImpl
 
 	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	// BaseURL is the base URL of the API, e.g. of a self-managed GitLab instance, it defaults to https://gitlab.com/api/v4
+	BaseURL *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 }
 
 func NewGitLabConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -41,6 +49,7 @@ func (c *GitLabConnection) Resolve(ctx context.Context) (PipelingConnection, err
 		newConnection := &GitLabConnection{
 			ConnectionImpl: c.ConnectionImpl,
 			Token:          &gitlabAccessTokenEnvVar,
+			BaseURL:        c.BaseURL,
 		}
 
 		return newConnection, nil
@@ -67,6 +76,10 @@ func (c *GitLabConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.BaseURL, other.BaseURL) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
@@ -100,3 +113,13 @@ func (c *GitLabConnection) GetEnv() map[string]cty.Value {
 	// https://github.com/xanzy/go-gitlab
 	return nil
 }
+
+// Test gets the authenticated user
+func (c *GitLabConnection) Test(ctx context.Context) error {
+	if c.Token == nil || *c.Token == "" {
+		return fmt.Errorf("no token is set")
+	}
+
+	_, err := testApi(ctx, http.MethodGet, apiUrl(c.BaseURL, defaultGitLabBaseUrl, "/user"), map[string]string{"PRIVATE-TOKEN": *c.Token})
+	return err
+}
@@ -2,7 +2,11 @@ package connection
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
@@ -119,3 +123,19 @@ func (c *JiraConnection) CtyValue() (cty.Value, error) {
 func (c *JiraConnection) GetEnv() map[string]cty.Value {
 	return nil
 }
+
+// Test gets the authenticated user from the base url of the connection
+func (c *JiraConnection) Test(ctx context.Context) error {
+	if c.BaseURL == nil || *c.BaseURL == "" {
+		return fmt.Errorf("no base_url is set")
+	}
+	if c.Username == nil || c.APIToken == nil || *c.APIToken == "" {
+		return fmt.Errorf("username and api_token must be set")
+	}
+
+	_, err := testApi(ctx, http.MethodGet, strings.TrimSuffix(*c.BaseURL, "/")+"/rest/api/2/myself", map[string]string{
+		"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(*c.Username+":"+*c.APIToken)),
+		"Accept":        "application/json",
+	})
+	return err
+}
@@ -2,6 +2,7 @@ package connection
 
 import (
 	"context"
+	"net/http"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
@@ -11,12 +12,18 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
-const OpenAIConnectionType = "openai"
+const (
+	OpenAIConnectionType = "openai"
+
+	defaultOpenAIBaseUrl = "https://api.openai.com/v1"
+)
 
 type OpenAIConnection struct {
 	ConnectionImpl
 
 	APIKey *string `json:"api_key,omitempty" cty:"api_key" hcl:"api_key,optional"`
+	// BaseURL is the base URL of the API, e.g. of an OpenAI compatible API, it defaults to https://api.openai.com/v1
+	BaseURL *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 }
 
 func NewOpenAIConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -41,6 +48,7 @@ func (c *OpenAIConnection) Resolve(ctx context.Context) (PipelingConnection, err
 		newConnection := &OpenAIConnection{
 			ConnectionImpl: c.ConnectionImpl,
 			APIKey:         &apiKeyEnvVar,
+			BaseURL:        c.BaseURL,
 		}
 
 		return newConnection, nil
@@ -67,6 +75,10 @@ func (c *OpenAIConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.BaseURL, other.BaseURL) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
@@ -102,3 +114,14 @@ func (c *OpenAIConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Test lists the models
+func (c *OpenAIConnection) Test(ctx context.Context) error {
+	headers, err := bearerAuthHeaders(c.APIKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = testApi(ctx, http.MethodGet, apiUrl(c.BaseURL, defaultOpenAIBaseUrl, "/models"), headers)
+	return err
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/httpclient"
 	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/utils"
 	"io"
 	"net/http"
 	"os"
@@ -17,6 +19,10 @@ import (
 
 const defaultCloudHost = "pipes.turbot.com"
 
+// DefaultPipesCredApiTimeout is the timeout of the Pipes credential request when the workspace profile does not set
+// an http timeout
+const DefaultPipesCredApiTimeout = 10 * time.Second
+
 type PipesConnectionMetadata struct {
 	CloudHost  *string `json:"cloud_host,omitempty" cty:"cloud_host" hcl:"cloud_host,optional"`
 	User       *string `json:"user,omitempty" cty:"user" hcl:"user,optional"`
@@ -36,14 +42,14 @@ func (m PipesConnectionMetadata) Resolve(ctx context.Context, target PipelingCon
 	if err := m.validate(); err != nil {
 		return nil, err
 	}
-	err := m.callPipesCredApi(target)
+	err := m.callPipesCredApi(ctx, target)
 	if err != nil {
 		return nil, err
 	}
 	return target, nil
 }
 
-func (m PipesConnectionMetadata) callPipesCredApi(target PipelingConnection) error {
+func (m PipesConnectionMetadata) callPipesCredApi(ctx context.Context, target PipelingConnection) error {
 	// get token from env
 	// NOTE: use app specific pipes token env, e.g. FLOWPIPE_PIPES_TOKEN
 	token, ok := os.LookupEnv(app_specific.EnvPipesToken)
@@ -54,13 +60,17 @@ func (m PipesConnectionMetadata) callPipesCredApi(target PipelingConnection) err
 	// API endpoint
 	url := m.endpoint()
 
-	// Create a new HTTP client with a timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	// Create a new HTTP client with the workspace transport settings, defaulting the timeout
+	transportConfig := httpclient.ConfigFromViper().Merge(&httpclient.Config{
+		Timeout: utils.ToPointer(DefaultPipesCredApiTimeout),
+	})
+	client, err := transportConfig.Client(nil)
+	if err != nil {
+		return perr.InternalWithMessage("invalid http settings: " + err.Error())
 	}
 
 	// Create a new HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return perr.InternalWithMessage("failed to create request")
 	}
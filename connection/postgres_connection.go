@@ -99,3 +99,8 @@ func (c *PostgresConnection) GetConnectionString() string {
 	}
 	return ""
 }
+
+// Test opens the database and pings it
+func (c *PostgresConnection) Test(ctx context.Context) error {
+	return testDatabase(ctx, c.GetConnectionString())
+}
@@ -0,0 +1,165 @@
+package connection
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/perr"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultExpiryWindow is how long before the expiry of its credentials a cached connection is resolved again, so
+// callers never receive credentials which expire while they use them
+const DefaultExpiryWindow = time.Minute
+
+// ExpiringConnection is implemented by the resolved connections holding temporary credentials
+type ExpiringConnection interface {
+	PipelingConnection
+	// GetExpiration returns the expiry of the credentials, nil if they do not expire
+	GetExpiration() *time.Time
+}
+
+// ConnectionResolver resolves connections, caching the resolved connections for their ttl or until their credentials
+// expire. It is safe for concurrent use.
+type ConnectionResolver struct {
+	mut     sync.Mutex
+	entries map[string]*resolvedConnection
+	// concurrent resolves of the same connection share a single call to Resolve
+	group singleflight.Group
+	// generation is incremented by Clear, so resolves started before it are not cached
+	generation int
+
+	// ExpiryWindow is how long before the expiry of its credentials a connection is resolved again
+	ExpiryWindow time.Duration
+	// Now returns the current time, it can be overridden for testing
+	Now func() time.Time
+}
+
+type resolvedConnection struct {
+	// hash of the config the connection was resolved from
+	hash       string
+	connection PipelingConnection
+	expires    time.Time
+}
+
+func NewConnectionResolver() *ConnectionResolver {
+	return &ConnectionResolver{
+		entries:      make(map[string]*resolvedConnection),
+		ExpiryWindow: DefaultExpiryWindow,
+		Now:          time.Now,
+	}
+}
+
+// Resolve returns the resolved connection, from the cache if the connection was resolved from the same config and has
+// not expired. Concurrent callers resolving the same connection wait for a single call to Resolve, made with the
+// context of the first caller.
+func (r *ConnectionResolver) Resolve(ctx context.Context, conn PipelingConnection) (PipelingConnection, error) {
+	hash, err := configHash(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	name := conn.Name()
+
+	r.mut.Lock()
+	if entry, ok := r.entries[name]; ok && entry.hash == hash && r.Now().Before(entry.expires) {
+		r.mut.Unlock()
+		return entry.connection, nil
+	}
+	generation := r.generation
+	r.mut.Unlock()
+
+	res, err, _ := r.group.Do(name+"/"+hash, func() (interface{}, error) {
+		resolved, err := conn.Resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mut.Lock()
+		defer r.mut.Unlock()
+
+		// do not cache a connection resolved from a config which has since been reloaded
+		if generation != r.generation {
+			return resolved, nil
+		}
+		if expires, ok := r.expiry(resolved); ok {
+			r.entries[name] = &resolvedConnection{hash: hash, connection: resolved, expires: expires}
+		} else {
+			delete(r.entries, name)
+		}
+		return resolved, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(PipelingConnection), nil
+}
+
+// Invalidate removes the cached connections with the given full names
+func (r *ConnectionResolver) Invalidate(names ...string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for _, name := range names {
+		delete(r.entries, name)
+	}
+}
+
+// Clear removes all cached connections, it is called when the config is reloaded
+func (r *ConnectionResolver) Clear() {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.entries = make(map[string]*resolvedConnection)
+	r.generation++
+}
+
+// expiry returns when the resolved connection must be resolved again, from its ttl and the expiry of its credentials.
+// It returns false if the connection must not be cached.
+func (r *ConnectionResolver) expiry(resolved PipelingConnection) (time.Time, bool) {
+	now := r.Now()
+
+	ttl := resolved.GetTtl()
+	// connections which do not set a ttl are cached for the default ttl
+	if ttl < 0 {
+		ttl = constants.DefaultConnectionTtl
+	}
+	if ttl == 0 {
+		return time.Time{}, false
+	}
+	expires := now.Add(time.Duration(ttl) * time.Second)
+
+	if expiring, ok := resolved.(ExpiringConnection); ok {
+		if expiration := expiring.GetExpiration(); expiration != nil {
+			refreshAt := expiration.Add(-r.ExpiryWindow)
+			if refreshAt.Before(expires) {
+				expires = refreshAt
+			}
+		}
+	}
+
+	if !now.Before(expires) {
+		return time.Time{}, false
+	}
+	return expires, true
+}
+
+// configHash identifies the config of the connection, so a connection is resolved again when its config changes
+func configHash(conn PipelingConnection) (string, error) {
+	data, err := json.Marshal(conn)
+	if err != nil {
+		return "", perr.InternalWithMessage("failed to hash connection config " + err.Error())
+	}
+
+	h := sha256.New()
+	h.Write([]byte(reflect.TypeOf(conn).String()))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
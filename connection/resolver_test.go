@@ -0,0 +1,163 @@
+package connection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+func newTestAwsRoleConnection(endpoint string) *AwsConnection {
+	conn := NewAwsConnection("deploy", hcl.Range{}).(*AwsConnection)
+	conn.AccessKey = utils.ToStringPointer("AKIASOURCE")
+	conn.SecretKey = utils.ToStringPointer("source")
+	conn.RoleArn = utils.ToStringPointer("arn:aws:iam::111111111111:role/deploy")
+	conn.StsEndpoint = &endpoint
+	return conn
+}
+
+func TestConnectionResolverCachesForTtl(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	standIn, endpoint := newStsStandIn(t)
+	standIn.expiration = time.Now().Add(time.Hour)
+
+	resolver := NewConnectionResolver()
+	now := time.Now()
+	resolver.Now = func() time.Time { return now }
+
+	conn := newTestAwsRoleConnection(endpoint)
+	first, err := resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	second, err := resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 1)
+	assert.Same(first, second)
+
+	// the ttl of the connection is 5 minutes
+	now = now.Add(defaultAwsTtl * time.Second)
+	third, err := resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 2)
+	assert.Equal("ASIA2", *third.(*AwsConnection).AccessKey)
+}
+
+func TestConnectionResolverRefreshesBeforeExpiry(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	standIn, endpoint := newStsStandIn(t)
+	// the credentials expire before the ttl of the connection
+	standIn.expiration = time.Now().Add(3 * time.Minute)
+
+	resolver := NewConnectionResolver()
+	now := time.Now()
+	resolver.Now = func() time.Time { return now }
+
+	conn := newTestAwsRoleConnection(endpoint)
+	_, err := resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+
+	now = now.Add(90 * time.Second)
+	_, err = resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 1)
+
+	// within the expiry window of the credentials
+	now = now.Add(30 * time.Second)
+	_, err = resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 2)
+}
+
+func TestConnectionResolverConfigChange(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	standIn, endpoint := newStsStandIn(t)
+	standIn.expiration = time.Now().Add(time.Hour)
+
+	resolver := NewConnectionResolver()
+
+	_, err := resolver.Resolve(ctx, newTestAwsRoleConnection(endpoint))
+	require.NoError(t, err)
+
+	// a connection with the same name but another config is resolved again
+	changed := newTestAwsRoleConnection(endpoint)
+	changed.RoleArn = utils.ToStringPointer("arn:aws:iam::111111111111:role/admin")
+	_, err = resolver.Resolve(ctx, changed)
+	require.NoError(t, err)
+	require.Len(t, standIn.calls, 2)
+	assert.Equal("arn:aws:iam::111111111111:role/admin", standIn.calls[1].roleArn)
+
+	// as is any connection once the config is reloaded
+	resolver.Clear()
+	_, err = resolver.Resolve(ctx, changed)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 3)
+
+	resolver.Invalidate(changed.Name())
+	_, err = resolver.Resolve(ctx, changed)
+	require.NoError(t, err)
+	assert.Len(standIn.calls, 4)
+}
+
+func TestConnectionResolverSingleFlight(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	standIn, endpoint := newStsStandIn(t)
+	standIn.expiration = time.Now().Add(time.Hour)
+	standIn.release = make(chan struct{})
+
+	resolver := NewConnectionResolver()
+	conn := newTestAwsRoleConnection(endpoint)
+
+	const callers = 10
+	results := make([]PipelingConnection, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := resolver.Resolve(ctx, conn)
+			assert.NoError(err)
+			results[i] = res
+		}(i)
+	}
+
+	// wait for the first call to reach the stand-in, then let it answer
+	require.Eventually(t, func() bool {
+		standIn.mut.Lock()
+		defer standIn.mut.Unlock()
+		return len(standIn.calls) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(standIn.release)
+	wg.Wait()
+
+	assert.Len(standIn.calls, 1)
+	for _, res := range results {
+		assert.Same(results[0], res)
+	}
+}
+
+func TestConnectionResolverDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	standIn, endpoint := newStsStandIn(t)
+	standIn.expiration = time.Now().Add(time.Hour)
+
+	resolver := NewConnectionResolver()
+	conn := newTestAwsRoleConnection(endpoint)
+	conn.SessionDuration = utils.ToStringPointer("1m")
+
+	_, err := resolver.Resolve(ctx, conn)
+	assert.Error(t, err)
+
+	conn.SessionDuration = nil
+	_, err = resolver.Resolve(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(t, standIn.calls, 1)
+}
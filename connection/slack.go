@@ -2,21 +2,30 @@ package connection
 
 import (
 	"context"
-	"github.com/turbot/pipe-fittings/cty_helpers"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
 )
 
-const SlackConnectionType = "slack"
+const (
+	SlackConnectionType = "slack"
+
+	defaultSlackBaseUrl = "https://slack.com/api"
+)
 
 type SlackConnection struct {
 	ConnectionImpl
 
 	Token *string `json:"token,omitempty" cty:"token" hcl:"token,optional"`
+	// BaseURL is the base URL of the Slack Web API, it defaults to https://slack.com/api
+	BaseURL *string `json:"base_url,omitempty" cty:"base_url" hcl:"base_url,optional"`
 }
 
 func NewSlackConnection(shortName string, declRange hcl.Range) PipelingConnection {
@@ -41,6 +50,7 @@ func (c *SlackConnection) Resolve(ctx context.Context) (PipelingConnection, erro
 		newConnection := &SlackConnection{
 			ConnectionImpl: c.ConnectionImpl,
 			Token:          &slackTokenEnvVar,
+			BaseURL:        c.BaseURL,
 		}
 
 		return newConnection, nil
@@ -67,6 +77,10 @@ func (c *SlackConnection) Equals(otherConnection PipelingConnection) bool {
 		return false
 	}
 
+	if !utils.PtrEqual(c.BaseURL, other.BaseURL) {
+		return false
+	}
+
 	return c.GetConnectionImpl().Equals(otherConnection.GetConnectionImpl())
 }
 
@@ -102,3 +116,28 @@ func (c *SlackConnection) GetEnv() map[string]cty.Value {
 	}
 	return env
 }
+
+// Test calls auth.test, which answers 200 with ok set to false for an invalid token
+func (c *SlackConnection) Test(ctx context.Context) error {
+	headers, err := bearerAuthHeaders(c.Token)
+	if err != nil {
+		return err
+	}
+
+	body, err := testApi(ctx, http.MethodPost, apiUrl(c.BaseURL, defaultSlackBaseUrl, "/auth.test"), headers)
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("invalid auth.test response: %w", err)
+	}
+	if !res.Ok {
+		return fmt.Errorf("auth.test failed: %s", res.Error)
+	}
+	return nil
+}
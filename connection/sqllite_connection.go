@@ -1,8 +1,31 @@
 package connection
 
-const SqlLiteConnectionType = "sqllite"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	SqlLiteConnectionType = "sqllite"
+
+	sqliteConnectionStringPrefix = "sqlite:"
+)
 
 type SqlLiteConnection struct {
 	ConnectionImpl
 	Database *string `json:"database,omitempty" cty:"database" hcl:"database,optional"`
 }
+
+// Test opens the database read only and pings it, it fails if the database file does not exist
+func (c *SqlLiteConnection) Test(ctx context.Context) error {
+	if c.Database == nil {
+		return fmt.Errorf("no database is set")
+	}
+	filePath, query, err := databaseFile(strings.TrimPrefix(*c.Database, sqliteConnectionStringPrefix))
+	if err != nil {
+		return err
+	}
+	// the sqlite driver only applies the mode of a file URI
+	return testDatabase(ctx, sqliteConnectionStringPrefix+"file:"+filePath+"?"+withQueryParam(query, "mode=ro"))
+}
@@ -0,0 +1,248 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/backend"
+	"github.com/turbot/pipe-fittings/httpclient"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+const (
+	// DefaultConnectionTestTimeout is the timeout of the test of a single connection
+	DefaultConnectionTestTimeout = 30 * time.Second
+	// DefaultConnectionTestConcurrency is the number of connections tested at the same time
+	DefaultConnectionTestConcurrency = 10
+
+	ConnectionTestPassed  = "passed"
+	ConnectionTestFailed  = "failed"
+	ConnectionTestSkipped = "skipped"
+)
+
+// Tester is implemented by the connections which can check that they are usable, by opening the database or making a
+// cheap authenticated API call. Test is called on the resolved connection.
+type Tester interface {
+	Test(ctx context.Context) error
+}
+
+// ConnectionTestResult is the outcome of the test of a connection
+type ConnectionTestResult struct {
+	Connection string `json:"connection"`
+	Type       string `json:"type"`
+	// Status is one of passed, failed or skipped for the connections which cannot be tested
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ConnectionTestReport holds the results of the tests of a set of connections, ordered by connection name
+type ConnectionTestReport struct {
+	Results []ConnectionTestResult `json:"results"`
+	Passed  int                    `json:"passed"`
+	Failed  int                    `json:"failed"`
+	Skipped int                    `json:"skipped"`
+}
+
+func (r *ConnectionTestReport) HasFailures() bool {
+	return r.Failed > 0
+}
+
+// FailedConnections returns the names of the connections which failed their test
+func (r *ConnectionTestReport) FailedConnections() []string {
+	var res []string
+	for _, result := range r.Results {
+		if result.Status == ConnectionTestFailed {
+			res = append(res, result.Connection)
+		}
+	}
+	return res
+}
+
+// TestConnections resolves and tests the connections concurrently. The resolver, if given, caches the resolved
+// connections for the pipelines run after the tests.
+func TestConnections(ctx context.Context, connections map[string]PipelingConnection, resolver *ConnectionResolver) *ConnectionTestReport {
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]ConnectionTestResult, len(names))
+	sem := make(chan struct{}, DefaultConnectionTestConcurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = testConnection(ctx, name, connections[name], resolver)
+		}(i, name)
+	}
+	wg.Wait()
+
+	report := &ConnectionTestReport{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case ConnectionTestPassed:
+			report.Passed++
+		case ConnectionTestFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+func testConnection(ctx context.Context, name string, conn PipelingConnection, resolver *ConnectionResolver) ConnectionTestResult {
+	result := ConnectionTestResult{
+		Connection: name,
+		Type:       conn.GetConnectionType(),
+	}
+
+	if _, ok := conn.(Tester); !ok {
+		result.Status = ConnectionTestSkipped
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultConnectionTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := func() error {
+		var resolved PipelingConnection
+		var err error
+		if resolver != nil {
+			resolved, err = resolver.Resolve(ctx, conn)
+		} else {
+			resolved, err = conn.Resolve(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to resolve connection: %w", err)
+		}
+
+		tester, ok := resolved.(Tester)
+		if !ok {
+			// pipes may resolve a connection to another type
+			return fmt.Errorf("resolved connection of type %s cannot be tested", resolved.GetConnectionType())
+		}
+		return tester.Test(ctx)
+	}()
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Status = ConnectionTestFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = ConnectionTestPassed
+	return result
+}
+
+// testDatabase opens the database of the connection string and pings it
+func testDatabase(ctx context.Context, connectionString string) error {
+	if connectionString == "" {
+		return fmt.Errorf("no database is set")
+	}
+
+	b, err := backend.FromConnectionString(ctx, connectionString)
+	if err != nil {
+		return err
+	}
+	db, err := b.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.PingContext(ctx)
+}
+
+// databaseFile returns the path and query of the file database of a connection string without its backend prefix.
+// The connection tests check the file exists, opening a missing file would create an empty database.
+func databaseFile(database string) (string, string, error) {
+	filePath, query, _ := strings.Cut(database, "?")
+	filePath = strings.TrimPrefix(filePath, "file:")
+	if filePath == "" {
+		return "", "", fmt.Errorf("no database is set")
+	}
+
+	// in memory databases do not create files
+	if filePath == ":memory:" {
+		return filePath, query, nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("database %s does not exist", filePath)
+		}
+		return "", "", err
+	}
+	return filePath, query, nil
+}
+
+// withQueryParam appends the parameter to the query of a connection string
+func withQueryParam(query, param string) string {
+	if query == "" {
+		return param
+	}
+	return query + "&" + param
+}
+
+// testApi makes an authenticated API call, setting the headers on the request. The call fails if the response status
+// code is not 2xx, it returns the response body otherwise.
+func testApi(ctx context.Context, method, url string, headers map[string]string) ([]byte, error) {
+	client, err := httpclient.ConfigFromViper().Merge(&httpclient.Config{
+		Timeout: utils.ToPointer(DefaultConnectionTestTimeout),
+	}).Client(nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http settings: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s returned status %d", method, url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// apiUrl returns the URL of the API path, relative to the base URL of the connection or the default base URL
+func apiUrl(baseUrl *string, defaultBaseUrl, path string) string {
+	if baseUrl != nil && *baseUrl != "" {
+		return strings.TrimSuffix(*baseUrl, "/") + path
+	}
+	return defaultBaseUrl + path
+}
+
+func bearerAuthHeaders(token *string) (map[string]string, error) {
+	if token == nil || *token == "" {
+		return nil, fmt.Errorf("no token is set")
+	}
+	return map[string]string{"Authorization": "Bearer " + *token}, nil
+}
@@ -0,0 +1,185 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+// testSqliteDriver stands in for the sqlite driver registered by the applications, it records the names of the
+// databases it opens
+type testSqliteDriver struct{}
+
+type testSqliteConn struct{}
+
+var testSqliteOpened []string
+
+func (testSqliteDriver) Open(name string) (driver.Conn, error) {
+	testSqliteOpened = append(testSqliteOpened, name)
+	return testSqliteConn{}, nil
+}
+
+func (testSqliteConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (testSqliteConn) Close() error                        { return nil }
+func (testSqliteConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+func init() {
+	sql.Register("sqlite3", testSqliteDriver{})
+}
+
+// newApiStandIn answers the API calls of the connection tests, accepting the requests with the expected header
+func newApiStandIn(t *testing.T, path, header, value string, body any) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestApiConnectionTests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// slack answers 200 for an invalid token, with ok set to false
+	slackUrl := newApiStandIn(t, "/auth.test", "Authorization", "Bearer xoxb-valid", map[string]any{"ok": true})
+	githubUrl := newApiStandIn(t, "/user", "Authorization", "Bearer ghp_valid", map[string]any{"login": "test"})
+	gitlabUrl := newApiStandIn(t, "/user", "Private-Token", "glpat-valid", map[string]any{"username": "test"})
+	openaiUrl := newApiStandIn(t, "/models", "Authorization", "Bearer sk-valid", map[string]any{"data": []any{}})
+	githubEnterpriseUrl := newApiStandIn(t, "/api/v3/user", "Authorization", "Bearer ghp_valid", map[string]any{"login": "test"})
+	// admin:secret
+	jiraUrl := newApiStandIn(t, "/rest/api/2/myself", "Authorization", "Basic YWRtaW46c2VjcmV0", map[string]any{"name": "admin"})
+
+	tests := []struct {
+		title   string
+		valid   Tester
+		invalid Tester
+	}{
+		{"slack", &SlackConnection{Token: utils.ToStringPointer("xoxb-valid"), BaseURL: &slackUrl}, &SlackConnection{Token: utils.ToStringPointer("xoxb-invalid"), BaseURL: &slackUrl}},
+		{"github", &GithubConnection{Token: utils.ToStringPointer("ghp_valid"), BaseURL: &githubUrl}, &GithubConnection{Token: utils.ToStringPointer("ghp_invalid"), BaseURL: &githubUrl}},
+		{"gitlab", &GitLabConnection{Token: utils.ToStringPointer("glpat-valid"), BaseURL: &gitlabUrl}, &GitLabConnection{Token: utils.ToStringPointer(""), BaseURL: &gitlabUrl}},
+		{"openai", &OpenAIConnection{APIKey: utils.ToStringPointer("sk-valid"), BaseURL: &openaiUrl}, &OpenAIConnection{APIKey: utils.ToStringPointer("sk-invalid"), BaseURL: &openaiUrl}},
+		// the base URL of a self-hosted instance may have a path and a trailing slash
+		{
+			"github enterprise",
+			&GithubConnection{Token: utils.ToStringPointer("ghp_valid"), BaseURL: utils.ToStringPointer(githubEnterpriseUrl + "/api/v3/")},
+			&GithubConnection{Token: utils.ToStringPointer("ghp_valid"), BaseURL: utils.ToStringPointer(githubEnterpriseUrl)},
+		},
+		{
+			"jira",
+			&JiraConnection{BaseURL: &jiraUrl, Username: utils.ToStringPointer("admin"), APIToken: utils.ToStringPointer("secret")},
+			&JiraConnection{BaseURL: &jiraUrl, Username: utils.ToStringPointer("admin"), APIToken: utils.ToStringPointer("wrong")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.NoError(t, tt.valid.Test(ctx))
+			assert.Error(t, tt.invalid.Test(ctx))
+		})
+	}
+}
+
+func TestDatabaseFileConnectionTests(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "flowpipe.db")
+	require.NoError(t, os.WriteFile(existing, nil, 0600))
+	missing := filepath.Join(dir, "missing.db")
+
+	// Case 1: the sqlite database is opened read only
+	testSqliteOpened = nil
+	assert.NoError((&SqlLiteConnection{Database: &existing}).Test(ctx))
+	assert.Equal([]string{"file:" + existing + "?mode=ro"}, testSqliteOpened)
+
+	// Case 2: the query of the database is kept
+	testSqliteOpened = nil
+	assert.NoError((&SqlLiteConnection{Database: utils.ToStringPointer("sqlite:" + existing + "?cache=shared")}).Test(ctx))
+	assert.Equal([]string{"file:" + existing + "?cache=shared&mode=ro"}, testSqliteOpened)
+
+	// Case 3: a missing database fails and is not created
+	testSqliteOpened = nil
+	err := (&SqlLiteConnection{Database: &missing}).Test(ctx)
+	assert.ErrorContains(err, "does not exist")
+	assert.Empty(testSqliteOpened)
+	err = (&DuckDbConnection{ConnectionString: utils.ToStringPointer("duckdb:" + missing)}).Test(ctx)
+	assert.ErrorContains(err, "does not exist")
+	_, statErr := os.Stat(missing)
+	assert.True(os.IsNotExist(statErr))
+
+	// Case 4: no database
+	assert.Error((&SqlLiteConnection{}).Test(ctx))
+	assert.Error((&DuckDbConnection{}).Test(ctx))
+}
+
+func TestAwsConnectionTest(t *testing.T) {
+	standIn, endpoint := newStsStandIn(t)
+
+	conn := &AwsConnection{
+		AccessKey:   utils.ToStringPointer("AKIATEST"),
+		SecretKey:   utils.ToStringPointer("secret"),
+		StsEndpoint: &endpoint,
+	}
+	require.NoError(t, conn.Test(context.Background()))
+	require.Len(t, standIn.calls, 1)
+	assert.Equal(t, "GetCallerIdentity", standIn.calls[0].action)
+	assert.Equal(t, "AKIATEST", standIn.calls[0].signedWith)
+}
+
+func TestTestConnections(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	githubUrl := newApiStandIn(t, "/user", "Authorization", "Bearer ghp_valid", map[string]any{"login": "test"})
+
+	valid := NewGithubConnection("valid", hcl.Range{}).(*GithubConnection)
+	valid.Token = utils.ToStringPointer("ghp_valid")
+	valid.BaseURL = &githubUrl
+	invalid := NewGithubConnection("invalid", hcl.Range{}).(*GithubConnection)
+	invalid.Token = utils.ToStringPointer("ghp_invalid")
+	invalid.BaseURL = &githubUrl
+	untestable := NewDiscordConnection("default", hcl.Range{})
+
+	resolver := NewConnectionResolver()
+	report := TestConnections(context.Background(), map[string]PipelingConnection{
+		valid.Name():      valid,
+		invalid.Name():    invalid,
+		untestable.Name(): untestable,
+	}, resolver)
+
+	require.Len(t, report.Results, 3)
+	assert.Equal([]string{"discord.default", "github.invalid", "github.valid"}, []string{report.Results[0].Connection, report.Results[1].Connection, report.Results[2].Connection})
+	assert.Equal(ConnectionTestSkipped, report.Results[0].Status)
+	assert.Equal(ConnectionTestFailed, report.Results[1].Status)
+	assert.Contains(report.Results[1].Error, "returned status 401")
+	assert.Equal(ConnectionTestPassed, report.Results[2].Status)
+	assert.Equal("github", report.Results[2].Type)
+
+	assert.Equal(1, report.Passed)
+	assert.Equal(1, report.Failed)
+	assert.Equal(1, report.Skipped)
+	assert.True(report.HasFailures())
+	assert.Equal([]string{"github.invalid"}, report.FailedConnections())
+}
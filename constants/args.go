@@ -43,6 +43,12 @@ const (
 	ArgHeader                  = "header"
 	ArgHelp                    = "help"
 	ArgHost                    = "host"
+	ArgHttp2                   = "http2"
+	ArgHttpConnectTimeout      = "http-connect-timeout"
+	ArgHttpMaxRedirects        = "http-max-redirects"
+	ArgHttpNoProxy             = "http-no-proxy"
+	ArgHttpProxyUrl            = "http-proxy-url"
+	ArgHttpTimeout             = "http-timeout"
 	ArgInput                   = "input"
 	ArgInsecure                = "insecure"
 	ArgInstallDir              = "install-dir"
@@ -5,11 +5,25 @@ const (
 	InputTypeMultiSelect = "multiselect"
 	InputTypeSelect      = "select"
 	InputTypeText        = "text"
+	InputTypeNumber      = "number"
+	InputTypeDate        = "date"
+	InputTypeDateTime    = "datetime"
+	InputTypeConfirm     = "confirm"
 )
 
 func IsValidInputType(s string) bool {
 	switch s {
-	case InputTypeButton, InputTypeMultiSelect, InputTypeSelect, InputTypeText:
+	case InputTypeButton, InputTypeMultiSelect, InputTypeSelect, InputTypeText, InputTypeNumber, InputTypeDate, InputTypeDateTime, InputTypeConfirm:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOptionInputType returns true if the response of the input type is one of its options
+func IsOptionInputType(s string) bool {
+	switch s {
+	case InputTypeButton, InputTypeMultiSelect, InputTypeSelect:
 		return true
 	default:
 		return false
@@ -0,0 +1,105 @@
+package credential
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/app_specific_connection"
+	"github.com/turbot/pipe-fittings/connection"
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+// the credential types whose connection type has a different name, these are also the names of the Steampipe plugins
+var credentialConnectionTypes = map[string]string{
+	"guardrails":  connection.GuardrailsConnectionType,
+	"pipes":       connection.PipesConnectionType,
+	"teams":       connection.MicrosoftTeamsConnectionType,
+	"uptimerobot": connection.UptimeRobotConnectionType,
+	"virustotal":  connection.VirusTotalConnectionType,
+}
+
+// ConnectionTypeForCredentialType returns the connection type equivalent to the credential type
+func ConnectionTypeForCredentialType(credentialType string) string {
+	if connectionType, ok := credentialConnectionTypes[credentialType]; ok {
+		return connectionType
+	}
+	return credentialType
+}
+
+// CredentialToConnection converts a credential to the equivalent pipeling connection. The attributes of the credential
+// are copied to the connection attributes with the same name.
+func CredentialToConnection(cred Credential) (connection.PipelingConnection, error) {
+	connectionType := ConnectionTypeForCredentialType(cred.GetCredentialType())
+
+	declRange := hcl.Range{}
+	if r := cred.GetDeclRange(); r != nil {
+		declRange = *r
+	}
+
+	conn, err := app_specific_connection.NewPipelingConnection(connectionType, cred.GetShortName(), declRange)
+	if err != nil {
+		return nil, err
+	}
+
+	connFields := hclFields(reflect.ValueOf(conn).Elem())
+
+	credValue := reflect.ValueOf(cred).Elem()
+	credType := credValue.Type()
+	for i := 0; i < credType.NumField(); i++ {
+		field := credType.Field(i)
+		name := hclName(field)
+		// skip the embedded CredentialImpl and unset attributes
+		if field.Anonymous || name == "" || credValue.Field(i).IsZero() {
+			continue
+		}
+
+		value := credValue.Field(i)
+		if name == "ttl" {
+			// the ttl of a connection is held by its ConnectionImpl
+			conn.SetTtl(*value.Interface().(*int))
+			continue
+		}
+
+		target, ok := connFields[name]
+		if !ok || target.Type() != value.Type() {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("credential %s: attribute %s is not supported by the %s connection", cred.Name(), name, connectionType))
+		}
+		target.Set(value)
+	}
+
+	return conn, nil
+}
+
+// CredentialsToConnections converts the credentials, keyed by name, to pipeling connections keyed by connection name
+func CredentialsToConnections(credentials map[string]Credential) (map[string]connection.PipelingConnection, error) {
+	connections := make(map[string]connection.PipelingConnection, len(credentials))
+	for _, cred := range credentials {
+		conn, err := CredentialToConnection(cred)
+		if err != nil {
+			return nil, err
+		}
+		connections[conn.Name()] = conn
+	}
+	return connections, nil
+}
+
+// hclFields returns the settable fields of the struct with an hcl tag, keyed by attribute name
+func hclFields(v reflect.Value) map[string]reflect.Value {
+	res := make(map[string]reflect.Value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := hclName(field); name != "" && !field.Anonymous {
+			res[name] = v.Field(i)
+		}
+	}
+	return res
+}
+
+func hclName(field reflect.StructField) string {
+	tag := field.Tag.Get("hcl")
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
@@ -0,0 +1,86 @@
+package credential
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/connection"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/tests/test_init"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+func newTestCredential(credentialType, shortName string) Credential {
+	cred := reflect.New(credentialTypeRegistry[credentialType]).Interface().(Credential)
+	cred.SetHclResourceImpl(modconfig.HclResourceImpl{
+		FullName:        credentialType + "." + shortName,
+		ShortName:       shortName,
+		UnqualifiedName: credentialType + "." + shortName,
+		DeclRange:       hcl.Range{Filename: "creds.fpc", Start: hcl.Pos{Line: 3}},
+	})
+	cred.SetCredentialType(credentialType)
+	return cred
+}
+
+func TestCredentialToConnectionAllTypes(t *testing.T) {
+	assert := assert.New(t)
+	test_init.SetAppSpecificConstants()
+
+	for credentialType := range credentialTypeRegistry {
+		cred := newTestCredential(credentialType, "example")
+
+		// set every string attribute, so a missing connection attribute fails the conversion
+		v := reflect.ValueOf(cred).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).Type() == reflect.TypeOf((*string)(nil)) {
+				v.Field(i).Set(reflect.ValueOf(utils.ToPointer("value")))
+			}
+		}
+
+		conn, err := CredentialToConnection(cred)
+		if !assert.Nil(err, credentialType) {
+			continue
+		}
+		assert.Equal(ConnectionTypeForCredentialType(credentialType)+".example", conn.Name())
+		assert.Equal("creds.fpc", conn.GetConnectionImpl().DeclRange.Filename)
+	}
+}
+
+func TestCredentialToConnection(t *testing.T) {
+	assert := assert.New(t)
+	test_init.SetAppSpecificConstants()
+
+	// Case 1: attributes are copied
+	awsCred := newTestCredential("aws", "prod").(*AwsCredential)
+	awsCred.AccessKey = utils.ToPointer("abc")
+	awsCred.SecretKey = utils.ToPointer("123")
+	awsCred.Ttl = utils.ToPointer(300)
+
+	conn, err := CredentialToConnection(awsCred)
+	assert.Nil(err)
+	awsConn := conn.(*connection.AwsConnection)
+	assert.Equal("aws.prod", awsConn.Name())
+	assert.Equal("abc", *awsConn.AccessKey)
+	assert.Equal("123", *awsConn.SecretKey)
+	assert.Nil(awsConn.Profile)
+	assert.Equal(300, awsConn.GetTtl())
+
+	// Case 2: the ttl of the connection defaults like a decoded connection
+	githubCred := newTestCredential("github", "default").(*GithubCredential)
+	githubCred.Token = utils.ToPointer("ghp_token")
+
+	conn, err = CredentialToConnection(githubCred)
+	assert.Nil(err)
+	assert.Equal("ghp_token", *conn.(*connection.GithubConnection).Token)
+	assert.Equal(-1, conn.GetTtl())
+
+	// Case 3: renamed type
+	teamsCred := newTestCredential("teams", "ops").(*MicrosoftTeamsCredential)
+	teamsCred.AccessToken = utils.ToPointer("teams_token")
+
+	connections, err := CredentialsToConnections(map[string]Credential{teamsCred.Name(): teamsCred})
+	assert.Nil(err)
+	assert.Equal("teams_token", *connections["microsoft_teams.ops"].(*connection.MicrosoftTeamsConnection).AccessToken)
+}
@@ -0,0 +1,133 @@
+package credential_migration
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/pipe-fittings/app_specific"
+	"github.com/turbot/pipe-fittings/error_helpers"
+)
+
+// FileMigration holds the content of a file before and after its credentials are migrated to connections
+type FileMigration struct {
+	Path     string
+	Original []byte
+	Migrated []byte
+}
+
+func (m *FileMigration) Changed() bool {
+	return !bytes.Equal(m.Original, m.Migrated)
+}
+
+// Diff returns the unified diff of the migration of the file
+func (m *FileMigration) Diff() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(m.Original)),
+		B:        difflib.SplitLines(string(m.Migrated)),
+		FromFile: m.Path,
+		ToFile:   m.Path,
+		Context:  3,
+	})
+}
+
+// MigrateOptions configures MigrateCredentials
+type MigrateOptions struct {
+	// DryRun returns the migrations without writing the files
+	DryRun bool
+}
+
+// MigrateCredentials rewrites the credential blocks and references of the config and mod files under the paths into
+// connection syntax. The paths are files or folders, which are searched recursively, ignoring hidden folders. It
+// returns the migrations of the files which changed.
+func MigrateCredentials(paths []string, opts MigrateOptions) ([]*FileMigration, error) {
+	filePaths, err := listMigrationFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []*FileMigration
+	for _, filePath := range filePaths {
+		src, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		migrated, diags := RewriteCredentials(filePath, src)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError("Failed to migrate credentials", diags)
+		}
+
+		migration := &FileMigration{Path: filePath, Original: src, Migrated: migrated}
+		if !migration.Changed() {
+			continue
+		}
+		migrations = append(migrations, migration)
+	}
+
+	if opts.DryRun {
+		return migrations, nil
+	}
+
+	// only write the files once they have all been rewritten, so a file which fails to parse leaves them untouched
+	for _, migration := range migrations {
+		info, err := os.Stat(migration.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(migration.Path, migration.Migrated, info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", migration.Path, err)
+		}
+	}
+	return migrations, nil
+}
+
+// listMigrationFiles returns the config and mod files of the paths
+func listMigrationFiles(paths []string) ([]string, error) {
+	extensions := append([]string{app_specific.ConfigExtension}, app_specific.ModDataExtensions...)
+
+	var res []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			if isMigrationFile(path, extensions) {
+				res = append(res, path)
+			}
+			continue
+		}
+
+		filePaths, err := filehelpers.ListFiles(path, &filehelpers.ListOptions{
+			Flags:   filehelpers.FilesRecursive,
+			Include: filehelpers.InclusionsFromExtensions(extensions),
+			Exclude: []string{
+				// ignore any hidden folder
+				fmt.Sprintf("%s/.*", path),
+				// and sub files/folders of hidden folders
+				fmt.Sprintf("%s/.*/**", path),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, filePaths...)
+	}
+	return res, nil
+}
+
+func isMigrationFile(path string, extensions []string) bool {
+	ext := filepath.Ext(path)
+	for _, e := range extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
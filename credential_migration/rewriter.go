@@ -0,0 +1,111 @@
+package credential_migration
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/pipe-fittings/credential"
+	"github.com/turbot/pipe-fittings/modinstaller"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// RewriteCredentials rewrites the credential blocks of the HCL source into connection blocks, and the references to
+// credentials, e.g. credential.aws.example.access_key, into references to the connections. The credential attributes
+// have the same names as the connection attributes, so the block bodies are left untouched.
+func RewriteCredentials(fileName string, src []byte) ([]byte, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, fileName, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("failed to parse %s: unexpected body type", fileName),
+			},
+		}
+	}
+
+	changes := modinstaller.MergeChangeSet(
+		buildChangeSetForCredentialBlocks(body),
+		buildChangeSetForCredentialReferences(body),
+	)
+	if len(changes) == 0 {
+		return src, nil
+	}
+
+	contents := modinstaller.NewByteSequence(src)
+	// the blocks and references are renamed in place, so the file is not reformatted
+	contents.ApplyChanges(changes)
+	return contents.Bytes(), nil
+}
+
+// buildChangeSetForCredentialBlocks renames the top level credential blocks, and their type label if the connection
+// type has a different name
+func buildChangeSetForCredentialBlocks(body *hclsyntax.Body) modinstaller.ChangeSet {
+	changes := modinstaller.EmptyChangeSet()
+	for _, block := range body.Blocks {
+		if block.Type != schema.BlockTypeCredential || len(block.Labels) != 2 {
+			continue
+		}
+
+		changes = append(changes, &modinstaller.Change{
+			Operation:   modinstaller.Replace,
+			OffsetStart: block.TypeRange.Start.Byte,
+			OffsetEnd:   block.TypeRange.End.Byte,
+			Content:     []byte(schema.BlockTypeConnection),
+		})
+
+		credentialType := block.Labels[0]
+		if connectionType := credential.ConnectionTypeForCredentialType(credentialType); connectionType != credentialType {
+			changes = append(changes, &modinstaller.Change{
+				Operation:   modinstaller.Replace,
+				OffsetStart: block.LabelRanges[0].Start.Byte,
+				OffsetEnd:   block.LabelRanges[0].End.Byte,
+				Content:     []byte(fmt.Sprintf("%q", connectionType)),
+			})
+		}
+	}
+	return changes
+}
+
+// buildChangeSetForCredentialReferences renames the root of the traversals of credentials, and their type if the
+// connection type has a different name
+func buildChangeSetForCredentialReferences(body *hclsyntax.Body) modinstaller.ChangeSet {
+	changes := modinstaller.EmptyChangeSet()
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || expr.Traversal.RootName() != schema.BlockTypeCredential {
+			return nil
+		}
+
+		root := expr.Traversal[0].SourceRange()
+		changes = append(changes, &modinstaller.Change{
+			Operation:   modinstaller.Replace,
+			OffsetStart: root.Start.Byte,
+			OffsetEnd:   root.End.Byte,
+			Content:     []byte(schema.BlockTypeConnection),
+		})
+
+		if len(expr.Traversal) < 2 {
+			return nil
+		}
+		typeStep, ok := expr.Traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return nil
+		}
+		if connectionType := credential.ConnectionTypeForCredentialType(typeStep.Name); connectionType != typeStep.Name {
+			// the range of the attribute step includes the preceding dot
+			changes = append(changes, &modinstaller.Change{
+				Operation:   modinstaller.Replace,
+				OffsetStart: typeStep.SrcRange.Start.Byte,
+				OffsetEnd:   typeStep.SrcRange.End.Byte,
+				Content:     []byte("." + connectionType),
+			})
+		}
+		return nil
+	})
+	return changes
+}
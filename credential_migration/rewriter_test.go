@@ -0,0 +1,159 @@
+package credential_migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/tests/test_init"
+)
+
+const testConfig = `credential "aws" "prod" {
+  access_key = "abc"
+  secret_key = "123"
+}
+
+credential "teams" "ops" {
+  access_token = "xyz"
+}
+
+credential_import "steampipe" {
+  source = "~/.steampipe/config/*.spc"
+}
+`
+
+const testConfigMigrated = `connection "aws" "prod" {
+  access_key = "abc"
+  secret_key = "123"
+}
+
+connection "microsoft_teams" "ops" {
+  access_token = "xyz"
+}
+
+credential_import "steampipe" {
+  source = "~/.steampipe/config/*.spc"
+}
+`
+
+const testPipeline = `pipeline "list" {
+  param "cred" {
+    type    = string
+    default = "default"
+  }
+
+  step "http" "list" {
+    url = "https://example.com/${credential.teams.ops.access_token}"
+  }
+
+  output "key" {
+    value = credential.aws[param.cred].access_key
+  }
+
+  output "env" {
+    value = credential.aws.prod.env
+  }
+}
+`
+
+const testPipelineMigrated = `pipeline "list" {
+  param "cred" {
+    type    = string
+    default = "default"
+  }
+
+  step "http" "list" {
+    url = "https://example.com/${connection.microsoft_teams.ops.access_token}"
+  }
+
+  output "key" {
+    value = connection.aws[param.cred].access_key
+  }
+
+  output "env" {
+    value = connection.aws.prod.env
+  }
+}
+`
+
+func TestRewriteCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	// Case 1: credential blocks
+	res, diags := RewriteCredentials("creds.fpc", []byte(testConfig))
+	assert.False(diags.HasErrors())
+	assert.Equal(testConfigMigrated, string(res))
+
+	// Case 2: credential references
+	res, diags = RewriteCredentials("pipeline.fp", []byte(testPipeline))
+	assert.False(diags.HasErrors())
+	assert.Equal(testPipelineMigrated, string(res))
+
+	// Case 3: migrated files are unchanged
+	res, diags = RewriteCredentials("pipeline.fp", []byte(testPipelineMigrated))
+	assert.False(diags.HasErrors())
+	assert.Equal(testPipelineMigrated, string(res))
+
+	// Case 4: invalid HCL
+	_, diags = RewriteCredentials("pipeline.fp", []byte(`pipeline "list" {`))
+	assert.True(diags.HasErrors())
+}
+
+func TestMigrateCredentials(t *testing.T) {
+	assert := assert.New(t)
+	test_init.SetAppSpecificConstants()
+
+	dir := t.TempDir()
+	assert.Nil(os.MkdirAll(filepath.Join(dir, "pipelines"), 0755))
+	assert.Nil(os.MkdirAll(filepath.Join(dir, ".flowpipe"), 0755))
+	files := map[string]string{
+		"creds.fpc":               testConfig,
+		"pipelines/pipeline.fp":   testPipeline,
+		"pipelines/README.md":     "credential.aws.prod",
+		".flowpipe/dependency.fp": testPipeline,
+	}
+	for name, content := range files {
+		assert.Nil(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	// Case 1: dry run
+	migrations, err := MigrateCredentials([]string{dir}, MigrateOptions{DryRun: true})
+	assert.Nil(err)
+	assert.Equal(2, len(migrations))
+
+	diffs := map[string]string{}
+	for _, migration := range migrations {
+		diff, err := migration.Diff()
+		assert.Nil(err)
+		rel, _ := filepath.Rel(dir, migration.Path)
+		diffs[rel] = diff
+	}
+	assert.Contains(diffs["creds.fpc"], `-credential "teams" "ops" {`)
+	assert.Contains(diffs["creds.fpc"], `+connection "microsoft_teams" "ops" {`)
+	assert.Contains(diffs["pipelines/pipeline.fp"], `+    value = connection.aws.prod.env`)
+
+	data, err := os.ReadFile(filepath.Join(dir, "creds.fpc"))
+	assert.Nil(err)
+	assert.Equal(testConfig, string(data))
+
+	// Case 2: the files are written
+	migrations, err = MigrateCredentials([]string{dir}, MigrateOptions{})
+	assert.Nil(err)
+	assert.Equal(2, len(migrations))
+
+	data, err = os.ReadFile(filepath.Join(dir, "creds.fpc"))
+	assert.Nil(err)
+	assert.Equal(testConfigMigrated, string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "pipelines/pipeline.fp"))
+	assert.Nil(err)
+	assert.Equal(testPipelineMigrated, string(data))
+	data, err = os.ReadFile(filepath.Join(dir, ".flowpipe/dependency.fp"))
+	assert.Nil(err)
+	assert.Equal(testPipeline, string(data))
+
+	// Case 3: nothing left to migrate
+	migrations, err = MigrateCredentials([]string{dir}, MigrateOptions{})
+	assert.Nil(err)
+	assert.Equal(0, len(migrations))
+}
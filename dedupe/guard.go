@@ -0,0 +1,199 @@
+package dedupe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ReasonDuplicate   = "duplicate"
+	ReasonRateLimited = "rate_limited"
+
+	dedupeKeyPrefix    = "dedupe/"
+	rateLimitKeyPrefix = "rate_limit/"
+)
+
+// MaxDigestMessages is the number of suppressed notifications whose text is kept for the digest of a rate limit
+// interval, the notifications suppressed beyond it are only counted
+var MaxDigestMessages = 100
+
+// Config is the dedupe and rate limit settings of a notifier, zero values disable the setting
+type Config struct {
+	// DedupeWindow suppresses the notifications with the same dedupe key as a notification sent within the window.
+	// The window starts with the first notification and is not extended by the duplicates.
+	DedupeWindow time.Duration
+	// RateLimit is the number of notifications sent per RateInterval
+	RateLimit    int
+	RateInterval time.Duration
+	// Digest keeps the notifications suppressed by the rate limit, to be sent as a digest once the interval is over
+	Digest bool
+}
+
+func (c Config) IsEmpty() bool {
+	return c.DedupeWindow <= 0 && (c.RateLimit <= 0 || c.RateInterval <= 0)
+}
+
+// Decision is the result of checking a notification
+type Decision struct {
+	Send bool
+	// Reason the notification is suppressed, ReasonDuplicate or ReasonRateLimited
+	Reason string
+	// Digest holds the notifications suppressed during the previous rate limit interval, to be sent along with this
+	// notification
+	Digest *Digest
+}
+
+// Digest is the notifications suppressed by the rate limit of a notifier during an interval
+type Digest struct {
+	// Messages holds the text of the first MaxDigestMessages suppressed notifications
+	Messages []string
+	// Omitted is the number of suppressed notifications whose text was not kept
+	Omitted int
+}
+
+// digestOf returns the digest of the rate limit entry, nil if it suppressed no notification
+func digestOf(entry *Entry) *Digest {
+	if entry == nil || len(entry.Suppressed) == 0 && entry.Omitted == 0 {
+		return nil
+	}
+	return &Digest{Messages: entry.Suppressed, Omitted: entry.Omitted}
+}
+
+// Guard decides whether a notification is sent, from the dedupe and rate limit state in its store
+type Guard struct {
+	store Store
+	// the store operations of a check are not atomic, so checks are serialised
+	mut sync.Mutex
+
+	// Now returns the current time, it can be overridden for testing
+	Now func() time.Time
+}
+
+func NewGuard(store Store) *Guard {
+	return &Guard{
+		store: store,
+		Now:   time.Now,
+	}
+}
+
+// Check records the notification sent by the notifier and returns whether it must be sent. The dedupe key
+// identifies identical notifications, an empty key disables the dedupe window for this notification. The dedupe
+// window only starts when the notification is sent, a notification suppressed by the rate limit is not a duplicate of
+// its retries.
+func (g *Guard) Check(ctx context.Context, notifier string, config Config, dedupeKey, text string) (*Decision, error) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	now := g.Now()
+
+	storeKey := ""
+	if config.DedupeWindow > 0 && dedupeKey != "" {
+		storeKey = dedupeStoreKey(notifier, dedupeKey)
+		entry, err := g.store.Get(ctx, storeKey, now)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entry.Count++
+			if err := g.store.Put(ctx, storeKey, entry, now); err != nil {
+				return nil, err
+			}
+			return &Decision{Reason: ReasonDuplicate}, nil
+		}
+	}
+
+	decision, err := g.checkRateLimit(ctx, notifier, config, text, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.Send && storeKey != "" {
+		entry := &Entry{Start: now, Expires: now.Add(config.DedupeWindow), Count: 1}
+		if err := g.store.Put(ctx, storeKey, entry, now); err != nil {
+			return nil, err
+		}
+	}
+	return decision, nil
+}
+
+// checkRateLimit records the notification in the rate limit interval of the notifier and returns whether it is sent
+func (g *Guard) checkRateLimit(ctx context.Context, notifier string, config Config, text string, now time.Time) (*Decision, error) {
+	decision := &Decision{Send: true}
+	if config.RateLimit <= 0 || config.RateInterval <= 0 {
+		return decision, nil
+	}
+
+	key := rateLimitKeyPrefix + notifier
+	entry, err := g.store.Get(ctx, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case entry == nil || !now.Before(entry.Start.Add(config.RateInterval)):
+		// a new interval, the notifications suppressed in the previous one are sent as a digest
+		decision.Digest = digestOf(entry)
+		entry = &Entry{Start: now, Count: 1}
+	case entry.Count < config.RateLimit:
+		entry.Count++
+	default:
+		entry.Count++
+		if config.Digest {
+			if len(entry.Suppressed) < MaxDigestMessages {
+				entry.Suppressed = append(entry.Suppressed, text)
+			} else {
+				entry.Omitted++
+			}
+		}
+		decision = &Decision{Reason: ReasonRateLimited}
+	}
+
+	if err := g.store.Put(ctx, key, entry, now); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// FlushDigest returns the notifications suppressed by the rate limit of the notifier once the interval is over, and
+// clears them. It is called by the executor when no new notification closes the interval.
+func (g *Guard) FlushDigest(ctx context.Context, notifier string, config Config) (*Digest, error) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	key := rateLimitKeyPrefix + notifier
+	entry, err := g.store.Get(ctx, key, g.Now())
+	if err != nil {
+		return nil, err
+	}
+	digest := digestOf(entry)
+	if digest == nil || g.Now().Before(entry.Start.Add(config.RateInterval)) {
+		return nil, nil
+	}
+
+	if err := g.store.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// RateLimitedNotifiers returns the notifiers with a rate limit state, which may have a digest to flush
+func (g *Guard) RateLimitedNotifiers(ctx context.Context) ([]string, error) {
+	keys, err := g.store.Keys(ctx, rateLimitKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, rateLimitKeyPrefix)
+	}
+	return keys, nil
+}
+
+// the dedupe keys are hashed, they may be long and hold the text of the notification
+func dedupeStoreKey(notifier, dedupeKey string) string {
+	sum := sha256.Sum256([]byte(dedupeKey))
+	return dedupeKeyPrefix + notifier + "/" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,245 @@
+package dedupe
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestGuard(t *testing.T, store Store) (*Guard, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)}
+	guard := NewGuard(store)
+	guard.Now = clock.Now
+	return guard, clock
+}
+
+func testStores(t *testing.T) map[string]func() Store {
+	dir := t.TempDir()
+	n := 0
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemoryStore() },
+		"file": func() Store {
+			n++
+			store, err := NewFileStore(filepath.Join(dir, "dedupe", "store"+string(rune('a'+n))+".json"))
+			require.NoError(t, err)
+			return store
+		},
+	}
+}
+
+func TestGuardDedupe(t *testing.T) {
+	for name, newStore := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+			guard, clock := newTestGuard(t, newStore())
+			config := Config{DedupeWindow: 10 * time.Minute}
+
+			decision, err := guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+
+			clock.Advance(5 * time.Minute)
+			decision, err = guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+			require.NoError(t, err)
+			assert.False(decision.Send)
+			assert.Equal(ReasonDuplicate, decision.Reason)
+
+			// other keys and other notifiers are not duplicates
+			decision, err = guard.Check(ctx, "oncall", config, "cpu high", "CPU high")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+			decision, err = guard.Check(ctx, "admins", config, "disk full", "Disk full")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+
+			// the duplicates do not extend the window
+			clock.Advance(5 * time.Minute)
+			decision, err = guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+
+			// an empty key is never a duplicate
+			decision, err = guard.Check(ctx, "oncall", config, "", "Hello")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+			decision, err = guard.Check(ctx, "oncall", config, "", "Hello")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+		})
+	}
+}
+
+func TestGuardRateLimit(t *testing.T) {
+	for name, newStore := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+			guard, clock := newTestGuard(t, newStore())
+			config := Config{RateLimit: 2, RateInterval: time.Minute, Digest: true}
+
+			var sent []string
+			for _, text := range []string{"one", "two", "three", "four"} {
+				decision, err := guard.Check(ctx, "oncall", config, "", text)
+				require.NoError(t, err)
+				if decision.Send {
+					sent = append(sent, text)
+				} else {
+					assert.Equal(ReasonRateLimited, decision.Reason)
+				}
+				clock.Advance(time.Second)
+			}
+			assert.Equal([]string{"one", "two"}, sent)
+
+			// the interval is not over
+			digest, err := guard.FlushDigest(ctx, "oncall", config)
+			require.NoError(t, err)
+			assert.Nil(digest)
+
+			notifiers, err := guard.RateLimitedNotifiers(ctx)
+			require.NoError(t, err)
+			assert.Equal([]string{"oncall"}, notifiers)
+
+			// the first notification of the next interval carries the digest of the previous one
+			clock.Advance(time.Minute)
+			decision, err := guard.Check(ctx, "oncall", config, "", "five")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+			assert.Equal(&Digest{Messages: []string{"three", "four"}}, decision.Digest)
+
+			// or the executor flushes it once the interval is over
+			for _, text := range []string{"six", "seven"} {
+				_, err := guard.Check(ctx, "oncall", config, "", text)
+				require.NoError(t, err)
+			}
+			clock.Advance(time.Minute)
+			digest, err = guard.FlushDigest(ctx, "oncall", config)
+			require.NoError(t, err)
+			assert.Equal(&Digest{Messages: []string{"seven"}}, digest)
+
+			digest, err = guard.FlushDigest(ctx, "oncall", config)
+			require.NoError(t, err)
+			assert.Nil(digest)
+		})
+	}
+}
+
+func TestGuardRateLimitedNotDuplicate(t *testing.T) {
+	for name, newStore := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+			guard, clock := newTestGuard(t, newStore())
+			config := Config{DedupeWindow: 10 * time.Minute, RateLimit: 1, RateInterval: time.Minute}
+
+			decision, err := guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+
+			decision, err = guard.Check(ctx, "oncall", config, "cpu high", "CPU high")
+			require.NoError(t, err)
+			assert.False(decision.Send)
+			assert.Equal(ReasonRateLimited, decision.Reason)
+
+			// the rate limited notification was never delivered, so its retry is not a duplicate
+			clock.Advance(time.Minute)
+			decision, err = guard.Check(ctx, "oncall", config, "cpu high", "CPU high")
+			require.NoError(t, err)
+			assert.True(decision.Send)
+
+			// once it is sent, the next retry is a duplicate
+			clock.Advance(time.Minute)
+			decision, err = guard.Check(ctx, "oncall", config, "cpu high", "CPU high")
+			require.NoError(t, err)
+			assert.False(decision.Send)
+			assert.Equal(ReasonDuplicate, decision.Reason)
+		})
+	}
+}
+
+func TestGuardDigestLimit(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	guard, clock := newTestGuard(t, NewMemoryStore())
+	config := Config{RateLimit: 1, RateInterval: time.Minute, Digest: true}
+
+	defer func(maxDigestMessages int) { MaxDigestMessages = maxDigestMessages }(MaxDigestMessages)
+	MaxDigestMessages = 2
+
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		_, err := guard.Check(ctx, "oncall", config, "", text)
+		require.NoError(t, err)
+	}
+
+	// only the text of the first suppressed notifications is kept, the others are counted
+	clock.Advance(time.Minute)
+	digest, err := guard.FlushDigest(ctx, "oncall", config)
+	require.NoError(t, err)
+	assert.Equal(&Digest{Messages: []string{"two", "three"}, Omitted: 2}, digest)
+}
+
+func TestMemoryStoreDropsExpired(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	store := NewMemoryStore()
+	clock := &testClock{now: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)}
+
+	require.NoError(t, store.Put(ctx, dedupeKeyPrefix+"a", &Entry{Start: clock.Now(), Expires: clock.Now().Add(time.Minute)}, clock.Now()))
+	require.NoError(t, store.Put(ctx, dedupeKeyPrefix+"b", &Entry{Start: clock.Now(), Expires: clock.Now().Add(time.Hour)}, clock.Now()))
+
+	// expired entries are dropped when another key is put, without being read
+	clock.Advance(2 * time.Minute)
+	require.NoError(t, store.Put(ctx, "other", &Entry{Start: clock.Now()}, clock.Now()))
+	keys, err := store.Keys(ctx, dedupeKeyPrefix)
+	require.NoError(t, err)
+	assert.Equal([]string{dedupeKeyPrefix + "b"}, keys)
+}
+
+func TestFileStorePersists(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedupe.json")
+
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+	guard, clock := newTestGuard(t, store)
+	config := Config{DedupeWindow: time.Hour}
+
+	decision, err := guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+	require.NoError(t, err)
+	assert.True(decision.Send)
+
+	// a restarted process still knows the notification was sent
+	store, err = NewFileStore(path)
+	require.NoError(t, err)
+	guard = NewGuard(store)
+	guard.Now = clock.Now
+
+	decision, err = guard.Check(ctx, "oncall", config, "disk full", "Disk full")
+	require.NoError(t, err)
+	assert.False(decision.Send)
+
+	// expired entries are dropped when another key is put
+	clock.Advance(2 * time.Hour)
+	require.NoError(t, store.Put(ctx, "other", &Entry{Start: clock.Now()}, clock.Now()))
+	keys, err := store.Keys(ctx, dedupeKeyPrefix)
+	require.NoError(t, err)
+	assert.Empty(keys)
+}
@@ -0,0 +1,214 @@
+// Package dedupe holds the state of the notifier dedupe windows and rate limits, and the guard the executor consults
+// before sending a notification.
+package dedupe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is the state of a dedupe key, or of the rate limit of a notifier
+type Entry struct {
+	// Start of the dedupe window or of the rate limit interval
+	Start time.Time `json:"start"`
+	// Expires is when the store may drop the entry, never if zero
+	Expires time.Time `json:"expires,omitempty"`
+	// Count of the notifications seen in the window or interval
+	Count int `json:"count"`
+	// Suppressed holds the text of the notifications suppressed by the rate limit, to be sent as a digest
+	Suppressed []string `json:"suppressed,omitempty"`
+	// Omitted counts the notifications suppressed by the rate limit once Suppressed holds MaxDigestMessages
+	Omitted int `json:"omitted,omitempty"`
+}
+
+func (e *Entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Store persists the dedupe and rate limit entries. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry of the key, nil if there is no entry or it has expired
+	Get(ctx context.Context, key string, now time.Time) (*Entry, error)
+	// Put stores the entry of the key, the store may drop the entries expired at now
+	Put(ctx context.Context, key string, entry *Entry, now time.Time) error
+	Delete(ctx context.Context, key string) error
+	// Keys returns the keys with the prefix, including expired entries which have not been dropped yet
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// MemoryStore is a Store which does not survive a restart of the process. Expired entries are dropped when an entry
+// is put.
+type MemoryStore struct {
+	mut     sync.Mutex
+	entries map[string]*Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*Entry),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string, now time.Time) (*Entry, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if entry.expired(now) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return copyEntry(entry), nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, entry *Entry, now time.Time) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	dropExpired(s.entries, now)
+	s.entries[key] = copyEntry(entry)
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return keysWithPrefix(s.entries, prefix), nil
+}
+
+// FileStore is a Store persisted to a JSON file, so that dedupe windows and rate limits survive a restart. The file
+// is rewritten on every change and expired entries are dropped when an entry is put. It is not meant to be shared by
+// several processes.
+type FileStore struct {
+	mut     sync.Mutex
+	path    string
+	entries map[string]*Entry
+}
+
+// NewFileStore loads the store from the file, which is created on the first change if it does not exist
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		entries: make(map[string]*Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.entries); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(_ context.Context, key string, now time.Time) (*Entry, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(now) {
+		return nil, nil
+	}
+	return copyEntry(entry), nil
+}
+
+func (s *FileStore) Put(_ context.Context, key string, entry *Entry, now time.Time) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	dropExpired(s.entries, now)
+	s.entries[key] = copyEntry(entry)
+	return s.save()
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	delete(s.entries, key)
+	return s.save()
+}
+
+func (s *FileStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return keysWithPrefix(s.entries, prefix), nil
+}
+
+// save writes the entries to a temporary file which replaces the store file, so a crash never leaves a partial file
+func (s *FileStore) save() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func copyEntry(entry *Entry) *Entry {
+	res := *entry
+	res.Suppressed = append([]string(nil), entry.Suppressed...)
+	return &res
+}
+
+func dropExpired(entries map[string]*Entry, now time.Time) {
+	for key, entry := range entries {
+		if entry.expired(now) {
+			delete(entries, key)
+		}
+	}
+}
+
+func keysWithPrefix(entries map[string]*Entry, prefix string) []string {
+	var res []string
+	for key := range entries {
+		if strings.HasPrefix(key, prefix) {
+			res = append(res, key)
+		}
+	}
+	return res
+}
@@ -0,0 +1,399 @@
+// Package email builds RFC 5322 messages for the email step: plain text and HTML alternatives, attachments and
+// inline images referenced from the HTML by their content ID, and sends them over SMTP.
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const crlf = "\r\n"
+
+// the headers set by the builder, which can not be overridden by custom headers
+var reservedHeaders = []string{
+	"Bcc", "Cc", "Content-Disposition", "Content-Id", "Content-Transfer-Encoding", "Content-Type", "Date", "From",
+	"Message-Id", "Mime-Version", "Reply-To", "Subject", "To",
+}
+
+var (
+	// RFC 5322 field name: printable ASCII except the colon, restricted to the token characters used in practice
+	headerNameRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+	// the id-left@id-right of a Content-ID, without the angle brackets
+	contentIdRegex = regexp.MustCompile(`^[A-Za-z0-9!#$%&'*+\-/=?^_{|}~.@]+$`)
+)
+
+// Attachment is a file attached to a message, an inline attachment is referenced from the HTML body as cid:<ContentId>
+type Attachment struct {
+	Filename string
+	// ContentType is the MIME type of the attachment, detected from the filename extension when not set
+	ContentType string
+	Content     []byte
+	// ContentId makes the attachment inline
+	ContentId string
+}
+
+func (a *Attachment) IsInline() bool {
+	return a.ContentId != ""
+}
+
+func (a *Attachment) mediaType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+	if contentType := mime.TypeByExtension(filepath.Ext(a.Filename)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Message is an email message
+type Message struct {
+	From       string
+	SenderName string
+	To         []string
+	Cc         []string
+	// Bcc recipients receive the message but are not written in its headers
+	Bcc     []string
+	ReplyTo []string
+	Subject string
+
+	// Text and Html are the plain text and HTML bodies, sent as alternatives when both are set
+	Text string
+	Html string
+
+	// Headers are custom headers, such as X-Priority or List-Unsubscribe
+	Headers     map[string]string
+	Attachments []Attachment
+
+	// Date defaults to the time the message is built
+	Date time.Time
+	// MessageId defaults to a random ID in the domain of the sender
+	MessageId string
+}
+
+// ValidateHeader returns an error if a custom header has an invalid name, is set by the message builder or has a value
+// spanning more than one line
+func ValidateHeader(name, value string) error {
+	if !headerNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid header name %s", name)
+	}
+	canonical := textproto.CanonicalMIMEHeaderKey(name)
+	for _, reserved := range reservedHeaders {
+		if canonical == reserved {
+			return fmt.Errorf("the header %s is set by the email step and can not be overridden", name)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("the value of the header %s must not contain line breaks", name)
+	}
+	return nil
+}
+
+// ValidateContentId returns an error if the content ID is not valid in a Content-ID header
+func ValidateContentId(contentId string) error {
+	if !contentIdRegex.MatchString(contentId) {
+		return fmt.Errorf("invalid content_id %s: use letters, digits and the characters .@-_", contentId)
+	}
+	return nil
+}
+
+// Recipients returns the addresses of the To, Cc and Bcc recipients of the message, without duplicates
+func (m *Message) Recipients() ([]string, error) {
+	var recipients []string
+	seen := map[string]bool{}
+
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		addresses, err := parseAddresses(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range addresses {
+			if !seen[address.Address] {
+				seen[address.Address] = true
+				recipients = append(recipients, address.Address)
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("the message has no recipients")
+	}
+	return recipients, nil
+}
+
+// Bytes returns the RFC 5322 encoding of the message, with CRLF line endings
+func (m *Message) Bytes() ([]byte, error) {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %s: %w", m.From, err)
+	}
+	if m.SenderName != "" {
+		from.Name = m.SenderName
+	}
+
+	if _, err := m.Recipients(); err != nil {
+		return nil, err
+	}
+
+	body, err := m.bodyPart()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + crlf)
+	}
+
+	writeHeader("From", from.String())
+	for _, h := range []struct {
+		name      string
+		addresses []string
+	}{{"Reply-To", m.ReplyTo}, {"To", m.To}, {"Cc", m.Cc}} {
+		if len(h.addresses) == 0 {
+			continue
+		}
+		addresses, err := parseAddresses(h.addresses)
+		if err != nil {
+			return nil, err
+		}
+		formatted := make([]string, len(addresses))
+		for i, address := range addresses {
+			formatted[i] = address.String()
+		}
+		writeHeader(h.name, strings.Join(formatted, ", "))
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	writeHeader("Date", date.Format(time.RFC1123Z))
+
+	messageId := m.MessageId
+	if messageId == "" {
+		messageId, err = newMessageId(from.Address)
+		if err != nil {
+			return nil, err
+		}
+	}
+	writeHeader("Message-ID", "<"+strings.Trim(messageId, "<>")+">")
+	writeHeader("MIME-Version", "1.0")
+
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := ValidateHeader(name, m.Headers[name]); err != nil {
+			return nil, err
+		}
+		writeHeader(textproto.CanonicalMIMEHeaderKey(name), mime.QEncoding.Encode("utf-8", m.Headers[name]))
+	}
+
+	header, content, err := body.render()
+	if err != nil {
+		return nil, err
+	}
+	writeMimeHeader(&buf, header)
+	buf.WriteString(crlf)
+	buf.Write(content)
+
+	return buf.Bytes(), nil
+}
+
+// bodyPart returns the MIME structure of the message:
+//
+//	multipart/mixed
+//	├── multipart/alternative
+//	│   ├── text/plain
+//	│   └── multipart/related
+//	│       ├── text/html
+//	│       └── inline attachments
+//	└── attachments
+//
+// where each multipart with a single part is replaced by the part itself.
+func (m *Message) bodyPart() (*mimePart, error) {
+	var inline, attached []*mimePart
+	for i := range m.Attachments {
+		a := &m.Attachments[i]
+		part, err := attachmentPart(a)
+		if err != nil {
+			return nil, err
+		}
+		if a.IsInline() {
+			inline = append(inline, part)
+		} else {
+			attached = append(attached, part)
+		}
+	}
+
+	if len(inline) > 0 && m.Html == "" {
+		return nil, fmt.Errorf("inline attachments are referenced from the html body, which is not set")
+	}
+
+	var alternatives []*mimePart
+	if m.Text != "" || m.Html == "" {
+		alternatives = append(alternatives, textPart("text/plain", m.Text))
+	}
+	if m.Html != "" {
+		alternatives = append(alternatives, multipartOf("related", append([]*mimePart{textPart("text/html", m.Html)}, inline...)))
+	}
+
+	return multipartOf("mixed", append([]*mimePart{multipartOf("alternative", alternatives)}, attached...)), nil
+}
+
+func attachmentPart(a *Attachment) (*mimePart, error) {
+	if a.Filename == "" {
+		return nil, fmt.Errorf("an attachment must have a filename")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(a.mediaType())
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %s for attachment %s: %w", a.ContentType, a.Filename, err)
+	}
+	params["name"] = a.Filename
+
+	disposition := "attachment"
+	header := textproto.MIMEHeader{}
+	if a.IsInline() {
+		if err := ValidateContentId(a.ContentId); err != nil {
+			return nil, err
+		}
+		disposition = "inline"
+		header.Set("Content-ID", "<"+a.ContentId+">")
+	}
+
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+
+	return &mimePart{header: header, body: wrapBase64(a.Content)}, nil
+}
+
+func textPart(mediaType string, text string) *mimePart {
+	var body bytes.Buffer
+	w := quotedprintable.NewWriter(&body)
+	// the writer only fails if the underlying writer does
+	_, _ = w.Write([]byte(text))
+	_ = w.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mediaType+"; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: body.Bytes()}
+}
+
+// mimePart is a leaf part with a body or a multipart with children
+type mimePart struct {
+	header   textproto.MIMEHeader
+	body     []byte
+	subtype  string
+	children []*mimePart
+}
+
+func multipartOf(subtype string, children []*mimePart) *mimePart {
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &mimePart{subtype: subtype, children: children}
+}
+
+// render returns the header and encoded body of the part
+func (p *mimePart) render() (textproto.MIMEHeader, []byte, error) {
+	if p.children == nil {
+		return p.header, p.body, nil
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, child := range p.children {
+		header, body, err := child.render()
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := w.Write(body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+p.subtype, map[string]string{"boundary": mw.Boundary()}))
+	return header, buf.Bytes(), nil
+}
+
+// writeMimeHeader writes the header in a stable order
+func writeMimeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			buf.WriteString(name + ": " + value + crlf)
+		}
+	}
+}
+
+// wrapBase64 returns the base64 encoding of the content in lines of 76 characters, as required by RFC 2045
+func wrapBase64(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + crlf)
+		encoded = encoded[76:]
+	}
+	if encoded != "" {
+		buf.WriteString(encoded + crlf)
+	}
+	return buf.Bytes()
+}
+
+func parseAddresses(addresses []string) ([]*mail.Address, error) {
+	parsed := make([]*mail.Address, 0, len(addresses))
+	for _, address := range addresses {
+		a, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %s: %w", address, err)
+		}
+		parsed = append(parsed, a)
+	}
+	return parsed, nil
+}
+
+func newMessageId(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	return hex.EncodeToString(b) + "@" + domain, nil
+}
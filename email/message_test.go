@@ -0,0 +1,402 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// parsedPart is a MIME part of a parsed message, with its decoded body
+type parsedPart struct {
+	mediaType string
+	params    map[string]string
+	header    textproto.MIMEHeader
+	body      string
+	children  []parsedPart
+}
+
+func parsePart(t *testing.T, header textproto.MIMEHeader, body io.Reader) parsedPart {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid content type %s: %v", header.Get("Content-Type"), err)
+	}
+	part := parsedPart{mediaType: mediaType, params: params, header: header}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("invalid multipart: %v", err)
+			}
+			// the reader decodes quoted-printable parts and removes their Content-Transfer-Encoding header
+			part.children = append(part.children, parsePart(t, p.Header, p))
+		}
+		return part
+	}
+
+	switch header.Get("Content-Transfer-Encoding") {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	part.body = string(content)
+	return part
+}
+
+func parseMessage(t *testing.T, raw []byte) (*mail.Message, parsedPart) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	return msg, parsePart(t, textproto.MIMEHeader(msg.Header), msg.Body)
+}
+
+func (p parsedPart) structure() string {
+	if len(p.children) == 0 {
+		return p.mediaType
+	}
+	children := make([]string, len(p.children))
+	for i, child := range p.children {
+		children[i] = child.structure()
+	}
+	return p.mediaType + "(" + strings.Join(children, ", ") + ")"
+}
+
+func TestMessageBytes(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff}
+	report := bytes.Repeat([]byte("id,amount\n1,100\n"), 20)
+
+	tests := []struct {
+		name          string
+		message       Message
+		wantStructure string
+	}{
+		{
+			name:          "text",
+			message:       Message{Text: "Hello"},
+			wantStructure: "text/plain",
+		},
+		{
+			name:          "html",
+			message:       Message{Html: "<p>Hello</p>"},
+			wantStructure: "text/html",
+		},
+		{
+			name:          "alternatives",
+			message:       Message{Text: "Hello", Html: "<p>Hello</p>"},
+			wantStructure: "multipart/alternative(text/plain, text/html)",
+		},
+		{
+			name: "attachments and inline images",
+			message: Message{
+				Text: "Hello",
+				Html: `<p>Hello</p><img src="cid:logo@example.com">`,
+				Attachments: []Attachment{
+					{Filename: "report.csv", Content: report},
+					{Filename: "logo.png", Content: png, ContentId: "logo@example.com"},
+				},
+			},
+			wantStructure: "multipart/mixed(multipart/alternative(text/plain, multipart/related(text/html, image/png)), text/csv)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.message
+			m.From = "sleepy.feynman@example.com"
+			m.SenderName = "Feynman"
+			m.To = []string{"friendly.curie@example.com"}
+			m.Subject = "Flowpipe Test"
+
+			raw, err := m.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() error = %v", err)
+			}
+			if strings.Contains(strings.ReplaceAll(string(raw), "\r\n", ""), "\n") {
+				t.Errorf("Bytes() has bare line feeds")
+			}
+
+			msg, body := parseMessage(t, raw)
+			if got := body.structure(); got != tt.wantStructure {
+				t.Errorf("Bytes() structure = %s, want %s", got, tt.wantStructure)
+			}
+			if got := msg.Header.Get("From"); got != `"Feynman" <sleepy.feynman@example.com>` {
+				t.Errorf("Bytes() from = %s", got)
+			}
+			if msg.Header.Get("Message-Id") == "" || msg.Header.Get("Date") == "" {
+				t.Errorf("Bytes() must set the Message-ID and Date headers")
+			}
+
+			for _, part := range flatten(body) {
+				switch part.mediaType {
+				case "text/csv":
+					if part.body != string(report) || part.params["name"] != "report.csv" {
+						t.Errorf("Bytes() attachment = %q, params %v", part.body, part.params)
+					}
+					if !strings.HasPrefix(part.header.Get("Content-Disposition"), "attachment") {
+						t.Errorf("Bytes() attachment disposition = %s", part.header.Get("Content-Disposition"))
+					}
+				case "image/png":
+					if part.body != string(png) || part.header.Get("Content-Id") != "<logo@example.com>" {
+						t.Errorf("Bytes() inline image = %q, content id %s", part.body, part.header.Get("Content-Id"))
+					}
+					if !strings.HasPrefix(part.header.Get("Content-Disposition"), "inline") {
+						t.Errorf("Bytes() inline image disposition = %s", part.header.Get("Content-Disposition"))
+					}
+				}
+			}
+		})
+	}
+}
+
+func flatten(p parsedPart) []parsedPart {
+	parts := []parsedPart{p}
+	for _, child := range p.children {
+		parts = append(parts, flatten(child)...)
+	}
+	return parts
+}
+
+func TestMessageHeaders(t *testing.T) {
+	m := Message{
+		From:    "sleepy.feynman@example.com",
+		To:      []string{"friendly.curie@example.com", "Johannes Kepler <angry.kepler@example.com>"},
+		Cc:      []string{"serene.turing@example.com"},
+		Bcc:     []string{"elastic.bassi@example.com"},
+		ReplyTo: []string{"support@example.com"},
+		Subject: "Überprüfung",
+		Text:    strings.Repeat("a long line which must be wrapped by the quoted-printable encoding ", 3) + "= ü",
+		Headers: map[string]string{
+			"x-priority":       "1",
+			"List-Unsubscribe": "<mailto:unsubscribe@example.com>",
+		},
+		Date:      time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC),
+		MessageId: "1234@example.com",
+	}
+
+	raw, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	msg, body := parseMessage(t, raw)
+
+	want := map[string]string{
+		"Reply-To":         "<support@example.com>",
+		"To":               `<friendly.curie@example.com>, "Johannes Kepler" <angry.kepler@example.com>`,
+		"Cc":               "<serene.turing@example.com>",
+		"Bcc":              "",
+		"Date":             "Mon, 01 Jul 2024 09:30:00 +0000",
+		"Message-Id":       "<1234@example.com>",
+		"Mime-Version":     "1.0",
+		"X-Priority":       "1",
+		"List-Unsubscribe": "<mailto:unsubscribe@example.com>",
+	}
+	for name, value := range want {
+		if got := msg.Header.Get(name); got != value {
+			t.Errorf("Bytes() header %s = %s, want %s", name, got, value)
+		}
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != m.Subject {
+		t.Errorf("Bytes() subject = %s, want %s", subject, m.Subject)
+	}
+	if body.body != m.Text {
+		t.Errorf("Bytes() text = %q, want %q", body.body, m.Text)
+	}
+
+	recipients, err := m.Recipients()
+	if err != nil {
+		t.Fatalf("Recipients() error = %v", err)
+	}
+	wantRecipients := []string{"friendly.curie@example.com", "angry.kepler@example.com", "serene.turing@example.com", "elastic.bassi@example.com"}
+	if !reflect.DeepEqual(recipients, wantRecipients) {
+		t.Errorf("Recipients() = %v, want %v", recipients, wantRecipients)
+	}
+}
+
+func TestMessageBytesErrors(t *testing.T) {
+	tests := map[string]Message{
+		"invalid from":            {From: "feynman", To: []string{"friendly.curie@example.com"}},
+		"no recipients":           {From: "sleepy.feynman@example.com"},
+		"invalid recipient":       {From: "sleepy.feynman@example.com", To: []string{"curie"}},
+		"reserved header":         {From: "sleepy.feynman@example.com", To: []string{"friendly.curie@example.com"}, Headers: map[string]string{"bcc": "eve@example.com"}},
+		"header injection":        {From: "sleepy.feynman@example.com", To: []string{"friendly.curie@example.com"}, Headers: map[string]string{"X-Tag": "a\r\nBcc: eve@example.com"}},
+		"inline without html":     {From: "sleepy.feynman@example.com", To: []string{"friendly.curie@example.com"}, Attachments: []Attachment{{Filename: "logo.png", ContentId: "logo"}}},
+		"attachment without name": {From: "sleepy.feynman@example.com", To: []string{"friendly.curie@example.com"}, Attachments: []Attachment{{Content: []byte("a")}}},
+	}
+
+	for name, m := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := m.Bytes(); err == nil {
+				t.Errorf("Bytes() expected an error")
+			}
+		})
+	}
+}
+
+// smtpStandIn is a local SMTP server which accepts a single message
+type smtpStandIn struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	from       string
+	recipients []string
+	data       []byte
+}
+
+func startSmtpStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpStandIn{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	defer s.wg.Done()
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	c := textproto.NewConn(conn)
+	defer c.Close()
+
+	_ = c.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			_ = c.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			_ = c.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.recipients = append(s.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			_ = c.PrintfLine("250 OK")
+		case command == "DATA":
+			_ = c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			s.data, err = c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			_ = c.PrintfLine("250 OK")
+		case command == "QUIT":
+			_ = c.PrintfLine("221 Bye")
+			return
+		default:
+			_ = c.PrintfLine("250 OK")
+		}
+	}
+}
+
+func TestSendInput(t *testing.T) {
+	server := startSmtpStandIn(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	reportPath := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(reportPath, []byte("id,amount\n1,100\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	portNumber, err := strconv.ParseInt(port, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SendInput(map[string]interface{}{
+		schema.AttributeTypeHost:        host,
+		schema.AttributeTypePort:        portNumber,
+		schema.AttributeTypeFrom:        "sleepy.feynman@example.com",
+		schema.AttributeTypeTo:          []string{"friendly.curie@example.com"},
+		schema.AttributeTypeBcc:         []string{"elastic.bassi@example.com"},
+		schema.AttributeTypeSubject:     "Daily report",
+		schema.AttributeTypeBody:        "<p>See the attached report</p>",
+		schema.AttributeTypeContentType: "text/html",
+		schema.AttributeTypeAttachments: []map[string]interface{}{
+			{schema.AttributeTypePath: reportPath},
+			{schema.AttributeTypeContent: `{"total": 100}`, schema.AttributeTypeFilename: "summary.json"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	server.listener.Close()
+	server.wg.Wait()
+
+	if server.from != "sleepy.feynman@example.com" {
+		t.Errorf("SendInput() from = %s", server.from)
+	}
+	if want := []string{"friendly.curie@example.com", "elastic.bassi@example.com"}; !reflect.DeepEqual(server.recipients, want) {
+		t.Errorf("SendInput() recipients = %v, want %v", server.recipients, want)
+	}
+
+	msg, body := parseMessage(t, server.data)
+	if msg.Header.Get("Bcc") != "" {
+		t.Errorf("SendInput() must not send the Bcc header")
+	}
+	if got, want := body.structure(), "multipart/mixed(text/html, text/csv, application/json)"; got != want {
+		t.Errorf("SendInput() structure = %s, want %s", got, want)
+	}
+	if got := body.children[1].params["name"]; got != "report.csv" {
+		t.Errorf("SendInput() attachment name = %s, want report.csv", got)
+	}
+	if got := body.children[2].body; got != `{"total": 100}` {
+		t.Errorf("SendInput() attachment content = %s", got)
+	}
+}
+
+func TestValidateHeader(t *testing.T) {
+	for _, name := range []string{"X-Priority", "List-Unsubscribe", "x-mailer"} {
+		if err := ValidateHeader(name, "value"); err != nil {
+			t.Errorf("ValidateHeader(%s) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"Subject", "content-type", "X Priority", "X:Priority", ""} {
+		if err := ValidateHeader(name, "value"); err == nil {
+			t.Errorf("ValidateHeader(%s) expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package email
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// Send sends the message through the SMTP server at addr, upgrading the connection with STARTTLS when the server
+// supports it. The auth may be nil for a server which does not require authentication.
+func Send(addr string, auth smtp.Auth, m *Message) error {
+	msg, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	recipients, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %s: %w", m.From, err)
+	}
+
+	return smtp.SendMail(addr, auth, from.Address, recipients, msg)
+}
+
+// SendInput sends the message of the inputs of an email step through the SMTP server of the step
+func SendInput(input map[string]interface{}) error {
+	m, err := MessageFromInput(input)
+	if err != nil {
+		return err
+	}
+
+	host, _ := input[schema.AttributeTypeHost].(string)
+	port, _ := input[schema.AttributeTypePort].(int64)
+	if host == "" || port == 0 {
+		return fmt.Errorf("the host and port of the smtp server must be set")
+	}
+
+	var auth smtp.Auth
+	if username, _ := input[schema.AttributeTypeSmtpUsername].(string); username != "" {
+		password, _ := input[schema.AttributeTypeSmtpPassword].(string)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return Send(net.JoinHostPort(host, strconv.FormatInt(port, 10)), auth, m)
+}
+
+// MessageFromInput returns the message of the inputs of an email step. The body is the HTML alternative when the
+// content type is text/html and html_body is not set, attachments with a path are read from disk.
+func MessageFromInput(input map[string]interface{}) (*Message, error) {
+	m := &Message{}
+
+	m.From, _ = input[schema.AttributeTypeFrom].(string)
+	m.SenderName, _ = input[schema.AttributeTypeSenderName].(string)
+	m.To, _ = input[schema.AttributeTypeTo].([]string)
+	m.Cc, _ = input[schema.AttributeTypeCc].([]string)
+	m.Bcc, _ = input[schema.AttributeTypeBcc].([]string)
+	m.ReplyTo, _ = input[schema.AttributeTypeReplyTo].([]string)
+	m.Subject, _ = input[schema.AttributeTypeSubject].(string)
+	m.Headers, _ = input[schema.AttributeTypeHeaders].(map[string]string)
+
+	body, _ := input[schema.AttributeTypeBody].(string)
+	m.Html, _ = input[schema.AttributeTypeHtmlBody].(string)
+	if contentType, _ := input[schema.AttributeTypeContentType].(string); contentType == "text/html" && m.Html == "" {
+		m.Html = body
+	} else {
+		m.Text = body
+	}
+
+	attachments, _ := input[schema.AttributeTypeAttachments].([]map[string]interface{})
+	for i, a := range attachments {
+		attachment := Attachment{}
+		attachment.Filename, _ = a[schema.AttributeTypeFilename].(string)
+		attachment.ContentType, _ = a[schema.AttributeTypeContentType].(string)
+		attachment.ContentId, _ = a[schema.AttributeTypeContentId].(string)
+
+		if path, ok := a[schema.AttributeTypePath].(string); ok {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read attachment %d: %w", i+1, err)
+			}
+			attachment.Content = content
+			if attachment.Filename == "" {
+				attachment.Filename = filepath.Base(path)
+			}
+		} else if content, ok := a[schema.AttributeTypeContent].(string); ok {
+			attachment.Content = []byte(content)
+		} else {
+			return nil, fmt.Errorf("attachment %d must set path or content", i+1)
+		}
+
+		m.Attachments = append(m.Attachments, attachment)
+	}
+
+	return m, nil
+}
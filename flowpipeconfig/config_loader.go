@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/connection"
 	"github.com/turbot/pipe-fittings/credential"
 	"github.com/turbot/pipe-fittings/filepaths"
 	"github.com/turbot/pipe-fittings/funcs"
@@ -81,12 +82,15 @@ func LoadFlowpipeConfig(configPaths []string) (res *FlowpipeConfig, errorsAndWar
 	return res, errorsAndWarnings
 }
 
+// importCredentials imports the Steampipe connections of the credential_import sources as credentials, and as
+// pipeling connections for the plugins with a registered connection type
 func (f *FlowpipeConfig) importCredentials() error {
 	if len(f.CredentialImports) == 0 {
 		return nil
 	}
 
 	credentials := map[string]credential.Credential{}
+	pipelingConnections := map[string]connection.PipelingConnection{}
 	for _, credentialImport := range f.CredentialImports {
 		if credentialImport.Source == nil {
 			continue
@@ -165,6 +169,21 @@ func (f *FlowpipeConfig) importCredentials() error {
 				if diags.HasErrors() {
 					return error_helpers.HclDiagsToError("Flowpipe Config", diags)
 				}
+
+				if pipelingConnectionType := parse.SteampipePluginConnectionType(connectionType); pipelingConnectionType != "" {
+					connectionFullName := fmt.Sprintf("%s.%s", pipelingConnectionType, connectionName)
+
+					// Return error if the flowpipe already has a connection with same type and name
+					if f.PipelingConnections[connectionFullName] != nil || pipelingConnections[connectionFullName] != nil {
+						return perr.BadRequestWithMessage(fmt.Sprintf("Connection with name '%s' already exists", connectionFullName))
+					}
+
+					conn, moreDiags := parse.DecodeImportedPipelingConnection(pipelingConnectionType, connectionName, body, block.DefRange)
+					if moreDiags.HasErrors() {
+						return error_helpers.HclDiagsToError("Flowpipe Config", moreDiags)
+					}
+					pipelingConnections[connectionFullName] = conn
+				}
 				evalCtx := &hcl.EvalContext{
 					Variables: make(map[string]cty.Value),
 					Functions: make(map[string]function.Function),
@@ -198,6 +217,7 @@ func (f *FlowpipeConfig) importCredentials() error {
 	}
 
 	maps.Copy(f.Credentials, credentials)
+	maps.Copy(f.PipelingConnections, pipelingConnections)
 	return nil
 }
 
@@ -295,7 +315,7 @@ func (f *FlowpipeConfig) loadFlowpipeConfigBlocks(configPath string, opts *loadC
 				continue
 			}
 
-			notifier, moreDiags := parse.DecodeNotifier(configPath, block, evalContext)
+			notifier, moreDiags := parse.DecodeNotifier(configPath, block, evalContext, fileData)
 			if len(moreDiags) > 0 {
 				diags = append(diags, moreDiags...)
 				slog.Debug("failed to decode notifier block")
@@ -332,6 +352,7 @@ func buildEvalContextWithIntegrationsOnly(configPath string, integrations map[st
 	email := make(map[string]cty.Value)
 	http := make(map[string]cty.Value)
 	teams := make(map[string]cty.Value)
+	webhook := make(map[string]cty.Value)
 
 	for k, v := range integrations {
 		parts := strings.Split(k, ".")
@@ -356,11 +377,13 @@ func buildEvalContextWithIntegrationsOnly(configPath string, integrations map[st
 			vars = http
 		case schema.IntegrationTypeMsTeams:
 			vars = teams
+		case schema.IntegrationTypeWebhook:
+			vars = webhook
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "invalid integration type",
-				Detail:   "integration type must be one of slack, email, msteams or http",
+				Detail:   "integration type must be one of slack, email, msteams, http or webhook",
 				Subject:  v.GetDeclRange(),
 			})
 			continue
@@ -394,11 +417,14 @@ func buildEvalContextWithIntegrationsOnly(configPath string, integrations map[st
 	if len(teams) > 0 {
 		integrationVariables[schema.IntegrationTypeMsTeams] = cty.ObjectVal(teams)
 	}
+	if len(webhook) > 0 {
+		integrationVariables[schema.IntegrationTypeWebhook] = cty.ObjectVal(webhook)
+	}
 
 	variables["integration"] = cty.ObjectVal(integrationVariables)
 
 	return &hcl.EvalContext{
-		Functions: funcs.ContextFunctions(configPath),
+		Functions: funcs.ConfigContextFunctions(configPath),
 		Variables: variables,
 	}, diags
 }
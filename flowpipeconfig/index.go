@@ -3,6 +3,7 @@ package flowpipeconfig
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -23,6 +24,9 @@ type FlowpipeConfig struct {
 	Notifiers           map[string]modconfig.Notifier
 	PipelingConnections map[string]connection.PipelingConnection
 
+	// ConnectionResolver caches the resolved connections, it is cleared when the config is reloaded
+	ConnectionResolver *connection.ConnectionResolver
+
 	watcher                 *filewatcher.FileWatcher
 	fileWatcherErrorHandler func(context.Context, error)
 
@@ -120,6 +124,15 @@ func (f *FlowpipeConfig) Equals(other *FlowpipeConfig) bool {
 	return true
 }
 
+// TestConnections resolves and tests the pipeling connections concurrently, caching the resolved connections
+func (f *FlowpipeConfig) TestConnections(ctx context.Context) *connection.ConnectionTestReport {
+	f.loadLock.Lock()
+	connections := maps.Clone(f.PipelingConnections)
+	f.loadLock.Unlock()
+
+	return connection.TestConnections(ctx, connections, f.ConnectionResolver)
+}
+
 func (f *FlowpipeConfig) SetupWatcher(ctx context.Context, errorHandler func(context.Context, error)) error {
 	watcherOptions := &filewatcher.WatcherOptions{
 		Directories: f.ConfigPaths,
@@ -165,6 +178,11 @@ func (f *FlowpipeConfig) handleFileWatcherEvent(ctx context.Context) {
 		return
 	}
 
+	// the connections may have changed, or their credentials been refreshed outside of the config
+	if f.ConnectionResolver != nil {
+		f.ConnectionResolver.Clear()
+	}
+
 	if !newFpConfig.Equals(f) {
 		f.updateResources(newFpConfig)
 
@@ -208,6 +226,7 @@ func NewFlowpipeConfig(configPaths []string) *FlowpipeConfig {
 		Notifiers:           defaultNotifiers,
 		ConfigPaths:         configPaths,
 		PipelingConnections: defaultPipelingConnections,
+		ConnectionResolver:  connection.NewConnectionResolver(),
 		loadLock:            &sync.Mutex{},
 	}
 
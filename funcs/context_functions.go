@@ -1,6 +1,8 @@
 package funcs
 
 import (
+	"maps"
+
 	"github.com/hashicorp/hcl/v2/ext/tryfunc"
 	"github.com/turbot/terraform-components/lang/funcs"
 	ctyyaml "github.com/zclconf/go-cty-yaml"
@@ -137,3 +139,11 @@ func ContextFunctions(baseDir string) map[string]function.Function {
 
 	return ctxFuncs
 }
+
+// ConfigContextFunctions returns the context functions together with the secret functions, which read files and run
+// commands. They are used for the config and the root mod, dependency mods only get the ContextFunctions.
+func ConfigContextFunctions(baseDir string) map[string]function.Function {
+	ctxFuncs := ContextFunctions(baseDir)
+	maps.Copy(ctxFuncs, SecretFunctions(baseDir))
+	return ctxFuncs
+}
@@ -0,0 +1,248 @@
+package funcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/httpclient"
+	"github.com/turbot/pipe-fittings/sanitize"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SecretTimeout is the timeout of the vault_secret requests and exec_secret commands
+var SecretTimeout = 30 * time.Second
+
+// The secret functions return plain strings rather than marked values, which the connections and steps could not be
+// decoded from. Instead, the secrets they return are registered with the sanitize package, so they are redacted from
+// any output.
+
+// SecretFunctions returns the secret functions. They read files and run commands, so they must only be available to
+// trusted HCL, i.e. the config and the root mod.
+func SecretFunctions(baseDir string) map[string]function.Function {
+	return map[string]function.Function{
+		"secret_file":  MakeSecretFileFunc(baseDir),
+		"vault_secret": VaultSecretFunc,
+		"exec_secret":  ExecSecretFunc,
+	}
+}
+
+// MakeSecretFileFunc returns the secret_file function, which reads a secret from a file relative to the base dir. The
+// trailing newline of the file is removed.
+func MakeSecretFileFunc(baseDir string) function.Function {
+	return function.New(&function.Spec{
+		Description: `Read a secret from a file`,
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			if strings.HasPrefix(path, "~/") {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return cty.UnknownVal(cty.String), function.NewArgError(0, err)
+				}
+				path = filepath.Join(home, path[2:])
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return cty.UnknownVal(cty.String), function.NewArgError(0, fmt.Errorf("failed to read secret file: %w", err))
+			}
+
+			return secretVal(strings.TrimRight(string(data), "\r\n")), nil
+		},
+	})
+}
+
+// VaultSecretFunc reads the key of a secret of a KV v2 secrets engine. The connection is a vault connection, or any
+// object with its address and token, which default to VAULT_ADDR and VAULT_TOKEN. The first segment of the path is the
+// mount of the secrets engine.
+var VaultSecretFunc = function.New(&function.Spec{
+	Description: `Read a key of a secret from a HashiCorp Vault KV v2 secrets engine`,
+	Params: []function.Parameter{
+		{
+			Name: "connection",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "path",
+			Type: cty.String,
+		},
+		{
+			Name: "key",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if !args[0].IsWhollyKnown() || isTemporaryConnection(args[0]) {
+			// the connection is only resolved when the pipeline runs
+			return cty.UnknownVal(cty.String), nil
+		}
+
+		address, err := stringAttribute(args[0], "address", "VAULT_ADDR")
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgError(0, err)
+		}
+		token, err := stringAttribute(args[0], "token", "VAULT_TOKEN")
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgError(0, err)
+		}
+
+		path := strings.Trim(args[1].AsString(), "/")
+		mount, secretPath, ok := strings.Cut(path, "/")
+		if !ok || secretPath == "" {
+			return cty.UnknownVal(cty.String), function.NewArgError(1, fmt.Errorf("path %s must be of the form <mount>/<secret>", path))
+		}
+		key := args[2].AsString()
+
+		value, err := readVaultSecret(address, token, mount, secretPath, key)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return secretVal(value), nil
+	},
+})
+
+// ExecSecretFunc runs a command, e.g. the CLI of a password manager, and returns its output without the trailing
+// newline. The command is run by the shell.
+var ExecSecretFunc = function.New(&function.Spec{
+	Description: `Read a secret from the output of a command`,
+	Params: []function.Parameter{
+		{
+			Name: "command",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		command := args[0].AsString()
+
+		ctx, cancel := context.WithTimeout(context.Background(), SecretTimeout)
+		defer cancel()
+
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			// the command is not included in the error, it may hold a secret
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return cty.UnknownVal(cty.String), function.NewArgError(0, fmt.Errorf("secret command failed: %s", msg))
+		}
+
+		return secretVal(strings.TrimRight(stdout.String(), "\r\n")), nil
+	},
+})
+
+func secretVal(value string) cty.Value {
+	sanitize.RegisterSensitiveValue(value)
+	return cty.StringVal(value)
+}
+
+// isTemporaryConnection returns whether the value is the placeholder of a connection used when the mod is parsed
+func isTemporaryConnection(val cty.Value) bool {
+	if val.IsNull() || !val.Type().IsObjectType() || !val.Type().HasAttribute("temporary") {
+		return false
+	}
+	temporary := val.GetAttr("temporary")
+	return temporary.Type().Equals(cty.Bool) && !temporary.IsNull() && temporary.True()
+}
+
+// stringAttribute returns the attribute of the connection, falling back to the environment variable like the vault
+// connection does when it is resolved
+func stringAttribute(val cty.Value, name, envVar string) (string, error) {
+	if val.IsNull() || !(val.Type().IsObjectType() || val.Type().IsMapType()) {
+		return "", fmt.Errorf("must be a vault connection or an object with address and token")
+	}
+
+	attr, ok := val.AsValueMap()[name]
+	if ok && !attr.IsNull() && attr.Type().Equals(cty.String) && attr.AsString() != "" {
+		return attr.AsString(), nil
+	}
+	if v := os.Getenv(envVar); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s is not set", name)
+}
+
+func readVaultSecret(address, token, mount, secretPath, key string) (string, error) {
+	client, err := httpclient.ConfigFromViper().Merge(&httpclient.Config{
+		Timeout: utils.ToPointer(SecretTimeout),
+	}).Client(nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid http settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(address, "/"), mount, secretPath)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s/%s: %w", mount, secretPath, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read vault secret %s/%s: status %d", mount, secretPath, resp.StatusCode)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid vault secret %s/%s: %w", mount, secretPath, err)
+	}
+
+	value, ok := secret.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s/%s has no key %s", mount, secretPath, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	// other values are returned as JSON
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -178,3 +178,36 @@ func AttributeToMap(attr *hcl.Attribute, evalContext *hcl.EvalContext, allowExpr
 
 	return res, diags
 }
+
+func AttributeToStringSlice(attr *hcl.Attribute, evalContext *hcl.EvalContext, allowExpression bool) ([]string, hcl.Diagnostics) {
+	if attr.Expr == nil {
+		return nil, nil
+	}
+
+	expr := attr.Expr
+	if len(expr.Variables()) > 0 && !allowExpression {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Expression not allowed in " + attr.Name,
+			Subject:  &attr.Range,
+		}}
+	}
+
+	val, err := attr.Expr.Value(evalContext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, convErr := CtyToGoStringSlice(val, val.Type())
+	if convErr != nil {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to parse " + attr.Name + " attribute as list of strings",
+			Detail:   convErr.Error(),
+			Subject:  &attr.Range,
+		}}
+	}
+
+	return res, hcl.Diagnostics{}
+}
@@ -0,0 +1,306 @@
+// Package httpclient builds the http clients used by the http step, the http based integrations and the
+// credential resolvers from the transport settings: proxy, timeouts, redirects and HTTP/2.
+package httpclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultMaxRedirects is the number of redirects followed when max_redirects is not set, the net/http default
+const DefaultMaxRedirects = 10
+
+var validProxySchemes = []string{"http", "https", "socks5"}
+
+// Config is the transport settings of an http client. Unset values fall back to the base config (see Merge) and
+// then to the net/http defaults: proxy from the environment, no timeouts, 10 redirects and HTTP/2 enabled.
+//
+// ConnectTimeout bounds establishing the connection (dial and TLS handshake), Timeout bounds the whole request
+// including reading the response body.
+type Config struct {
+	ProxyUrl       *string        `json:"proxy_url,omitempty"`
+	NoProxy        []string       `json:"no_proxy,omitempty"`
+	ConnectTimeout *time.Duration `json:"connect_timeout,omitempty"`
+	Timeout        *time.Duration `json:"timeout,omitempty"`
+	MaxRedirects   *int           `json:"max_redirects,omitempty"`
+	Http2          *bool          `json:"http2,omitempty"`
+}
+
+// ConfigFromViper returns the transport settings set at workspace profile level
+func ConfigFromViper() *Config {
+	config := &Config{}
+
+	if viper.IsSet(constants.ArgHttpProxyUrl) {
+		config.ProxyUrl = utils.ToPointer(viper.GetString(constants.ArgHttpProxyUrl))
+	}
+	if viper.IsSet(constants.ArgHttpNoProxy) {
+		config.NoProxy = viper.GetStringSlice(constants.ArgHttpNoProxy)
+	}
+	if viper.IsSet(constants.ArgHttpConnectTimeout) {
+		config.ConnectTimeout = utils.ToPointer(viper.GetDuration(constants.ArgHttpConnectTimeout))
+	}
+	if viper.IsSet(constants.ArgHttpTimeout) {
+		config.Timeout = utils.ToPointer(viper.GetDuration(constants.ArgHttpTimeout))
+	}
+	if viper.IsSet(constants.ArgHttpMaxRedirects) {
+		config.MaxRedirects = utils.ToPointer(viper.GetInt(constants.ArgHttpMaxRedirects))
+	}
+	if viper.IsSet(constants.ArgHttp2) {
+		config.Http2 = utils.ToPointer(viper.GetBool(constants.ArgHttp2))
+	}
+
+	return config
+}
+
+// Merge returns a copy of the config with the unset values taken from the base config
+func (c *Config) Merge(base *Config) *Config {
+	res := &Config{}
+	if c != nil {
+		*res = *c
+	}
+	if base == nil {
+		return res
+	}
+
+	if res.ProxyUrl == nil {
+		res.ProxyUrl = base.ProxyUrl
+	}
+	if res.NoProxy == nil {
+		res.NoProxy = base.NoProxy
+	}
+	if res.ConnectTimeout == nil {
+		res.ConnectTimeout = base.ConnectTimeout
+	}
+	if res.Timeout == nil {
+		res.Timeout = base.Timeout
+	}
+	if res.MaxRedirects == nil {
+		res.MaxRedirects = base.MaxRedirects
+	}
+	if res.Http2 == nil {
+		res.Http2 = base.Http2
+	}
+
+	return res
+}
+
+func (c *Config) Equals(other *Config) bool {
+	if c == nil && other == nil {
+		return true
+	}
+
+	if c == nil && other != nil || c != nil && other == nil {
+		return false
+	}
+
+	return utils.PtrEqual(c.ProxyUrl, other.ProxyUrl) &&
+		slices.Equal(c.NoProxy, other.NoProxy) &&
+		utils.PtrEqual(c.ConnectTimeout, other.ConnectTimeout) &&
+		utils.PtrEqual(c.Timeout, other.Timeout) &&
+		utils.PtrEqual(c.MaxRedirects, other.MaxRedirects) &&
+		utils.BoolPtrEqual(c.Http2, other.Http2)
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.ProxyUrl != nil && *c.ProxyUrl != "" {
+		if err := ValidateProxyUrl(*c.ProxyUrl); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.ConnectTimeout != nil && *c.ConnectTimeout < 0 {
+		errs = append(errs, fmt.Errorf("connect timeout must not be negative"))
+	}
+	if c.Timeout != nil && *c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative"))
+	}
+	if c.MaxRedirects != nil && *c.MaxRedirects < 0 {
+		errs = append(errs, fmt.Errorf("max redirects must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateProxyUrl returns an error if the proxy url is not an absolute http, https or socks5 url
+func ValidateProxyUrl(proxyUrl string) error {
+	u, err := url.Parse(proxyUrl)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url %s: %w", proxyUrl, err)
+	}
+	if !slices.Contains(validProxySchemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("invalid proxy url %s: the url must start with one of %s", proxyUrl, strings.Join(validProxySchemes, "://, ")+"://")
+	}
+	return nil
+}
+
+// Proxy returns the proxy function of the transport. Without a proxy url the proxy is read from the environment
+// (HTTP_PROXY, HTTPS_PROXY and NO_PROXY), the no proxy list applies in both cases.
+func (c *Config) Proxy() func(*http.Request) (*url.URL, error) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.ProxyUrl != nil {
+		proxyConfig.HTTPProxy = *c.ProxyUrl
+		proxyConfig.HTTPSProxy = *c.ProxyUrl
+	}
+	if c.NoProxy != nil {
+		proxyConfig.NoProxy = strings.Join(c.NoProxy, ",")
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// Transport returns a transport with the proxy, connect timeout and HTTP/2 settings applied
+func (c *Config) Transport(tlsConfig *tls.Config) (*http.Transport, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.Proxy = c.Proxy()
+
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if c.ConnectTimeout != nil && *c.ConnectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   *c.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = *c.ConnectTimeout
+	}
+
+	if c.Http2 != nil && !*c.Http2 {
+		// a non-nil empty map disables the HTTP/2 upgrade
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+// Client returns an http client using the transport settings, the timeout and the redirect policy
+func (c *Config) Client(tlsConfig *tls.Config) (*http.Client, error) {
+	transport, err := c.Transport(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: transport,
+	}
+
+	if c.Timeout != nil {
+		client.Timeout = *c.Timeout
+	}
+
+	if c.MaxRedirects != nil {
+		maxRedirects := *c.MaxRedirects
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if maxRedirects == 0 {
+				// return the redirect response rather than following it
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		}
+	}
+
+	return client, nil
+}
+
+// ConfigFromMap returns the transport settings of the http step inputs or of an http based integration. Timeouts
+// are either a duration string or a whole number of milliseconds.
+func ConfigFromMap(values map[string]interface{}) (*Config, error) {
+	config := &Config{}
+
+	if v, ok := values[schema.AttributeTypeProxyUrl].(string); ok {
+		config.ProxyUrl = &v
+	}
+
+	switch v := values[schema.AttributeTypeNoProxy].(type) {
+	case []string:
+		config.NoProxy = v
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", schema.AttributeTypeNoProxy)
+			}
+			config.NoProxy = append(config.NoProxy, s)
+		}
+	}
+
+	for name, target := range map[string]**time.Duration{schema.AttributeTypeConnectTimeout: &config.ConnectTimeout, schema.AttributeTypeTimeout: &config.Timeout} {
+		value, ok := values[name]
+		if !ok || value == nil {
+			continue
+		}
+		duration, err := toDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*target = &duration
+	}
+
+	if value, ok := values[schema.AttributeTypeMaxRedirects]; ok && value != nil {
+		maxRedirects, err := toInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", schema.AttributeTypeMaxRedirects, err)
+		}
+		config.MaxRedirects = &maxRedirects
+	}
+
+	if v, ok := values[schema.AttributeTypeHttp2].(bool); ok {
+		config.Http2 = &v
+	}
+
+	return config, config.Validate()
+}
+
+func toDuration(value interface{}) (time.Duration, error) {
+	if s, ok := value.(string); ok {
+		return time.ParseDuration(s)
+	}
+
+	ms, err := toInt(value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%v is not a whole number", v)
+		}
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
@@ -0,0 +1,193 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+func TestConfigFromMap(t *testing.T) {
+	config, err := ConfigFromMap(map[string]interface{}{
+		"proxy_url":       "http://proxy.example.com:3128",
+		"no_proxy":        []interface{}{"localhost", ".internal.example.com"},
+		"connect_timeout": "5s",
+		"timeout":         int64(30000),
+		"max_redirects":   float64(3),
+		"http2":           false,
+	})
+	if err != nil {
+		t.Fatalf("ConfigFromMap() error = %v", err)
+	}
+
+	expected := &Config{
+		ProxyUrl:       utils.ToPointer("http://proxy.example.com:3128"),
+		NoProxy:        []string{"localhost", ".internal.example.com"},
+		ConnectTimeout: utils.ToPointer(5 * time.Second),
+		Timeout:        utils.ToPointer(30 * time.Second),
+		MaxRedirects:   utils.ToPointer(3),
+		Http2:          utils.ToPointer(false),
+	}
+	if !config.Equals(expected) {
+		t.Errorf("ConfigFromMap() = %+v, want %+v", config, expected)
+	}
+
+	invalid := []map[string]interface{}{
+		{"proxy_url": "proxy.example.com"},
+		{"proxy_url": "ftp://proxy.example.com"},
+		{"connect_timeout": "5 seconds"},
+		{"timeout": "-1s"},
+		{"max_redirects": -1},
+		{"max_redirects": 1.5},
+		{"no_proxy": []interface{}{1}},
+	}
+	for _, values := range invalid {
+		if _, err := ConfigFromMap(values); err == nil {
+			t.Errorf("ConfigFromMap(%v) expected an error", values)
+		}
+	}
+}
+
+func TestConfig_Merge(t *testing.T) {
+	base := &Config{
+		ProxyUrl: utils.ToPointer("http://proxy.example.com:3128"),
+		Timeout:  utils.ToPointer(10 * time.Second),
+	}
+	config := &Config{
+		Timeout: utils.ToPointer(time.Second),
+	}
+
+	merged := config.Merge(base)
+	if *merged.ProxyUrl != "http://proxy.example.com:3128" {
+		t.Errorf("Merge() proxy url = %s, want the base proxy url", *merged.ProxyUrl)
+	}
+	if *merged.Timeout != time.Second {
+		t.Errorf("Merge() timeout = %s, want 1s", *merged.Timeout)
+	}
+	if config.ProxyUrl != nil {
+		t.Errorf("Merge() must not modify the config")
+	}
+
+	var nilConfig *Config
+	if !nilConfig.Merge(base).Equals(base) {
+		t.Errorf("Merge() of a nil config must return the base config")
+	}
+}
+
+func TestConfig_Proxy(t *testing.T) {
+	config := &Config{
+		ProxyUrl: utils.ToPointer("http://proxy.example.com:3128"),
+		NoProxy:  []string{".internal.example.com"},
+	}
+	proxy := config.Proxy()
+
+	tests := map[string]string{
+		"https://api.example.com/items":     "http://proxy.example.com:3128",
+		"https://api.internal.example.com/": "",
+	}
+	for target, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		got, err := proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy() error = %v", err)
+		}
+		if (got == nil && want != "") || (got != nil && got.String() != want) {
+			t.Errorf("Proxy(%s) = %v, want %q", target, got, want)
+		}
+	}
+}
+
+func TestConfig_Client(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/redirect1", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// the test server must not be reached through a proxy set in the environment
+	noProxy := []string{"127.0.0.1", "localhost"}
+
+	tests := []struct {
+		name       string
+		config     *Config
+		path       string
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "default redirects",
+			config:     &Config{NoProxy: noProxy},
+			path:       "/redirect",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no redirects",
+			config:     &Config{NoProxy: noProxy, MaxRedirects: utils.ToPointer(0)},
+			path:       "/redirect",
+			wantStatus: http.StatusFound,
+		},
+		{
+			name:    "too many redirects",
+			config:  &Config{NoProxy: noProxy, MaxRedirects: utils.ToPointer(1)},
+			path:    "/redirect",
+			wantErr: true,
+		},
+		{
+			name:    "timeout",
+			config:  &Config{NoProxy: noProxy, Timeout: utils.ToPointer(50 * time.Millisecond)},
+			path:    "/slow",
+			wantErr: true,
+		},
+		{
+			name:       "http2 disabled",
+			config:     &Config{NoProxy: noProxy, Http2: utils.ToPointer(false), ConnectTimeout: utils.ToPointer(time.Second)},
+			path:       "/ok",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.config.Client(nil)
+			if err != nil {
+				t.Fatalf("Client() error = %v", err)
+			}
+
+			resp, err := client.Get(server.URL + tt.path)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("expected an error")
+				}
+				var urlErr *url.Error
+				if !errors.As(err, &urlErr) {
+					t.Errorf("expected a url error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package modconfig
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeOfDayLayout is the HH:MM format of the start and end of a daily window
+const timeOfDayLayout = "15:04"
+
+const minutesPerDay = 24 * 60
+
+// dailyWindow is a window between two times of day in a location, shared by the schedule triggers and the business
+// hours of the notifiers. The end is exclusive and a window which ends before it starts spans midnight.
+type dailyWindow struct {
+	// start and end are minutes since midnight
+	start    int
+	end      int
+	location *time.Location
+}
+
+// newDailyWindow returns the window between the start and end times of day (HH:MM). An empty start is midnight and
+// an empty end is the end of the day. A window which starts and ends at the same time is rejected, it would always be
+// open.
+func newDailyWindow(start, end string, location *time.Location) (*dailyWindow, error) {
+	w := &dailyWindow{end: minutesPerDay, location: location}
+
+	if start != "" {
+		minutes, err := parseTimeOfDay(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start %s, must be in the HH:MM format", start)
+		}
+		w.start = minutes
+	}
+	if end != "" {
+		minutes, err := parseTimeOfDay(end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end %s, must be in the HH:MM format", end)
+		}
+		w.end = minutes
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("start and end can not be the same time")
+	}
+	return w, nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of a HH:MM time of day
+func parseTimeOfDay(timeOfDay string) (int, error) {
+	t, err := time.Parse(timeOfDayLayout, timeOfDay)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains returns true if the time is inside the window, and the day in the window location the window containing
+// it started, which is the day before after midnight in a window spanning midnight
+func (w *dailyWindow) contains(t time.Time) (bool, time.Time) {
+	t = t.In(w.location)
+	minute := t.Hour()*60 + t.Minute()
+
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end, t
+	}
+	if minute >= w.start {
+		return true, t
+	}
+	if minute < w.end {
+		return true, t.AddDate(0, 0, -1)
+	}
+	return false, t
+}
@@ -0,0 +1,54 @@
+package modconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyWindow(t *testing.T) {
+	tests := []struct {
+		title   string
+		start   string
+		end     string
+		time    time.Time
+		inside  bool
+		dayDiff int
+	}{
+		{"inside", "9:00", "17:00", time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC), true, 0},
+		{"before a single digit start hour", "9:00", "17:00", time.Date(2024, 1, 10, 8, 59, 0, 0, time.UTC), false, 0},
+		{"end is exclusive", "09:00", "17:00", time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC), false, 0},
+		{"no start", "", "06:00", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), true, 0},
+		{"no end", "22:00", "", time.Date(2024, 1, 10, 23, 59, 0, 0, time.UTC), true, 0},
+		{"spans midnight before midnight", "22:00", "06:00", time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC), true, 0},
+		{"spans midnight after midnight", "22:00", "06:00", time.Date(2024, 1, 10, 5, 0, 0, 0, time.UTC), true, -1},
+		{"spans midnight outside", "22:00", "06:00", time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			window, err := newDailyWindow(tt.start, tt.end, time.UTC)
+			if !assert.NoError(t, err) {
+				return
+			}
+			inside, day := window.contains(tt.time)
+			assert.Equal(t, tt.inside, inside)
+			if inside {
+				assert.Equal(t, tt.time.AddDate(0, 0, tt.dayDiff).Day(), day.Day())
+			}
+		})
+	}
+}
+
+func TestDailyWindowInvalid(t *testing.T) {
+	_, err := newDailyWindow("09:00", "9:00", time.UTC)
+	assert.ErrorContains(t, err, "start and end can not be the same time")
+
+	// no start is midnight
+	_, err = newDailyWindow("", "00:00", time.UTC)
+	assert.ErrorContains(t, err, "start and end can not be the same time")
+
+	_, err = newDailyWindow("9am", "17:00", time.UTC)
+	assert.ErrorContains(t, err, "invalid start 9am, must be in the HH:MM format")
+}
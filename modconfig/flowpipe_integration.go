@@ -2,12 +2,14 @@ package modconfig
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/httpclient"
 	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/utils"
@@ -87,6 +89,14 @@ type SlackIntegration struct {
 	SigningSecret *string `json:"signing_secret,omitempty" cty:"signing_secret" hcl:"signing_secret,optional"`
 	WebhookUrl    *string `json:"webhook_url,omitempty" cty:"webhook_url" hcl:"webhook_url,optional"`
 	Channel       *string `json:"channel,omitempty" cty:"channel" hcl:"channel,optional"`
+
+	// http transport settings, unset values fall back to the workspace profile settings
+	ProxyUrl       *string  `json:"proxy_url,omitempty" cty:"proxy_url" hcl:"proxy_url,optional"`
+	NoProxy        []string `json:"no_proxy,omitempty" cty:"no_proxy" hcl:"no_proxy,optional"`
+	ConnectTimeout *string  `json:"connect_timeout,omitempty" cty:"connect_timeout" hcl:"connect_timeout,optional"`
+	Timeout        *string  `json:"timeout,omitempty" cty:"timeout" hcl:"timeout,optional"`
+	MaxRedirects   *int     `json:"max_redirects,omitempty" cty:"max_redirects" hcl:"max_redirects,optional"`
+	Http2          *bool    `json:"http2,omitempty" cty:"http2" hcl:"http2,optional"`
 }
 
 func (i *SlackIntegration) Equals(other Integration) bool {
@@ -114,7 +124,13 @@ func (i *SlackIntegration) Equals(other Integration) bool {
 		((i.WebhookUrl == nil && otherSlack.WebhookUrl == nil) ||
 			(i.WebhookUrl != nil && otherSlack.WebhookUrl != nil && *i.WebhookUrl == *otherSlack.WebhookUrl)) &&
 		((i.Channel == nil && otherSlack.Channel == nil) ||
-			(i.Channel != nil && otherSlack.Channel != nil && *i.Channel == *otherSlack.Channel))
+			(i.Channel != nil && otherSlack.Channel != nil && *i.Channel == *otherSlack.Channel)) &&
+		utils.PtrEqual(i.ProxyUrl, otherSlack.ProxyUrl) &&
+		slices.Equal(i.NoProxy, otherSlack.NoProxy) &&
+		utils.PtrEqual(i.ConnectTimeout, otherSlack.ConnectTimeout) &&
+		utils.PtrEqual(i.Timeout, otherSlack.Timeout) &&
+		utils.PtrEqual(i.MaxRedirects, otherSlack.MaxRedirects) &&
+		utils.BoolPtrEqual(i.Http2, otherSlack.Http2)
 }
 
 func (i *SlackIntegration) CtyValue() (cty.Value, error) {
@@ -158,6 +174,7 @@ func (i *SlackIntegration) MapInterface() (map[string]interface{}, error) {
 	if i.Channel != nil {
 		res["channel"] = *i.Channel
 	}
+	setIntegrationHttpTransportValues(res, i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
 
 	res["full_name"] = i.FullName
 	res["short_name"] = i.ShortName
@@ -221,9 +238,24 @@ func (i *SlackIntegration) Validate() hcl.Diagnostics {
 		})
 	}
 
+	if _, err := i.HttpTransportConfig(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid http settings: " + i.Name(),
+			Detail:   err.Error(),
+			Subject:  &i.DeclRange,
+		})
+	}
+
 	return diags
 }
 
+// HttpTransportConfig returns the proxy, timeout and redirect settings of the integration, to be merged with the
+// workspace profile settings
+func (i *SlackIntegration) HttpTransportConfig() (*httpclient.Config, error) {
+	return integrationHttpTransportConfig(i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
+}
+
 func (i *SlackIntegration) SetAttributes(hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
@@ -250,6 +282,8 @@ func (i *SlackIntegration) SetAttributes(hclAttributes hcl.Attributes, evalConte
 				continue
 			}
 			i.WebhookUrl = webhookUrl
+		case schema.AttributeTypeProxyUrl, schema.AttributeTypeNoProxy, schema.AttributeTypeConnectTimeout, schema.AttributeTypeTimeout, schema.AttributeTypeMaxRedirects, schema.AttributeTypeHttp2:
+			diags = append(diags, setIntegrationHttpTransportAttribute(attr, evalContext, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)...)
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -604,6 +638,8 @@ func integrationFromCtyValue(val cty.Value) (Integration, error) {
 		return HttpIntegrationFromCtyValue(val)
 	case schema.IntegrationTypeMsTeams:
 		return MsTeamsIntegrationFromCtyValue(val)
+	case schema.IntegrationTypeWebhook:
+		return WebhookIntegrationFromCtyValue(val)
 	}
 	return nil, perr.BadRequestWithMessage(fmt.Sprintf("Unsupported integration type: %s", integrationType))
 }
@@ -672,6 +708,8 @@ func SlackIntegrationFromCtyValue(val cty.Value) (*SlackIntegration, error) {
 		i.Channel = &channelStr
 	}
 
+	integrationHttpTransportFromValueMap(valMap, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)
+
 	return i, nil
 }
 
@@ -791,11 +829,37 @@ func MsTeamsIntegrationFromCtyValue(val cty.Value) (*MsTeamsIntegration, error)
 		i.WebhookUrl = &webhookUrlStr
 	}
 
+	integrationHttpTransportFromValueMap(valMap, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)
+
 	i.IntegrationName = val.GetAttr("integration_name").AsString()
 
 	return i, nil
 }
 
+func integrationHttpTransportFromValueMap(valMap map[string]cty.Value, proxyUrl **string, noProxy *[]string, connectTimeout, timeout **string, maxRedirects **int, http2 **bool) {
+	if v, ok := valMap[schema.AttributeTypeProxyUrl]; ok && !v.IsNull() {
+		*proxyUrl = utils.ToPointer(v.AsString())
+	}
+	if v, ok := valMap[schema.AttributeTypeNoProxy]; ok && !v.IsNull() {
+		for _, item := range v.AsValueSlice() {
+			*noProxy = append(*noProxy, item.AsString())
+		}
+	}
+	if v, ok := valMap[schema.AttributeTypeConnectTimeout]; ok && !v.IsNull() {
+		*connectTimeout = utils.ToPointer(v.AsString())
+	}
+	if v, ok := valMap[schema.AttributeTypeTimeout]; ok && !v.IsNull() {
+		*timeout = utils.ToPointer(v.AsString())
+	}
+	if v, ok := valMap[schema.AttributeTypeMaxRedirects]; ok && !v.IsNull() {
+		n, _ := v.AsBigFloat().Int64()
+		*maxRedirects = utils.ToPointer(int(n))
+	}
+	if v, ok := valMap[schema.AttributeTypeHttp2]; ok && !v.IsNull() {
+		*http2 = utils.ToPointer(v.True())
+	}
+}
+
 func HclImplFromAttributes(hclResourceImpl *HclResourceImpl, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
 
 	diags := hcl.Diagnostics{}
@@ -892,6 +956,11 @@ func NewIntegrationFromBlock(block *hcl.Block) Integration {
 			Type:            integrationType,
 			IntegrationName: integrationFullName,
 		}
+	case schema.IntegrationTypeWebhook:
+		return &WebhookIntegration{
+			HclResourceImpl: hclResourceImpl,
+			Type:            integrationType,
+		}
 	}
 
 	return nil
@@ -992,6 +1061,14 @@ type MsTeamsIntegration struct {
 
 	// teams
 	WebhookUrl *string `json:"webhook_url,omitempty" cty:"webhook_url" hcl:"webhook_url,optional"`
+
+	// http transport settings, unset values fall back to the workspace profile settings
+	ProxyUrl       *string  `json:"proxy_url,omitempty" cty:"proxy_url" hcl:"proxy_url,optional"`
+	NoProxy        []string `json:"no_proxy,omitempty" cty:"no_proxy" hcl:"no_proxy,optional"`
+	ConnectTimeout *string  `json:"connect_timeout,omitempty" cty:"connect_timeout" hcl:"connect_timeout,optional"`
+	Timeout        *string  `json:"timeout,omitempty" cty:"timeout" hcl:"timeout,optional"`
+	MaxRedirects   *int     `json:"max_redirects,omitempty" cty:"max_redirects" hcl:"max_redirects,optional"`
+	Http2          *bool    `json:"http2,omitempty" cty:"http2" hcl:"http2,optional"`
 }
 
 func (i *MsTeamsIntegration) CtyValue() (cty.Value, error) {
@@ -1036,7 +1113,13 @@ func (i *MsTeamsIntegration) Equals(other Integration) bool {
 		i.StartLineNumber == otherTeams.StartLineNumber &&
 		i.EndLineNumber == otherTeams.EndLineNumber &&
 		((i.WebhookUrl == nil && otherTeams.WebhookUrl == nil) ||
-			(i.WebhookUrl != nil && otherTeams.WebhookUrl != nil && *i.WebhookUrl == *otherTeams.WebhookUrl))
+			(i.WebhookUrl != nil && otherTeams.WebhookUrl != nil && *i.WebhookUrl == *otherTeams.WebhookUrl)) &&
+		utils.PtrEqual(i.ProxyUrl, otherTeams.ProxyUrl) &&
+		slices.Equal(i.NoProxy, otherTeams.NoProxy) &&
+		utils.PtrEqual(i.ConnectTimeout, otherTeams.ConnectTimeout) &&
+		utils.PtrEqual(i.Timeout, otherTeams.Timeout) &&
+		utils.PtrEqual(i.MaxRedirects, otherTeams.MaxRedirects) &&
+		utils.BoolPtrEqual(i.Http2, otherTeams.Http2)
 }
 
 func (i *MsTeamsIntegration) GetIntegrationType() string {
@@ -1050,6 +1133,7 @@ func (i *MsTeamsIntegration) MapInterface() (map[string]interface{}, error) {
 	if i.WebhookUrl != nil {
 		res["webhook_url"] = *i.WebhookUrl
 	}
+	setIntegrationHttpTransportValues(res, i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
 
 	res["full_name"] = i.FullName
 	res["short_name"] = i.ShortName
@@ -1079,6 +1163,8 @@ func (i *MsTeamsIntegration) SetAttributes(hclAttributes hcl.Attributes, evalCon
 				continue
 			}
 			i.WebhookUrl = webhookUrl
+		case schema.AttributeTypeProxyUrl, schema.AttributeTypeNoProxy, schema.AttributeTypeConnectTimeout, schema.AttributeTypeTimeout, schema.AttributeTypeMaxRedirects, schema.AttributeTypeHttp2:
+			diags = append(diags, setIntegrationHttpTransportAttribute(attr, evalContext, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)...)
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -1106,5 +1192,88 @@ func (i *MsTeamsIntegration) Validate() hcl.Diagnostics {
 		})
 	}
 
+	if _, err := i.HttpTransportConfig(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid http settings: " + i.Name(),
+			Detail:   err.Error(),
+			Subject:  &i.DeclRange,
+		})
+	}
+
 	return diags
 }
+
+// HttpTransportConfig returns the proxy, timeout and redirect settings of the integration, to be merged with the
+// workspace profile settings
+func (i *MsTeamsIntegration) HttpTransportConfig() (*httpclient.Config, error) {
+	return integrationHttpTransportConfig(i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
+}
+
+func integrationHttpTransportConfig(proxyUrl *string, noProxy []string, connectTimeout, timeout *string, maxRedirects *int, http2 *bool) (*httpclient.Config, error) {
+	values := map[string]interface{}{}
+	setIntegrationHttpTransportValues(values, proxyUrl, noProxy, connectTimeout, timeout, maxRedirects, http2)
+	return httpclient.ConfigFromMap(values)
+}
+
+func setIntegrationHttpTransportValues(res map[string]interface{}, proxyUrl *string, noProxy []string, connectTimeout, timeout *string, maxRedirects *int, http2 *bool) {
+	if proxyUrl != nil {
+		res[schema.AttributeTypeProxyUrl] = *proxyUrl
+	}
+	if noProxy != nil {
+		res[schema.AttributeTypeNoProxy] = noProxy
+	}
+	if connectTimeout != nil {
+		res[schema.AttributeTypeConnectTimeout] = *connectTimeout
+	}
+	if timeout != nil {
+		res[schema.AttributeTypeTimeout] = *timeout
+	}
+	if maxRedirects != nil {
+		res[schema.AttributeTypeMaxRedirects] = *maxRedirects
+	}
+	if http2 != nil {
+		res[schema.AttributeTypeHttp2] = *http2
+	}
+}
+
+func setIntegrationHttpTransportAttribute(attr *hcl.Attribute, evalContext *hcl.EvalContext, proxyUrl **string, noProxy *[]string, connectTimeout, timeout **string, maxRedirects **int, http2 **bool) hcl.Diagnostics {
+	switch attr.Name {
+	case schema.AttributeTypeProxyUrl:
+		val, diags := hclhelpers.AttributeToString(attr, evalContext, false)
+		if len(diags) > 0 {
+			return diags
+		}
+		*proxyUrl = val
+	case schema.AttributeTypeNoProxy:
+		val, diags := hclhelpers.AttributeToStringSlice(attr, evalContext, false)
+		if len(diags) > 0 {
+			return diags
+		}
+		*noProxy = val
+	case schema.AttributeTypeConnectTimeout, schema.AttributeTypeTimeout:
+		val, diags := hclhelpers.AttributeToString(attr, evalContext, false)
+		if len(diags) > 0 {
+			return diags
+		}
+		if attr.Name == schema.AttributeTypeConnectTimeout {
+			*connectTimeout = val
+		} else {
+			*timeout = val
+		}
+	case schema.AttributeTypeMaxRedirects:
+		val, diags := hclhelpers.AttributeToInt(attr, evalContext, false)
+		if len(diags) > 0 {
+			return diags
+		}
+		*maxRedirects = utils.ToPointer(int(*val))
+	case schema.AttributeTypeHttp2:
+		val, diags := hclhelpers.AttributeToBool(attr, evalContext, false)
+		if len(diags) > 0 {
+			return diags
+		}
+		*http2 = val
+	}
+
+	return nil
+}
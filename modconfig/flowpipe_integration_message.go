@@ -0,0 +1,713 @@
+package modconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	RichMessageSeverityInfo    = "info"
+	RichMessageSeveritySuccess = "success"
+	RichMessageSeverityWarning = "warning"
+	RichMessageSeverityError   = "error"
+)
+
+var ValidRichMessageSeverities = []string{RichMessageSeverityInfo, RichMessageSeveritySuccess, RichMessageSeverityWarning, RichMessageSeverityError}
+
+// the colour of each severity, used by Slack attachments and HTML email
+var richMessageSeverityColors = map[string]string{
+	RichMessageSeverityInfo:    "#2F80ED",
+	RichMessageSeveritySuccess: "#27AE60",
+	RichMessageSeverityWarning: "#F2994A",
+	RichMessageSeverityError:   "#EB5757",
+}
+
+// the Adaptive Card text colour of each severity
+var richMessageSeverityTeamsColors = map[string]string{
+	RichMessageSeverityInfo:    "Accent",
+	RichMessageSeveritySuccess: "Good",
+	RichMessageSeverityWarning: "Warning",
+	RichMessageSeverityError:   "Attention",
+}
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var validRichMessageUrlSchemes = []string{"http", "https", "mailto"}
+
+// RichMessage is a portable message with a title, a severity and sections, rendered natively for each integration
+// type. The text of a section is a Markdown subset: paragraphs, "- " list items, **bold**, _italic_, `code` and
+// [links](https://example.com).
+type RichMessage struct {
+	Title    string `json:"title,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	// Color overrides the colour of the severity, as a #RRGGBB hex colour
+	Color    string               `json:"color,omitempty"`
+	Sections []RichMessageSection `json:"sections,omitempty"`
+}
+
+type RichMessageSection struct {
+	Text   string             `json:"text,omitempty"`
+	Fields []RichMessageField `json:"fields,omitempty"`
+	Image  *RichMessageImage  `json:"image,omitempty"`
+	Links  []RichMessageLink  `json:"links,omitempty"`
+}
+
+type RichMessageField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type RichMessageImage struct {
+	Url     string `json:"url"`
+	AltText string `json:"alt_text,omitempty"`
+}
+
+type RichMessageLink struct {
+	Text string `json:"text"`
+	Url  string `json:"url"`
+}
+
+// RichMessageFromInput returns the rich message of the inputs of a message or input step: the subject is the title,
+// the severity of a message step is the severity, the text, or the prompt of an input step, is the first Markdown
+// section and is followed by the sections of the step
+func RichMessageFromInput(input map[string]interface{}) *RichMessage {
+	m := &RichMessage{}
+	m.Title, _ = input[schema.AttributeTypeSubject].(string)
+	m.Severity, _ = input[schema.AttributeTypeSeverity].(string)
+	m.Color, _ = input[schema.AttributeTypeColor].(string)
+
+	text, ok := input[schema.AttributeTypeText].(string)
+	if !ok {
+		text, _ = input[schema.AttributeTypePrompt].(string)
+	}
+	if text != "" {
+		m.Sections = append(m.Sections, RichMessageSection{Text: text})
+	}
+
+	switch sections := input[schema.AttributeTypeSections].(type) {
+	case []RichMessageSection:
+		m.Sections = append(m.Sections, sections...)
+	case nil:
+	default:
+		// the sections of inputs which have been serialised, e.g. to the event store
+		if data, err := json.Marshal(sections); err == nil {
+			var decoded []RichMessageSection
+			if err := json.Unmarshal(data, &decoded); err == nil {
+				m.Sections = append(m.Sections, decoded...)
+			}
+		}
+	}
+	return m
+}
+
+// CtyValueToRichMessageSections converts the value of the sections attribute of a message or input step: a list of
+// objects with text, fields, image and links, e.g.
+//
+//	sections = [{
+//	  text   = "Deployed **${param.version}**"
+//	  fields = [{ title = "Env", value = "prod" }]
+//	  image  = { url = "https://example.com/graph.png", alt_text = "graph" }
+//	  links  = [{ text = "Logs", url = "https://example.com/logs" }]
+//	}]
+func CtyValueToRichMessageSections(value cty.Value) ([]RichMessageSection, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+	if !value.Type().IsListType() && !value.Type().IsTupleType() {
+		return nil, fmt.Errorf("sections must be a list of objects with text, fields, image and links")
+	}
+
+	data, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []RichMessageSection
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sections); err != nil {
+		return nil, fmt.Errorf("sections must be a list of objects with text, fields, image and links: %w", err)
+	}
+	return sections, nil
+}
+
+func (m *RichMessage) Validate() error {
+	if m.Severity != "" && !slices.Contains(ValidRichMessageSeverities, m.Severity) {
+		return fmt.Errorf("invalid severity %s: must be one of %s", m.Severity, strings.Join(ValidRichMessageSeverities, ", "))
+	}
+	if m.Color != "" && !hexColorRegex.MatchString(m.Color) {
+		return fmt.Errorf("invalid color %s: must be a #RRGGBB hex colour", m.Color)
+	}
+	for _, section := range m.Sections {
+		if section.Image != nil {
+			if section.Image.Url == "" {
+				return fmt.Errorf("an image must have a url")
+			}
+			if err := validateRichMessageUrl(section.Image.Url); err != nil {
+				return err
+			}
+		}
+		for _, link := range section.Links {
+			if link.Url == "" {
+				return fmt.Errorf("the link %s must have a url", link.Text)
+			}
+			if err := validateRichMessageUrl(link.Url); err != nil {
+				return err
+			}
+		}
+		// the links of the text are rendered as links too
+		for _, block := range parseMarkdownBlocks(section.Text) {
+			for _, line := range block.lines {
+				for _, span := range parseMarkdownSpans(line) {
+					if span.kind != markdownLink {
+						continue
+					}
+					if err := validateRichMessageUrl(span.url); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateRichMessageUrl checks the scheme of a link or image url, the message text may come from untrusted data
+// such as webhook payloads so javascript: and other schemes are rejected
+func validateRichMessageUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %w", rawUrl, err)
+	}
+	if !slices.Contains(validRichMessageUrlSchemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("invalid url %s: the scheme must be one of %s", rawUrl, strings.Join(validRichMessageUrlSchemes, ", "))
+	}
+	return nil
+}
+
+// ColorHex returns the colour of the message, the colour of its severity when it has no colour
+func (m *RichMessage) ColorHex() string {
+	if m.Color != "" {
+		return m.Color
+	}
+	return richMessageSeverityColors[m.Severity]
+}
+
+// PlainText returns the message without formatting, the fallback text of notifications
+func (m *RichMessage) PlainText() string {
+	var parts []string
+	if m.Title != "" {
+		parts = append(parts, m.Title)
+	}
+	for _, section := range m.Sections {
+		var lines []string
+		for _, block := range parseMarkdownBlocks(section.Text) {
+			for _, item := range block.lines {
+				line := renderMarkdownSpans(parseMarkdownSpans(item), plainTextSpan)
+				if block.list {
+					line = "- " + line
+				}
+				lines = append(lines, line)
+			}
+		}
+		for _, field := range section.Fields {
+			lines = append(lines, field.Title+": "+field.Value)
+		}
+		for _, link := range section.Links {
+			lines = append(lines, link.Text+": "+link.Url)
+		}
+		if len(lines) > 0 {
+			parts = append(parts, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// RichMessageRenderer renders a rich message as the payload of an integration type
+type RichMessageRenderer interface {
+	Render(*RichMessage) ([]byte, error)
+}
+
+// RichMessageRendererForIntegrationType returns the renderer of the integration type
+func RichMessageRendererForIntegrationType(integrationType string) (RichMessageRenderer, error) {
+	switch integrationType {
+	case schema.IntegrationTypeSlack:
+		return SlackMessageRenderer{}, nil
+	case schema.IntegrationTypeMsTeams:
+		return MsTeamsMessageRenderer{}, nil
+	case schema.IntegrationTypeEmail:
+		return EmailMessageRenderer{}, nil
+	case schema.IntegrationTypeHttp, schema.IntegrationTypeWebhook:
+		return JsonMessageRenderer{}, nil
+	}
+	return nil, fmt.Errorf("no message renderer for integration type %s", integrationType)
+}
+
+// RenderRichMessage renders the message as the payload of the integration
+func RenderRichMessage(integration Integration, m *RichMessage) ([]byte, error) {
+	// webhooks render the message with their own body template
+	if webhook, ok := integration.(*WebhookIntegration); ok {
+		return webhook.RenderBody(m)
+	}
+
+	renderer, err := RichMessageRendererForIntegrationType(integration.GetIntegrationType())
+	if err != nil {
+		return nil, err
+	}
+	return renderer.Render(m)
+}
+
+// SlackMessageRenderer renders Block Kit blocks in an attachment coloured by the severity
+type SlackMessageRenderer struct{}
+
+func (SlackMessageRenderer) Render(m *RichMessage) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	var blocks []map[string]interface{}
+	if m.Title != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": m.Title},
+		})
+	}
+
+	for _, section := range m.Sections {
+		if section.Text != "" {
+			blocks = append(blocks, map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": slackMarkdown(section.Text)},
+			})
+		}
+		if len(section.Fields) > 0 {
+			// Slack allows at most 10 fields per section
+			for start := 0; start < len(section.Fields); start += 10 {
+				chunk := section.Fields[start:min(start+10, len(section.Fields))]
+				fields := make([]map[string]interface{}, len(chunk))
+				for i, field := range chunk {
+					fields[i] = map[string]interface{}{"type": "mrkdwn", "text": "*" + slackEscape(field.Title) + "*\n" + slackEscape(field.Value)}
+				}
+				blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+			}
+		}
+		if section.Image != nil {
+			blocks = append(blocks, map[string]interface{}{
+				"type":      "image",
+				"image_url": section.Image.Url,
+				"alt_text":  altText(section.Image),
+			})
+		}
+		if len(section.Links) > 0 {
+			elements := make([]map[string]interface{}, len(section.Links))
+			for i, link := range section.Links {
+				elements[i] = map[string]interface{}{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": link.Text},
+					"url":  link.Url,
+				}
+			}
+			blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": elements})
+		}
+	}
+
+	payload := map[string]interface{}{
+		"text": m.PlainText(),
+	}
+	if color := m.ColorHex(); color != "" {
+		payload["attachments"] = []map[string]interface{}{{"color": color, "blocks": blocks}}
+	} else {
+		payload["blocks"] = blocks
+	}
+	return marshalPayload(payload)
+}
+
+// MsTeamsMessageRenderer renders an Adaptive Card, whose text blocks support the Markdown subset natively
+type MsTeamsMessageRenderer struct{}
+
+func (MsTeamsMessageRenderer) Render(m *RichMessage) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	body := []map[string]interface{}{}
+	if m.Title != "" {
+		title := map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   m.Title,
+			"size":   "Large",
+			"weight": "Bolder",
+			"wrap":   true,
+		}
+		if color, ok := richMessageSeverityTeamsColors[m.Severity]; ok {
+			title["color"] = color
+		}
+		body = append(body, title)
+	}
+
+	var actions []map[string]interface{}
+	for _, section := range m.Sections {
+		if section.Text != "" {
+			body = append(body, map[string]interface{}{
+				"type": "TextBlock",
+				"text": teamsMarkdown(section.Text),
+				"wrap": true,
+			})
+		}
+		if len(section.Fields) > 0 {
+			facts := make([]map[string]interface{}, len(section.Fields))
+			for i, field := range section.Fields {
+				facts[i] = map[string]interface{}{"title": field.Title, "value": field.Value}
+			}
+			body = append(body, map[string]interface{}{"type": "FactSet", "facts": facts})
+		}
+		if section.Image != nil {
+			body = append(body, map[string]interface{}{
+				"type":    "Image",
+				"url":     section.Image.Url,
+				"altText": altText(section.Image),
+			})
+		}
+		for _, link := range section.Links {
+			actions = append(actions, map[string]interface{}{
+				"type":  "Action.OpenUrl",
+				"title": link.Text,
+				"url":   link.Url,
+			})
+		}
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+
+	return marshalPayload(map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	})
+}
+
+// EmailMessageRenderer renders an HTML document with inline styles, which most email clients require
+type EmailMessageRenderer struct{}
+
+func (EmailMessageRenderer) Render(m *RichMessage) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<body style=\"font-family: Helvetica, Arial, sans-serif; font-size: 14px; color: #333333;\">\n")
+
+	borderColor := m.ColorHex()
+	if borderColor == "" {
+		borderColor = "#DDDDDD"
+	}
+	sb.WriteString("<div style=\"border-left: 4px solid " + borderColor + "; padding: 8px 16px;\">\n")
+
+	if m.Title != "" {
+		sb.WriteString("<h2 style=\"margin: 0 0 12px 0;\">" + html.EscapeString(m.Title) + "</h2>\n")
+	}
+
+	for _, section := range m.Sections {
+		for _, block := range parseMarkdownBlocks(section.Text) {
+			if block.list {
+				sb.WriteString("<ul>\n")
+				for _, item := range block.lines {
+					sb.WriteString("<li>" + renderMarkdownSpans(parseMarkdownSpans(item), htmlSpan) + "</li>\n")
+				}
+				sb.WriteString("</ul>\n")
+				continue
+			}
+			lines := make([]string, len(block.lines))
+			for i, line := range block.lines {
+				lines[i] = renderMarkdownSpans(parseMarkdownSpans(line), htmlSpan)
+			}
+			sb.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>\n")
+		}
+
+		if len(section.Fields) > 0 {
+			sb.WriteString("<table style=\"border-collapse: collapse; margin: 8px 0;\">\n")
+			for _, field := range section.Fields {
+				sb.WriteString("<tr><th style=\"text-align: left; padding: 4px 12px 4px 0;\">" + html.EscapeString(field.Title) +
+					"</th><td style=\"padding: 4px 0;\">" + html.EscapeString(field.Value) + "</td></tr>\n")
+			}
+			sb.WriteString("</table>\n")
+		}
+		if section.Image != nil {
+			sb.WriteString("<p><img src=\"" + html.EscapeString(section.Image.Url) + "\" alt=\"" + html.EscapeString(altText(section.Image)) + "\" style=\"max-width: 100%;\"></p>\n")
+		}
+		if len(section.Links) > 0 {
+			links := make([]string, len(section.Links))
+			for i, link := range section.Links {
+				links[i] = "<a href=\"" + html.EscapeString(link.Url) + "\">" + html.EscapeString(link.Text) + "</a>"
+			}
+			sb.WriteString("<p>" + strings.Join(links, " | ") + "</p>\n")
+		}
+	}
+
+	sb.WriteString("</div>\n</body>\n</html>\n")
+	return []byte(sb.String()), nil
+}
+
+// JsonMessageRenderer renders the message model as JSON, with the plain text for receivers which do not format it
+type JsonMessageRenderer struct{}
+
+func (JsonMessageRenderer) Render(m *RichMessage) ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return marshalPayload(struct {
+		*RichMessage
+		Color string `json:"color,omitempty"`
+		Text  string `json:"text"`
+	}{
+		RichMessage: m,
+		Color:       m.ColorHex(),
+		Text:        m.PlainText(),
+	})
+}
+
+// marshalPayload returns the JSON of a payload without escaping the HTML characters of the text, which is not embedded
+// in HTML
+func marshalPayload(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func altText(image *RichMessageImage) string {
+	if image.AltText != "" {
+		return image.AltText
+	}
+	return "image"
+}
+
+// markdownBlock is a paragraph or a list
+type markdownBlock struct {
+	list  bool
+	lines []string
+}
+
+// parseMarkdownBlocks splits the text in paragraphs, separated by blank lines, and lists of "- " or "* " items
+func parseMarkdownBlocks(text string) []markdownBlock {
+	var blocks []markdownBlock
+	var current *markdownBlock
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = nil
+			continue
+		}
+
+		isItem := strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ")
+		if isItem {
+			trimmed = strings.TrimSpace(trimmed[2:])
+		}
+		if current == nil || current.list != isItem {
+			blocks = append(blocks, markdownBlock{list: isItem})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, trimmed)
+	}
+	return blocks
+}
+
+type markdownSpanKind int
+
+const (
+	markdownText markdownSpanKind = iota
+	markdownBold
+	markdownItalic
+	markdownCode
+	markdownLink
+)
+
+type markdownSpan struct {
+	kind markdownSpanKind
+	text string
+	url  string
+}
+
+var markdownSpanRegex = regexp.MustCompile(`\*\*([^*]+)\*\*|_([^_]+)_|` + "`([^`]+)`" + `|\[([^\]]+)\]\(([^)\s]+)\)`)
+
+// parseMarkdownSpans splits a line in text, bold, italic, code and link spans, spans are not nested
+func parseMarkdownSpans(line string) []markdownSpan {
+	var spans []markdownSpan
+	last := 0
+	for offset := 0; offset < len(line); {
+		match := markdownSpanRegex.FindStringSubmatchIndex(line[offset:])
+		if match == nil {
+			break
+		}
+		for i := range match {
+			if match[i] >= 0 {
+				match[i] += offset
+			}
+		}
+		// the underscores of snake_case names are not italics, look for the next span after the underscore
+		if match[4] >= 0 && !isItalicBoundary(line, match[0], match[1]) {
+			offset = match[0] + 1
+			continue
+		}
+
+		if match[0] > last {
+			spans = append(spans, markdownSpan{kind: markdownText, text: line[last:match[0]]})
+		}
+		switch {
+		case match[2] >= 0:
+			spans = append(spans, markdownSpan{kind: markdownBold, text: line[match[2]:match[3]]})
+		case match[4] >= 0:
+			spans = append(spans, markdownSpan{kind: markdownItalic, text: line[match[4]:match[5]]})
+		case match[6] >= 0:
+			spans = append(spans, markdownSpan{kind: markdownCode, text: line[match[6]:match[7]]})
+		default:
+			spans = append(spans, markdownSpan{kind: markdownLink, text: line[match[8]:match[9]], url: line[match[10]:match[11]]})
+		}
+		last = match[1]
+		offset = match[1]
+	}
+	if last < len(line) {
+		spans = append(spans, markdownSpan{kind: markdownText, text: line[last:]})
+	}
+	return spans
+}
+
+// isItalicBoundary returns whether the italic span of the line from start to end is not inside a word
+func isItalicBoundary(line string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(line[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(line) {
+		if r, _ := utf8.DecodeRuneInString(line[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func renderMarkdownSpans(spans []markdownSpan, render func(markdownSpan) string) string {
+	var sb strings.Builder
+	for _, span := range spans {
+		sb.WriteString(render(span))
+	}
+	return sb.String()
+}
+
+func plainTextSpan(span markdownSpan) string {
+	if span.kind == markdownLink {
+		return span.text + " (" + span.url + ")"
+	}
+	return span.text
+}
+
+func htmlSpan(span markdownSpan) string {
+	text := html.EscapeString(span.text)
+	switch span.kind {
+	case markdownBold:
+		return "<strong>" + text + "</strong>"
+	case markdownItalic:
+		return "<em>" + text + "</em>"
+	case markdownCode:
+		return "<code>" + text + "</code>"
+	case markdownLink:
+		return "<a href=\"" + html.EscapeString(span.url) + "\">" + text + "</a>"
+	}
+	return text
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackUrlEscape percent encodes the control characters of a Slack link in its url, a | or > would end the url
+func slackUrlEscape(u string) string {
+	return strings.NewReplacer("<", "%3C", ">", "%3E", "|", "%7C").Replace(u)
+}
+
+func slackSpan(span markdownSpan) string {
+	text := slackEscape(span.text)
+	switch span.kind {
+	case markdownBold:
+		return "*" + text + "*"
+	case markdownItalic:
+		return "_" + text + "_"
+	case markdownCode:
+		return "`" + text + "`"
+	case markdownLink:
+		return "<" + slackUrlEscape(span.url) + "|" + text + ">"
+	}
+	return text
+}
+
+// slackMarkdown converts the Markdown subset to Slack mrkdwn, which has no lists so list items are bullets
+func slackMarkdown(text string) string {
+	var paragraphs []string
+	for _, block := range parseMarkdownBlocks(text) {
+		lines := make([]string, len(block.lines))
+		for i, line := range block.lines {
+			lines[i] = renderMarkdownSpans(parseMarkdownSpans(line), slackSpan)
+			if block.list {
+				lines[i] = "• " + lines[i]
+			}
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// teamsMarkdown normalises the Markdown subset for Adaptive Cards, which need a blank line between paragraphs and
+// "- " list items
+func teamsMarkdown(text string) string {
+	var paragraphs []string
+	for _, block := range parseMarkdownBlocks(text) {
+		lines := make([]string, len(block.lines))
+		for i, line := range block.lines {
+			lines[i] = line
+			if block.list {
+				lines[i] = "- " + line
+			}
+		}
+		separator := "\n\n"
+		if block.list {
+			separator = "\n"
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, separator))
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
@@ -0,0 +1,227 @@
+package modconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the rich message renderers")
+
+func testRichMessage() *RichMessage {
+	return &RichMessage{
+		Title:    "Deployment failed",
+		Severity: RichMessageSeverityError,
+		Sections: []RichMessageSection{
+			{
+				Text: "The **production** deployment of `api` failed, see the [run log](https://example.com/runs/42).\n\n" +
+					"Failed checks:\n- _health_ check of deploy_to_prod timed out\n- 3 < 5 replicas ready",
+			},
+			{
+				Fields: []RichMessageField{
+					{Title: "Pipeline", Value: "deploy.pipeline.production"},
+					{Title: "Duration", Value: "4m 12s"},
+				},
+				Image: &RichMessageImage{Url: "https://example.com/charts/latency.png", AltText: "Latency"},
+				Links: []RichMessageLink{
+					{Text: "Retry", Url: "https://example.com/runs/42/retry"},
+					{Text: "Dashboard", Url: "https://example.com/dashboard?env=production&service=api"},
+				},
+			},
+		},
+	}
+}
+
+func TestRichMessageRenderers(t *testing.T) {
+	tests := []struct {
+		integrationType string
+		golden          string
+	}{
+		{schema.IntegrationTypeSlack, "slack.json.golden"},
+		{schema.IntegrationTypeMsTeams, "msteams.json.golden"},
+		{schema.IntegrationTypeEmail, "email.html.golden"},
+		{schema.IntegrationTypeHttp, "http.json.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.integrationType, func(t *testing.T) {
+			assert := assert.New(t)
+
+			renderer, err := RichMessageRendererForIntegrationType(tt.integrationType)
+			if !assert.NoError(err) {
+				return
+			}
+
+			got, err := renderer.Render(testRichMessage())
+			if !assert.NoError(err) {
+				return
+			}
+
+			// JSON payloads are indented so the golden files are readable
+			if strings.HasSuffix(tt.golden, ".json.golden") {
+				var indented bytes.Buffer
+				if !assert.NoError(json.Indent(&indented, got, "", "  ")) {
+					return
+				}
+				indented.WriteString("\n")
+				got = indented.Bytes()
+			}
+
+			path := filepath.Join("testdata", "rich_message", tt.golden)
+			if *updateGolden {
+				assert.NoError(os.WriteFile(path, got, 0600))
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if !assert.NoError(err) {
+				return
+			}
+			assert.Equal(string(want), string(got))
+		})
+	}
+}
+
+func TestRichMessagePlainText(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Deployment failed\n\n"+
+		"The production deployment of api failed, see the run log (https://example.com/runs/42).\n"+
+		"Failed checks:\n"+
+		"- health check of deploy_to_prod timed out\n"+
+		"- 3 < 5 replicas ready\n\n"+
+		"Pipeline: deploy.pipeline.production\n"+
+		"Duration: 4m 12s\n"+
+		"Retry: https://example.com/runs/42/retry\n"+
+		"Dashboard: https://example.com/dashboard?env=production&service=api", testRichMessage().PlainText())
+
+	// the underscores of snake_case names are kept, italics need a non word character around them
+	m := &RichMessage{Sections: []RichMessageSection{{Text: "step run_query of deploy_to_prod is _slow_, see (_logs_)"}}}
+	assert.Equal("step run_query of deploy_to_prod is slow, see (logs)", m.PlainText())
+}
+
+func TestRichMessageFromInput(t *testing.T) {
+	assert := assert.New(t)
+
+	m := RichMessageFromInput(map[string]interface{}{
+		schema.AttributeTypeSubject: "Approval",
+		schema.AttributeTypePrompt:  "Do you want to **approve** the deployment?",
+	})
+	assert.Equal("Approval", m.Title)
+	assert.Equal([]RichMessageSection{{Text: "Do you want to **approve** the deployment?"}}, m.Sections)
+
+	m = RichMessageFromInput(map[string]interface{}{
+		schema.AttributeTypeText: "Hello",
+	})
+	assert.Equal("", m.Title)
+	assert.Equal([]RichMessageSection{{Text: "Hello"}}, m.Sections)
+
+	// sections and color
+	m = RichMessageFromInput(map[string]interface{}{
+		schema.AttributeTypeText:     "Hello",
+		schema.AttributeTypeColor:    "#00FF00",
+		schema.AttributeTypeSections: []RichMessageSection{{Fields: []RichMessageField{{Title: "Env", Value: "prod"}}}},
+	})
+	assert.Equal("#00FF00", m.Color)
+	assert.Equal([]RichMessageSection{{Text: "Hello"}, {Fields: []RichMessageField{{Title: "Env", Value: "prod"}}}}, m.Sections)
+
+	// serialised sections
+	m = RichMessageFromInput(map[string]interface{}{
+		schema.AttributeTypeSections: []interface{}{
+			map[string]interface{}{"links": []interface{}{map[string]interface{}{"text": "Logs", "url": "https://example.com/logs"}}},
+		},
+	})
+	assert.Equal([]RichMessageSection{{Links: []RichMessageLink{{Text: "Logs", Url: "https://example.com/logs"}}}}, m.Sections)
+}
+
+func TestCtyValueToRichMessageSections(t *testing.T) {
+	assert := assert.New(t)
+
+	sections, err := CtyValueToRichMessageSections(cty.TupleVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{
+			"text": cty.StringVal("Deployed"),
+			"fields": cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"title": cty.StringVal("Env"), "value": cty.StringVal("prod")}),
+			}),
+			"image": cty.ObjectVal(map[string]cty.Value{"url": cty.StringVal("https://example.com/graph.png")}),
+		}),
+	}))
+	assert.Nil(err)
+	assert.Equal([]RichMessageSection{{
+		Text:   "Deployed",
+		Fields: []RichMessageField{{Title: "Env", Value: "prod"}},
+		Image:  &RichMessageImage{Url: "https://example.com/graph.png"},
+	}}, sections)
+
+	// unknown attribute
+	_, err = CtyValueToRichMessageSections(cty.TupleVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{"title": cty.StringVal("Deployed")}),
+	}))
+	assert.NotNil(err)
+
+	// not a list
+	_, err = CtyValueToRichMessageSections(cty.StringVal("Deployed"))
+	assert.NotNil(err)
+}
+
+func TestRichMessageValidate(t *testing.T) {
+	tests := []struct {
+		title         string
+		message       *RichMessage
+		containsError string
+	}{
+		{"invalid severity", &RichMessage{Severity: "critical"}, "invalid severity critical"},
+		{"invalid color", &RichMessage{Color: "red"}, "invalid color red"},
+		{"image without url", &RichMessage{Sections: []RichMessageSection{{Image: &RichMessageImage{}}}}, "an image must have a url"},
+		{"link without url", &RichMessage{Sections: []RichMessageSection{{Links: []RichMessageLink{{Text: "Run"}}}}}, "the link Run must have a url"},
+		{"javascript link", &RichMessage{Sections: []RichMessageSection{{Links: []RichMessageLink{{Text: "Run", Url: "javascript:alert(1)"}}}}}, "invalid url javascript:alert(1): the scheme must be one of http, https, mailto"},
+		{"javascript image", &RichMessage{Sections: []RichMessageSection{{Image: &RichMessageImage{Url: "JavaScript:alert(1)"}}}}, "invalid url JavaScript:alert(1)"},
+		{"javascript link in text", &RichMessage{Sections: []RichMessageSection{{Text: "see [x](javascript:alert(1))"}}}, "invalid url javascript:alert(1"},
+		{"relative link in text", &RichMessage{Sections: []RichMessageSection{{Text: "see [x](/runs/42)"}}}, "invalid url /runs/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			for _, integrationType := range []string{schema.IntegrationTypeSlack, schema.IntegrationTypeMsTeams, schema.IntegrationTypeEmail, schema.IntegrationTypeHttp} {
+				renderer, err := RichMessageRendererForIntegrationType(integrationType)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = renderer.Render(tt.message)
+				assert.ErrorContains(t, err, tt.containsError, integrationType)
+			}
+		})
+	}
+}
+
+func TestSlackLinkEscape(t *testing.T) {
+	assert := assert.New(t)
+
+	// a | or > in the url would end the url of the Slack link
+	assert.Equal("see <https://example.com/a%7Cb%3Ec|x>", slackMarkdown("see [x](https://example.com/a|b>c)"))
+	assert.Equal("<mailto:ops@example.com|mail ops>", slackMarkdown("[mail ops](mailto:ops@example.com)"))
+}
+
+func TestRichMessageColor(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("#F2994A", (&RichMessage{Severity: RichMessageSeverityWarning}).ColorHex())
+	assert.Equal("#123456", (&RichMessage{Severity: RichMessageSeverityWarning, Color: "#123456"}).ColorHex())
+	assert.Equal("", (&RichMessage{}).ColorHex())
+
+	// a message without a colour is sent as top level blocks, not in a coloured attachment
+	got, err := SlackMessageRenderer{}.Render(&RichMessage{Title: "Hello"})
+	assert.NoError(err)
+	assert.JSONEq(`{"text":"Hello","blocks":[{"type":"header","text":{"type":"plain_text","text":"Hello"}}]}`, string(got))
+
+	_, err = RichMessageRendererForIntegrationType("pagerduty")
+	assert.ErrorContains(err, "no message renderer for integration type pagerduty")
+}
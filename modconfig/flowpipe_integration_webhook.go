@@ -0,0 +1,482 @@
+package modconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cty_helpers"
+	"github.com/turbot/pipe-fittings/funcs"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/httpclient"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	WebhookTemplateFormatGo  = "go"
+	WebhookTemplateFormatHcl = "hcl"
+)
+
+var ValidWebhookTemplateFormats = []string{WebhookTemplateFormatGo, WebhookTemplateFormatHcl}
+
+// WebhookIntegration sends notifications to any HTTP endpoint, e.g. Discord, Mattermost, Google Chat, PagerDuty
+// Events v2 or an in-house bot. The request body is rendered from the body template, which receives the same
+// notification model as the http integration: title, severity, color, text and sections. Without a body template the
+// model itself is sent as JSON.
+type WebhookIntegration struct {
+	HclResourceImpl          `json:"-"`
+	ResourceWithMetadataImpl `json:"-"`
+	IntegrationImpl          `json:"-"`
+
+	Type string `json:"type" cty:"type" hcl:"type,label"`
+
+	Url     *string           `json:"url,omitempty" cty:"url" hcl:"url,optional"`
+	Method  *string           `json:"method,omitempty" cty:"method" hcl:"method,optional"`
+	Headers map[string]string `json:"headers,omitempty" cty:"headers" hcl:"headers,optional"`
+
+	// AuthConnection is the name of the connection used to authenticate the request, e.g. connection.pagerduty.default.
+	// The connection is resolved when the notification is sent.
+	AuthConnection *string `json:"auth_connection,omitempty" cty:"auth_connection" hcl:"auth_connection,optional"`
+
+	// BodyTemplate is a Go template, or an HCL template when the template format is hcl. HCL templates must escape
+	// their interpolations ($${message.title}) or be loaded with the file function, the template is rendered when the
+	// notification is sent.
+	BodyTemplate   *string `json:"body_template,omitempty" cty:"body_template" hcl:"body_template,optional"`
+	TemplateFormat *string `json:"template_format,omitempty" cty:"template_format" hcl:"template_format,optional"`
+
+	// SuccessCodes are the response status codes treated as a successful delivery, any 2xx status code if not set
+	SuccessCodes []int `json:"success_codes,omitempty" cty:"success_codes" hcl:"success_codes,optional"`
+
+	// http transport settings, unset values fall back to the workspace profile settings
+	ProxyUrl       *string  `json:"proxy_url,omitempty" cty:"proxy_url" hcl:"proxy_url,optional"`
+	NoProxy        []string `json:"no_proxy,omitempty" cty:"no_proxy" hcl:"no_proxy,optional"`
+	ConnectTimeout *string  `json:"connect_timeout,omitempty" cty:"connect_timeout" hcl:"connect_timeout,optional"`
+	Timeout        *string  `json:"timeout,omitempty" cty:"timeout" hcl:"timeout,optional"`
+	MaxRedirects   *int     `json:"max_redirects,omitempty" cty:"max_redirects" hcl:"max_redirects,optional"`
+	Http2          *bool    `json:"http2,omitempty" cty:"http2" hcl:"http2,optional"`
+}
+
+func (i *WebhookIntegration) GetIntegrationType() string {
+	return i.Type
+}
+
+func (i *WebhookIntegration) Equals(other Integration) bool {
+	if i == nil && helpers.IsNil(other) {
+		return true
+	}
+
+	if i == nil && !helpers.IsNil(other) || i != nil && helpers.IsNil(other) {
+		return false
+	}
+
+	otherWebhook, ok := other.(*WebhookIntegration)
+	if !ok {
+		return false
+	}
+
+	return i.FileName == otherWebhook.FileName &&
+		i.StartLineNumber == otherWebhook.StartLineNumber &&
+		i.EndLineNumber == otherWebhook.EndLineNumber &&
+		utils.PtrEqual(i.Url, otherWebhook.Url) &&
+		utils.PtrEqual(i.Method, otherWebhook.Method) &&
+		reflect.DeepEqual(i.Headers, otherWebhook.Headers) &&
+		utils.PtrEqual(i.AuthConnection, otherWebhook.AuthConnection) &&
+		utils.PtrEqual(i.BodyTemplate, otherWebhook.BodyTemplate) &&
+		utils.PtrEqual(i.TemplateFormat, otherWebhook.TemplateFormat) &&
+		slices.Equal(i.SuccessCodes, otherWebhook.SuccessCodes) &&
+		utils.PtrEqual(i.ProxyUrl, otherWebhook.ProxyUrl) &&
+		slices.Equal(i.NoProxy, otherWebhook.NoProxy) &&
+		utils.PtrEqual(i.ConnectTimeout, otherWebhook.ConnectTimeout) &&
+		utils.PtrEqual(i.Timeout, otherWebhook.Timeout) &&
+		utils.PtrEqual(i.MaxRedirects, otherWebhook.MaxRedirects) &&
+		utils.BoolPtrEqual(i.Http2, otherWebhook.Http2)
+}
+
+func (i *WebhookIntegration) CtyValue() (cty.Value, error) {
+	iCty, err := cty_helpers.GetCtyValue(i)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	valueMap := iCty.AsValueMap()
+	valueMap["full_name"] = cty.StringVal(i.FullName)
+	valueMap["short_name"] = cty.StringVal(i.ShortName)
+	valueMap["unqualified_name"] = cty.StringVal(i.UnqualifiedName)
+
+	if i.Title != nil {
+		valueMap["title"] = cty.StringVal(*i.Title)
+	}
+
+	if i.Description != nil {
+		valueMap["description"] = cty.StringVal(*i.Description)
+	}
+
+	return cty.ObjectVal(valueMap), nil
+}
+
+func (i *WebhookIntegration) MapInterface() (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+	res["type"] = i.Type
+
+	if i.Url != nil {
+		res[schema.AttributeTypeUrl] = *i.Url
+	}
+	if i.Method != nil {
+		res[schema.AttributeTypeMethod] = *i.Method
+	}
+	if i.Headers != nil {
+		res[schema.AttributeTypeHeaders] = i.Headers
+	}
+	if i.AuthConnection != nil {
+		res[schema.AttributeTypeAuthConnection] = *i.AuthConnection
+	}
+	if i.BodyTemplate != nil {
+		res[schema.AttributeTypeBodyTemplate] = *i.BodyTemplate
+	}
+	if i.TemplateFormat != nil {
+		res[schema.AttributeTypeTemplateFormat] = *i.TemplateFormat
+	}
+	if i.SuccessCodes != nil {
+		res[schema.AttributeTypeSuccessCodes] = i.SuccessCodes
+	}
+	setIntegrationHttpTransportValues(res, i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
+
+	res["full_name"] = i.FullName
+	res["short_name"] = i.ShortName
+	res["unqualified_name"] = i.UnqualifiedName
+
+	if i.Title != nil {
+		res["title"] = *i.Title
+	}
+	if i.Description != nil {
+		res["description"] = *i.Description
+	}
+
+	return res, nil
+}
+
+func (i *WebhookIntegration) SetAttributes(hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case schema.AttributeTypeUrl, schema.AttributeTypeMethod, schema.AttributeTypeAuthConnection, schema.AttributeTypeBodyTemplate, schema.AttributeTypeTemplateFormat:
+			val, moreDiags := hclhelpers.AttributeToString(attr, evalContext, false)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			switch name {
+			case schema.AttributeTypeUrl:
+				i.Url = val
+			case schema.AttributeTypeMethod:
+				i.Method = val
+			case schema.AttributeTypeAuthConnection:
+				i.AuthConnection = val
+			case schema.AttributeTypeBodyTemplate:
+				i.BodyTemplate = val
+			case schema.AttributeTypeTemplateFormat:
+				i.TemplateFormat = val
+			}
+		case schema.AttributeTypeHeaders:
+			val, moreDiags := hclhelpers.AttributeToMap(attr, evalContext, false)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			i.Headers = make(map[string]string, len(val))
+			for k, v := range val {
+				i.Headers[k] = fmt.Sprintf("%v", v)
+			}
+		case schema.AttributeTypeSuccessCodes:
+			val, moreDiags := attr.Expr.Value(evalContext)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			codes, err := hclhelpers.CtyToGoNumericSlice(val, val.Type())
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Attribute " + schema.AttributeTypeSuccessCodes + " must be a list of status codes",
+					Detail:   err.Error(),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			i.SuccessCodes = nil
+			for _, code := range codes {
+				i.SuccessCodes = append(i.SuccessCodes, int(code))
+			}
+		case schema.AttributeTypeProxyUrl, schema.AttributeTypeNoProxy, schema.AttributeTypeConnectTimeout, schema.AttributeTypeTimeout, schema.AttributeTypeMaxRedirects, schema.AttributeTypeHttp2:
+			diags = append(diags, setIntegrationHttpTransportAttribute(attr, evalContext, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)...)
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute for webhook Integration: " + attr.Name,
+				Subject:  &attr.Range,
+			})
+		}
+	}
+
+	return diags
+}
+
+func (i *WebhookIntegration) Validate() hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	var webhookUrl string
+	if i.Url != nil {
+		webhookUrl = *i.Url
+	}
+	if webhookUrl == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Attribute " + schema.AttributeTypeUrl + " must be defined: " + i.Name(),
+			Subject:  &i.DeclRange,
+		})
+	} else if u, err := url.Parse(webhookUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Attribute " + schema.AttributeTypeUrl + " must be an http or https url: " + i.Name(),
+			Subject:  &i.DeclRange,
+		})
+	}
+
+	if i.Method != nil && !slices.Contains(ValidHttpMethods, strings.ToLower(*i.Method)) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid method " + *i.Method + ": " + i.Name(),
+			Detail:   "The method must be one of " + strings.Join(ValidHttpMethods, ", "),
+			Subject:  &i.DeclRange,
+		})
+	}
+
+	if i.AuthConnection != nil {
+		if _, _, err := i.AuthConnectionName(); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.AttributeTypeAuthConnection + ": " + i.Name(),
+				Detail:   err.Error(),
+				Subject:  &i.DeclRange,
+			})
+		}
+	}
+
+	if i.TemplateFormat != nil && !slices.Contains(ValidWebhookTemplateFormats, *i.TemplateFormat) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid template format " + *i.TemplateFormat + ": " + i.Name(),
+			Detail:   "The template format must be one of " + strings.Join(ValidWebhookTemplateFormats, ", "),
+			Subject:  &i.DeclRange,
+		})
+	} else if i.BodyTemplate != nil {
+		if err := i.parseBodyTemplate(); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.AttributeTypeBodyTemplate + ": " + i.Name(),
+				Detail:   err.Error(),
+				Subject:  &i.DeclRange,
+			})
+		}
+	}
+
+	for _, code := range i.SuccessCodes {
+		if code < 100 || code > 599 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid success status code %d: %s", code, i.Name()),
+				Subject:  &i.DeclRange,
+			})
+		}
+	}
+
+	if _, err := i.HttpTransportConfig(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid http settings: " + i.Name(),
+			Detail:   err.Error(),
+			Subject:  &i.DeclRange,
+		})
+	}
+
+	return diags
+}
+
+// HttpTransportConfig returns the proxy, timeout and redirect settings of the integration, to be merged with the
+// workspace profile settings
+func (i *WebhookIntegration) HttpTransportConfig() (*httpclient.Config, error) {
+	return integrationHttpTransportConfig(i.ProxyUrl, i.NoProxy, i.ConnectTimeout, i.Timeout, i.MaxRedirects, i.Http2)
+}
+
+// HttpMethod returns the upper case method of the request, POST if not set
+func (i *WebhookIntegration) HttpMethod() string {
+	if i.Method == nil {
+		return http.MethodPost
+	}
+	return strings.ToUpper(*i.Method)
+}
+
+// IsSuccessStatusCode returns true if the response status code is a successful delivery
+func (i *WebhookIntegration) IsSuccessStatusCode(statusCode int) bool {
+	if len(i.SuccessCodes) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	return slices.Contains(i.SuccessCodes, statusCode)
+}
+
+// AuthConnectionName returns the type and the name of the auth connection, which is either written as
+// connection.<type>.<name> or <type>.<name>
+func (i *WebhookIntegration) AuthConnectionName() (string, string, error) {
+	if i.AuthConnection == nil {
+		return "", "", nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(*i.AuthConnection, schema.BlockTypeConnection+"."), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("the connection must be in the format connection.<type>.<name>: %s", *i.AuthConnection)
+	}
+	return parts[0], parts[1], nil
+}
+
+func (i *WebhookIntegration) templateFormat() string {
+	if i.TemplateFormat == nil {
+		return WebhookTemplateFormatGo
+	}
+	return *i.TemplateFormat
+}
+
+func (i *WebhookIntegration) parseBodyTemplate() error {
+	if i.templateFormat() == WebhookTemplateFormatHcl {
+		_, diags := hclsyntax.ParseTemplate([]byte(*i.BodyTemplate), i.Name(), hcl.InitialPos)
+		if diags.HasErrors() {
+			return diags
+		}
+		return nil
+	}
+
+	_, err := template.New(i.Name()).Funcs(webhookTemplateFuncs).Parse(*i.BodyTemplate)
+	return err
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	// json quotes a value, e.g. "text": {{ json .text }}
+	"json": func(v interface{}) (string, error) {
+		b, err := marshalPayload(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(b), "\n"), nil
+	},
+}
+
+// RenderBody renders the request body of the message. Go templates receive the notification model as their data
+// (e.g. {{ .title }}), HCL templates as the message variable (e.g. ${message.title}).
+func (i *WebhookIntegration) RenderBody(m *RichMessage) ([]byte, error) {
+	payload, err := JsonMessageRenderer{}.Render(m)
+	if err != nil {
+		return nil, err
+	}
+	if i.BodyTemplate == nil {
+		return payload, nil
+	}
+
+	if i.templateFormat() == WebhookTemplateFormatHcl {
+		expr, diags := hclsyntax.ParseTemplate([]byte(*i.BodyTemplate), i.Name(), hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		ty, err := ctyjson.ImpliedType(payload)
+		if err != nil {
+			return nil, err
+		}
+		message, err := ctyjson.Unmarshal(payload, ty)
+		if err != nil {
+			return nil, err
+		}
+
+		evalCtx := &hcl.EvalContext{
+			Functions: funcs.ContextFunctions(""),
+			Variables: map[string]cty.Value{"message": message},
+		}
+		val, diags := expr.Value(evalCtx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		body, err := hclhelpers.CtyToString(val)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(body), nil
+	}
+
+	tmpl, err := template.New(i.Name()).Funcs(webhookTemplateFuncs).Parse(*i.BodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func WebhookIntegrationFromCtyValue(val cty.Value) (*WebhookIntegration, error) {
+	hclResourceImpl := hclResourceImplFromVal(val)
+	i := &WebhookIntegration{
+		HclResourceImpl: hclResourceImpl,
+	}
+	i.Type = val.GetAttr("type").AsString()
+
+	valMap := val.AsValueMap()
+
+	for name, target := range map[string]**string{
+		schema.AttributeTypeUrl:            &i.Url,
+		schema.AttributeTypeMethod:         &i.Method,
+		schema.AttributeTypeAuthConnection: &i.AuthConnection,
+		schema.AttributeTypeBodyTemplate:   &i.BodyTemplate,
+		schema.AttributeTypeTemplateFormat: &i.TemplateFormat,
+	} {
+		if v, ok := valMap[name]; ok && !v.IsNull() {
+			*target = utils.ToPointer(v.AsString())
+		}
+	}
+
+	if v, ok := valMap[schema.AttributeTypeHeaders]; ok && !v.IsNull() {
+		headers, err := hclhelpers.CtyToGoMapString(v)
+		if err != nil {
+			return nil, err
+		}
+		i.Headers = headers
+	}
+
+	if v, ok := valMap[schema.AttributeTypeSuccessCodes]; ok && !v.IsNull() {
+		for _, item := range v.AsValueSlice() {
+			n, _ := item.AsBigFloat().Int64()
+			i.SuccessCodes = append(i.SuccessCodes, int(n))
+		}
+	}
+
+	integrationHttpTransportFromValueMap(valMap, &i.ProxyUrl, &i.NoProxy, &i.ConnectTimeout, &i.Timeout, &i.MaxRedirects, &i.Http2)
+
+	return i, nil
+}
@@ -0,0 +1,61 @@
+package modconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+func TestWebhookIntegrationRenderBody(t *testing.T) {
+	assert := assert.New(t)
+
+	message := &RichMessage{Title: `Disk "data" full`, Severity: RichMessageSeverityWarning}
+
+	// without a template the notification model is sent as JSON
+	i := &WebhookIntegration{Type: schema.IntegrationTypeWebhook}
+	body, err := i.RenderBody(message)
+	assert.NoError(err)
+	assert.JSONEq(`{"title":"Disk \"data\" full","severity":"warning","color":"#F2994A","text":"Disk \"data\" full"}`, string(body))
+
+	i.BodyTemplate = utils.ToPointer(`{"content": {{ json .title }}, "color": "{{ .color }}"}`)
+	body, err = i.RenderBody(message)
+	assert.NoError(err)
+	assert.JSONEq(`{"content":"Disk \"data\" full","color":"#F2994A"}`, string(body))
+
+	i.TemplateFormat = utils.ToPointer(WebhookTemplateFormatHcl)
+	i.BodyTemplate = utils.ToPointer(`{"summary": ${jsonencode(message.title)}, "severity": "${upper(message.severity)}"}`)
+	body, err = i.RenderBody(message)
+	assert.NoError(err)
+	assert.JSONEq(`{"summary":"Disk \"data\" full","severity":"WARNING"}`, string(body))
+}
+
+func TestWebhookIntegrationValidate(t *testing.T) {
+	tests := []struct {
+		title       string
+		integration *WebhookIntegration
+		summary     string
+	}{
+		{"missing url", &WebhookIntegration{}, "Attribute url must be defined"},
+		{"invalid url", &WebhookIntegration{Url: utils.ToPointer("ftp://example.com")}, "Attribute url must be an http or https url"},
+		{"invalid method", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), Method: utils.ToPointer("fetch")}, "Invalid method fetch"},
+		{"invalid auth connection", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), AuthConnection: utils.ToPointer("pagerduty")}, "Invalid auth_connection"},
+		{"invalid template format", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), TemplateFormat: utils.ToPointer("jinja")}, "Invalid template format jinja"},
+		{"invalid go template", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), BodyTemplate: utils.ToPointer("{{ .title ")}, "Invalid body_template"},
+		{"invalid hcl template", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), BodyTemplate: utils.ToPointer("${message.title"), TemplateFormat: utils.ToPointer(WebhookTemplateFormatHcl)}, "Invalid body_template"},
+		{"invalid success code", &WebhookIntegration{Url: utils.ToPointer("https://example.com"), SuccessCodes: []int{200, 42}}, "Invalid success status code 42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			diags := tt.integration.Validate()
+			if !assert.Len(t, diags, 1) {
+				return
+			}
+			assert.Contains(t, diags[0].Summary, tt.summary)
+		})
+	}
+
+	assert.Len(t, (&WebhookIntegration{Url: utils.ToPointer("https://example.com"), AuthConnection: utils.ToPointer("pagerduty.default")}).Validate(), 0)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
@@ -30,6 +31,15 @@ type LoopContainerStep struct {
 	ReadOnly          *bool              `json:"read_only,omitempty" hcl:"read_only,optional" cty:"read_only"`
 	User              *string            `json:"user,omitempty" hcl:"user,optional" cty:"user"`
 	Workdir           *string            `json:"workdir,omitempty" hcl:"workdir,optional" cty:"workdir"`
+	NetworkMode       *string            `json:"network_mode,omitempty" hcl:"network_mode,optional" cty:"network_mode"`
+	Stdin             *string            `json:"stdin,omitempty" hcl:"stdin,optional" cty:"stdin"`
+	PidsLimit         *int64             `json:"pids_limit,omitempty" hcl:"pids_limit,optional" cty:"pids_limit"`
+	CapDrop           *[]string          `json:"cap_drop,omitempty" hcl:"cap_drop,optional" cty:"cap_drop"`
+	OutputFormat      *string            `json:"output_format,omitempty" hcl:"output_format,optional" cty:"output_format"`
+
+	// Mounts replaces the mount blocks of the step, the loop block only has attributes so the mounts are a list of
+	// objects with source, target and read_only
+	Mounts *[]map[string]interface{} `json:"mounts,omitempty" hcl:"mounts,optional" cty:"mounts"`
 }
 
 func (l *LoopContainerStep) Equals(other LoopDefn) bool {
@@ -79,7 +89,23 @@ func (l *LoopContainerStep) Equals(other LoopDefn) bool {
 		}
 	}
 
+	if l.CapDrop == nil && otherLoopContainerStep.CapDrop != nil || l.CapDrop != nil && otherLoopContainerStep.CapDrop == nil {
+		return false
+	} else if l.CapDrop != nil {
+		if slices.Compare(*l.CapDrop, *otherLoopContainerStep.CapDrop) != 0 {
+			return false
+		}
+	}
+
+	if !reflect.DeepEqual(l.Mounts, otherLoopContainerStep.Mounts) {
+		return false
+	}
+
 	return utils.BoolPtrEqual(l.Until, otherLoopContainerStep.Until) &&
+		utils.PtrEqual(l.NetworkMode, otherLoopContainerStep.NetworkMode) &&
+		utils.PtrEqual(l.Stdin, otherLoopContainerStep.Stdin) &&
+		utils.PtrEqual(l.PidsLimit, otherLoopContainerStep.PidsLimit) &&
+		utils.PtrEqual(l.OutputFormat, otherLoopContainerStep.OutputFormat) &&
 		utils.PtrEqual(l.Image, otherLoopContainerStep.Image) &&
 		utils.PtrEqual(l.Source, otherLoopContainerStep.Source) &&
 		utils.PtrEqual(l.CpuShares, otherLoopContainerStep.CpuShares) &&
@@ -158,6 +184,54 @@ func (l *LoopContainerStep) UpdateInput(input Input, evalContext *hcl.EvalContex
 		return nil, error_helpers.BetterHclDiagsToError("container", diags)
 	}
 
+	result, diags = simpleTypeInputFromAttribute(l.GetUnresolvedAttributes(), result, evalContext, schema.AttributeTypeNetworkMode, l.NetworkMode)
+	if len(diags) > 0 {
+		return nil, error_helpers.BetterHclDiagsToError("container", diags)
+	}
+
+	result, diags = simpleTypeInputFromAttribute(l.GetUnresolvedAttributes(), result, evalContext, schema.AttributeTypeStdin, l.Stdin)
+	if len(diags) > 0 {
+		return nil, error_helpers.BetterHclDiagsToError("container", diags)
+	}
+
+	result, diags = simpleTypeInputFromAttribute(l.GetUnresolvedAttributes(), result, evalContext, schema.AttributeTypePidsLimit, l.PidsLimit)
+	if len(diags) > 0 {
+		return nil, error_helpers.BetterHclDiagsToError("container", diags)
+	}
+
+	result, diags = stringSliceInputFromAttribute(l.GetUnresolvedAttributes(), result, evalContext, schema.AttributeTypeCapDrop, l.CapDrop)
+	if len(diags) > 0 {
+		return nil, error_helpers.BetterHclDiagsToError("container", diags)
+	}
+
+	result, diags = simpleTypeInputFromAttribute(l.GetUnresolvedAttributes(), result, evalContext, schema.AttributeTypeOutputFormat, l.OutputFormat)
+	if len(diags) > 0 {
+		return nil, error_helpers.BetterHclDiagsToError("container", diags)
+	}
+
+	if l.UnresolvedAttributes[schema.AttributeTypeMounts] != nil {
+		val, diags := l.UnresolvedAttributes[schema.AttributeTypeMounts].Value(evalContext)
+		if len(diags) > 0 {
+			return nil, error_helpers.BetterHclDiagsToError("container", diags)
+		}
+
+		goVal, err := hclhelpers.CtyToGo(val)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage("container: unable to parse mounts: " + err.Error())
+		}
+		mounts, err := containerMountsFromGoValue(goVal)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage("container: " + err.Error())
+		}
+		result[schema.AttributeTypeMounts] = mounts
+	} else if l.Mounts != nil {
+		result[schema.AttributeTypeMounts] = *l.Mounts
+	}
+
+	if err := validateContainerInputs(result); err != nil {
+		return nil, perr.BadRequestWithMessage("container: " + err.Error())
+	}
+
 	return result, nil
 }
 
@@ -214,6 +288,52 @@ func (l *LoopContainerStep) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				diags = append(diags, stepDiags...)
 			}
 
+		case schema.AttributeTypeNetworkMode, schema.AttributeTypeStdin, schema.AttributeTypeOutputFormat:
+			fieldName := strcase.ToCamel(name)
+			stepDiags := setStringAttributeWithResultReference(attr, evalContext, l, fieldName, true, true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypePidsLimit:
+			stepDiags := setInt64AttributeWithResultReference(attr, evalContext, l, "PidsLimit", true, true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypeCapDrop:
+			stepDiags := setStringSliceAttributeWithResultReference(attr, evalContext, l, "CapDrop", true, true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypeMounts:
+			val, stepDiags := dependsOnFromExpressionsWithResultControl(attr, evalContext, l, true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+			if val == cty.NilVal {
+				continue
+			}
+
+			goVal, err := hclhelpers.CtyToGo(val)
+			var mounts []map[string]interface{}
+			if err == nil {
+				mounts, err = containerMountsFromGoValue(goVal)
+			}
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid mounts",
+					Detail:   "Invalid mounts in the step loop block: " + err.Error(),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			l.Mounts = &mounts
+
 		case schema.AttributeTypeUntil:
 			// already handled in SetAttributes
 		default:
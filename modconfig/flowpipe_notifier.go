@@ -31,6 +31,13 @@ type NotifierImpl struct {
 
 	Notifies []Notify `json:"notifies" cty:"notifies" hcl:"notifies"`
 
+	// DedupeWindow suppresses the messages with the same dedupe key sent within the window, e.g. 10m
+	DedupeWindow *string `json:"dedupe_window,omitempty" cty:"dedupe_window" hcl:"dedupe_window,optional"`
+	// DedupeKey is the source of the HCL expression returning the dedupe key of a message, it is evaluated when the
+	// message is sent
+	DedupeKey *string            `json:"dedupe_key,omitempty" cty:"dedupe_key" hcl:"-"`
+	RateLimit *NotifierRateLimit `json:"rate_limit,omitempty" cty:"rate_limit" hcl:"rate_limit,block"`
+
 	// required to allow partial decoding
 	Remain hcl.Body `hcl:",remain" json:"-"`
 
@@ -59,6 +66,12 @@ func (n *NotifierImpl) Equals(other Notifier) bool {
 		}
 	}
 
+	if !utils.PtrEqual(n.DedupeWindow, other.GetNotifierImpl().DedupeWindow) ||
+		!utils.PtrEqual(n.DedupeKey, other.GetNotifierImpl().DedupeKey) ||
+		!n.RateLimit.Equals(other.GetNotifierImpl().RateLimit) {
+		return false
+	}
+
 	return n.FileName == other.GetNotifierImpl().FileName &&
 		n.StartLineNumber == other.GetNotifierImpl().StartLineNumber &&
 		n.EndLineNumber == other.GetNotifierImpl().EndLineNumber
@@ -125,6 +138,15 @@ func (c *NotifierImpl) CtyValue() (cty.Value, error) {
 		notifierMap["description"] = *c.Description
 	}
 	notifierMap["resource_type"] = "notifier"
+	if c.DedupeWindow != nil {
+		notifierMap[schema.AttributeTypeDedupeWindow] = *c.DedupeWindow
+	}
+	if c.DedupeKey != nil {
+		notifierMap[schema.AttributeTypeDedupeKey] = *c.DedupeKey
+	}
+	if c.RateLimit != nil {
+		notifierMap[schema.BlockTypeRateLimit] = c.RateLimit.MapInterface()
+	}
 
 	notifierCtyVal, err := hclhelpers.ConvertInterfaceToCtyValue(notifierMap)
 	return notifierCtyVal, err
@@ -140,6 +162,8 @@ func (c *NotifierImpl) Validate() hcl.Diagnostics {
 			Summary:  schema.BlockTypeNotifier + " must have at least one " + schema.BlockTypeNotify + " block to send the request to: " + c.Name(),
 		})
 	}
+
+	diags = append(diags, c.ValidateDedupe()...)
 	return diags
 }
 
@@ -156,6 +180,9 @@ type Notify struct {
 	Subject     *string  `json:"subject,omitempty" cty:"subject" hcl:"subject,optional"`
 	Title       *string  `json:"title,omitempty" cty:"title" hcl:"title,optional"`
 	To          []string `json:"to,omitempty" cty:"to" hcl:"to,optional"`
+
+	// Match is the routing condition of the notify, a notify without a match receives every notification
+	Match *NotifyMatch `json:"match,omitempty" cty:"match" hcl:"match,block"`
 }
 
 func (n *Notify) Equals(other *Notify) bool {
@@ -175,6 +202,7 @@ func (n *Notify) Equals(other *Notify) bool {
 		utils.PtrEqual(n.Description, other.Description) &&
 		utils.PtrEqual(n.Subject, other.Subject) &&
 		utils.PtrEqual(n.Title, other.Title) &&
+		n.Match.Equals(other.Match) &&
 		n.Integration.Equals(other.Integration)
 }
 
@@ -229,6 +257,12 @@ func (n *Notify) UnmarshalJSON(data []byte) error {
 			return err
 		}
 		n.Integration = &teamsIntegration
+	case schema.IntegrationTypeWebhook:
+		var webhookIntegration WebhookIntegration
+		if err := json.Unmarshal(temp.Integration, &webhookIntegration); err != nil {
+			return err
+		}
+		n.Integration = &webhookIntegration
 	default:
 		return perr.InternalWithMessage(fmt.Sprintf("unknown integration type: %s", typeIndicator.Type))
 	}
@@ -267,6 +301,10 @@ func (n *Notify) MapInterface() (map[string]interface{}, error) {
 		notifyMap["to"] = n.To
 	}
 
+	if n.Match != nil {
+		notifyMap[schema.BlockTypeMatch] = n.Match.MapInterface()
+	}
+
 	var err error
 	notifyMap["integration"], err = n.Integration.MapInterface()
 	if err != nil {
@@ -308,6 +346,10 @@ func (n *Notify) CtyValue() (cty.Value, error) {
 		notifyMap["to"] = n.To
 	}
 
+	if n.Match != nil {
+		notifyMap[schema.BlockTypeMatch] = n.Match.MapInterface()
+	}
+
 	notifyMap["integration"], err = n.Integration.MapInterface()
 	if err != nil {
 		return cty.NilVal, err
@@ -321,7 +363,8 @@ func (n *Notify) CtyValue() (cty.Value, error) {
 	return ctyVal, nil
 }
 
-func (c *Notify) Validate() hcl.Diagnostics {
+// Validate validates the notify block, subject is the range of the block reported in the diagnostics
+func (c *Notify) Validate(subject *hcl.Range) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 
 	if c.Integration != nil {
@@ -331,6 +374,7 @@ func (c *Notify) Validate() hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Attribute '" + schema.AttributeTypeCc + "' is not a valid attribute for " + integrationType + " type integration",
+				Subject:  subject,
 			})
 		}
 
@@ -338,6 +382,7 @@ func (c *Notify) Validate() hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Attribute '" + schema.AttributeTypeBcc + "' is not a valid attribute for " + integrationType + " type integration",
+				Subject:  subject,
 			})
 		}
 
@@ -345,6 +390,7 @@ func (c *Notify) Validate() hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Attribute '" + schema.AttributeTypeTo + "' is not a valid attribute for " + integrationType + " type integration",
+				Subject:  subject,
 			})
 		}
 
@@ -352,6 +398,7 @@ func (c *Notify) Validate() hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Attribute '" + schema.AttributeTypeSubject + "' is not a valid attribute for " + integrationType + " type integration",
+				Subject:  subject,
 			})
 		}
 
@@ -359,20 +406,28 @@ func (c *Notify) Validate() hcl.Diagnostics {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Attribute '" + schema.AttributeTypeChannel + "' is not a valid attribute for " + integrationType + " type integration",
+				Subject:  subject,
 			})
 		}
 	}
 
+	if c.Match != nil {
+		diags = append(diags, c.Match.Validate(subject)...)
+	}
+
 	return diags
 }
 
 func (n *Notify) SetAttributes(body hcl.Body, evalCtx *hcl.EvalContext) hcl.Diagnostics {
-	attribs, diags := body.JustAttributes()
+	// the match block is decoded with the notify struct
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: schema.AttributeTypeIntegration}},
+	})
 	if diags.HasErrors() {
 		return diags
 	}
 
-	attr := attribs[schema.AttributeTypeIntegration]
+	attr := content.Attributes[schema.AttributeTypeIntegration]
 	if attr == nil {
 		return hcl.Diagnostics{
 			{
@@ -0,0 +1,208 @@
+package modconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/pipe-fittings/dedupe"
+	"github.com/turbot/pipe-fittings/funcs"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// the variable holding the notification in the dedupe_key expression
+const dedupeKeyVariable = "message"
+
+// NotifierRateLimit is the number of messages a notifier sends per interval
+type NotifierRateLimit struct {
+	Messages int    `json:"messages" cty:"messages" hcl:"messages"`
+	Interval string `json:"interval" cty:"interval" hcl:"interval"`
+	// Digest sends the messages suppressed during the interval as a single digest once it is over
+	Digest *bool `json:"digest,omitempty" cty:"digest" hcl:"digest,optional"`
+}
+
+func (r *NotifierRateLimit) Equals(other *NotifierRateLimit) bool {
+	if r == nil && other == nil {
+		return true
+	}
+
+	if r == nil && other != nil || r != nil && other == nil {
+		return false
+	}
+
+	return r.Messages == other.Messages &&
+		r.Interval == other.Interval &&
+		utils.BoolPtrEqual(r.Digest, other.Digest)
+}
+
+func (r *NotifierRateLimit) MapInterface() map[string]interface{} {
+	res := map[string]interface{}{
+		schema.AttributeTypeMessages: r.Messages,
+		schema.AttributeTypeInterval: r.Interval,
+	}
+	if r.Digest != nil {
+		res[schema.AttributeTypeDigest] = *r.Digest
+	}
+	return res
+}
+
+func ctyValueToNotifierRateLimit(val cty.Value) *NotifierRateLimit {
+	if val.IsNull() {
+		return nil
+	}
+
+	r := &NotifierRateLimit{}
+	valMap := val.AsValueMap()
+	if v, ok := valMap[schema.AttributeTypeMessages]; ok && !v.IsNull() {
+		n, _ := v.AsBigFloat().Int64()
+		r.Messages = int(n)
+	}
+	if v, ok := valMap[schema.AttributeTypeInterval]; ok && !v.IsNull() {
+		r.Interval = v.AsString()
+	}
+	if v, ok := valMap[schema.AttributeTypeDigest]; ok && !v.IsNull() {
+		r.Digest = utils.ToPointer(v.True())
+	}
+	return r
+}
+
+// ValidateDedupe validates the dedupe and rate limit settings of the notifier
+func (c *NotifierImpl) ValidateDedupe() hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if c.DedupeWindow != nil {
+		if d, err := time.ParseDuration(*c.DedupeWindow); err != nil || d <= 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.AttributeTypeDedupeWindow + ": " + *c.DedupeWindow + " must be a positive duration, e.g. 10m",
+				Subject:  &c.DeclRange,
+			})
+		}
+	}
+
+	if c.DedupeKey != nil {
+		expr, moreDiags := c.dedupeKeyExpression()
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+		} else {
+			for _, traversal := range expr.Variables() {
+				if traversal.RootName() != dedupeKeyVariable {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid " + schema.AttributeTypeDedupeKey + ": the expression can only reference the " + dedupeKeyVariable + " variable, found " + hclhelpers.TraversalAsString(traversal),
+						Subject:  &c.DeclRange,
+					})
+				}
+			}
+		}
+		if c.DedupeWindow == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.AttributeTypeDedupeKey + ": " + schema.AttributeTypeDedupeWindow + " must be set",
+				Subject:  &c.DeclRange,
+			})
+		}
+	}
+
+	if r := c.RateLimit; r != nil {
+		if r.Messages < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid %s: messages must be at least 1, got %d", schema.BlockTypeRateLimit, r.Messages),
+				Subject:  &c.DeclRange,
+			})
+		}
+		if d, err := time.ParseDuration(r.Interval); err != nil || d <= 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.BlockTypeRateLimit + ": interval " + r.Interval + " must be a positive duration, e.g. 1m",
+				Subject:  &c.DeclRange,
+			})
+		}
+	}
+
+	return diags
+}
+
+func (c *NotifierImpl) dedupeKeyExpression() (hcl.Expression, hcl.Diagnostics) {
+	return hclsyntax.ParseExpression([]byte(*c.DedupeKey), c.Name()+" "+schema.AttributeTypeDedupeKey, hcl.InitialPos)
+}
+
+// DedupeConfig returns the dedupe and rate limit settings of the notifier, the config is empty if none are set
+func (c *NotifierImpl) DedupeConfig() (dedupe.Config, error) {
+	config := dedupe.Config{}
+
+	if c.DedupeWindow != nil {
+		d, err := time.ParseDuration(*c.DedupeWindow)
+		if err != nil {
+			return config, err
+		}
+		config.DedupeWindow = d
+	}
+
+	if c.RateLimit != nil {
+		d, err := time.ParseDuration(c.RateLimit.Interval)
+		if err != nil {
+			return config, err
+		}
+		config.RateLimit = c.RateLimit.Messages
+		config.RateInterval = d
+		config.Digest = c.RateLimit.Digest != nil && *c.RateLimit.Digest
+	}
+
+	return config, nil
+}
+
+// DedupeKeyFor returns the dedupe key of a message sent by a message or input step, from its resolved inputs. The
+// dedupe_key expression receives the message variable with the text, subject, severity, pipeline and tags of the
+// notification. Without a dedupe_key, messages with the same subject and text are duplicates.
+func (c *NotifierImpl) DedupeKeyFor(input map[string]interface{}, nc NotificationContext) (string, error) {
+	text, ok := input[schema.AttributeTypeText].(string)
+	if !ok {
+		text, _ = input[schema.AttributeTypePrompt].(string)
+	}
+	subject, _ := input[schema.AttributeTypeSubject].(string)
+
+	if c.DedupeKey == nil {
+		return subject + "\n" + text, nil
+	}
+
+	tags := cty.MapValEmpty(cty.String)
+	if len(nc.Tags) > 0 {
+		tagVals := map[string]cty.Value{}
+		for k, v := range nc.Tags {
+			tagVals[k] = cty.StringVal(v)
+		}
+		tags = cty.MapVal(tagVals)
+	}
+	message := cty.ObjectVal(map[string]cty.Value{
+		schema.AttributeTypeText:     cty.StringVal(text),
+		schema.AttributeTypeSubject:  cty.StringVal(subject),
+		schema.AttributeTypeSeverity: cty.StringVal(nc.Severity),
+		schema.AttributeTypePipeline: cty.StringVal(nc.PipelineName),
+		schema.AttributeTypeTags:     tags,
+	})
+
+	expr, diags := c.dedupeKeyExpression()
+	if diags.HasErrors() {
+		return "", diags
+	}
+	val, diags := expr.Value(&hcl.EvalContext{
+		Functions: funcs.ContextFunctions(""),
+		Variables: map[string]cty.Value{dedupeKeyVariable: message},
+	})
+	if diags.HasErrors() {
+		return "", diags
+	}
+
+	key, err := hclhelpers.CtyToString(val)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(key), nil
+}
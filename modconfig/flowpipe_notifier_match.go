@@ -0,0 +1,314 @@
+package modconfig
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var validBusinessDays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// NotificationContext describes a notification being sent to a notifier, it is matched against the match conditions
+// of the notifier's notify blocks
+type NotificationContext struct {
+	// Severity of the notification, info if not set
+	Severity string
+	// Tags of the pipeline sending the notification
+	Tags map[string]string
+	// PipelineName is the full name of the pipeline sending the notification, e.g. mymod.pipeline.deploy
+	PipelineName string
+	// Time the notification is sent, now if not set
+	Time time.Time
+}
+
+// NotifyMatch is the set of conditions a notification must meet to be sent to a notify target. All the conditions
+// that are set must match.
+type NotifyMatch struct {
+	// Severity is the list of severities of the notifications to send
+	Severity []string `json:"severity,omitempty" cty:"severity" hcl:"severity,optional"`
+	// Tags must all be set on the pipeline with the same value
+	Tags map[string]string `json:"tags,omitempty" cty:"tags" hcl:"tags,optional"`
+	// Pipeline is a glob pattern of the pipeline name, e.g. mymod.pipeline.deploy_*
+	Pipeline      *string                   `json:"pipeline,omitempty" cty:"pipeline" hcl:"pipeline,optional"`
+	BusinessHours *NotifyMatchBusinessHours `json:"business_hours,omitempty" cty:"business_hours" hcl:"business_hours,block"`
+}
+
+// NotifyMatchBusinessHours is a weekly time window, by default the notification matches inside the window, or outside
+// of it if Outside is set
+type NotifyMatchBusinessHours struct {
+	Days     []string `json:"days,omitempty" cty:"days" hcl:"days,optional"`
+	Start    string   `json:"start" cty:"start" hcl:"start"`
+	End      string   `json:"end" cty:"end" hcl:"end"`
+	Timezone *string  `json:"timezone,omitempty" cty:"timezone" hcl:"timezone,optional"`
+	Outside  *bool    `json:"outside,omitempty" cty:"outside" hcl:"outside,optional"`
+}
+
+func (m *NotifyMatch) Equals(other *NotifyMatch) bool {
+	if m == nil && other == nil {
+		return true
+	}
+
+	if m == nil && other != nil || m != nil && other == nil {
+		return false
+	}
+
+	return helpers.StringSliceEqualIgnoreOrder(m.Severity, other.Severity) &&
+		reflect.DeepEqual(m.Tags, other.Tags) &&
+		utils.PtrEqual(m.Pipeline, other.Pipeline) &&
+		m.BusinessHours.Equals(other.BusinessHours)
+}
+
+func (b *NotifyMatchBusinessHours) Equals(other *NotifyMatchBusinessHours) bool {
+	if b == nil && other == nil {
+		return true
+	}
+
+	if b == nil && other != nil || b != nil && other == nil {
+		return false
+	}
+
+	return helpers.StringSliceEqualIgnoreOrder(b.Days, other.Days) &&
+		b.Start == other.Start &&
+		b.End == other.End &&
+		utils.PtrEqual(b.Timezone, other.Timezone) &&
+		utils.BoolPtrEqual(b.Outside, other.Outside)
+}
+
+// Validate validates the match block, subject is the range of the notify block reported in the diagnostics
+func (m *NotifyMatch) Validate(subject *hcl.Range) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for _, severity := range m.Severity {
+		if !slices.Contains(ValidRichMessageSeverities, severity) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.BlockTypeMatch + ": invalid severity " + severity + ", must be one of " + strings.Join(ValidRichMessageSeverities, ", "),
+				Subject:  subject,
+			})
+		}
+	}
+
+	if m.Pipeline != nil {
+		if _, err := path.Match(*m.Pipeline, ""); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.BlockTypeMatch + ": invalid pipeline pattern " + *m.Pipeline,
+				Detail:   err.Error(),
+				Subject:  subject,
+			})
+		}
+	}
+
+	if m.BusinessHours != nil {
+		if err := m.BusinessHours.validate(); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + schema.BlockTypeBusinessHours + ": " + err.Error(),
+				Subject:  subject,
+			})
+		}
+	}
+
+	return diags
+}
+
+func (b *NotifyMatchBusinessHours) validate() error {
+	for _, day := range b.Days {
+		if !slices.Contains(validBusinessDays, day) {
+			return fmt.Errorf("invalid day %s, must be one of %s", day, strings.Join(validBusinessDays, ", "))
+		}
+	}
+
+	loc, err := b.location()
+	if err != nil {
+		return err
+	}
+	if _, err := newDailyWindow(b.Start, b.End, loc); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *NotifyMatchBusinessHours) location() (*time.Location, error) {
+	if b.Timezone == nil {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(*b.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %s", *b.Timezone)
+	}
+	return loc, nil
+}
+
+// Contains returns true if the time is inside the window. A window which ends before it starts spans midnight, the
+// day is the day the window starts.
+func (b *NotifyMatchBusinessHours) Contains(t time.Time) (bool, error) {
+	loc, err := b.location()
+	if err != nil {
+		return false, err
+	}
+	window, err := newDailyWindow(b.Start, b.End, loc)
+	if err != nil {
+		return false, err
+	}
+
+	inside, day := window.contains(t)
+	if inside && len(b.Days) > 0 {
+		inside = slices.Contains(b.Days, strings.ToLower(day.Weekday().String()[:3]))
+	}
+	return inside, nil
+}
+
+// Matches returns true if the notification meets all the conditions
+func (m *NotifyMatch) Matches(nc NotificationContext) (bool, error) {
+	if m == nil {
+		return true, nil
+	}
+
+	if len(m.Severity) > 0 {
+		severity := nc.Severity
+		if severity == "" {
+			severity = RichMessageSeverityInfo
+		}
+		if !slices.Contains(m.Severity, severity) {
+			return false, nil
+		}
+	}
+
+	for k, v := range m.Tags {
+		if tag, ok := nc.Tags[k]; !ok || tag != v {
+			return false, nil
+		}
+	}
+
+	if m.Pipeline != nil {
+		match, err := path.Match(*m.Pipeline, nc.PipelineName)
+		if err != nil || !match {
+			return false, err
+		}
+	}
+
+	if m.BusinessHours != nil {
+		t := nc.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		inside, err := m.BusinessHours.Contains(t)
+		if err != nil {
+			return false, err
+		}
+		outside := m.BusinessHours.Outside != nil && *m.BusinessHours.Outside
+		if inside == outside {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (m *NotifyMatch) MapInterface() map[string]interface{} {
+	res := map[string]interface{}{}
+
+	if m.Severity != nil {
+		res[schema.AttributeTypeSeverity] = m.Severity
+	}
+	if m.Tags != nil {
+		res[schema.AttributeTypeTags] = m.Tags
+	}
+	if m.Pipeline != nil {
+		res[schema.AttributeTypePipeline] = *m.Pipeline
+	}
+	if b := m.BusinessHours; b != nil {
+		businessHours := map[string]interface{}{
+			schema.AttributeTypeStart: b.Start,
+			schema.AttributeTypeEnd:   b.End,
+		}
+		if b.Days != nil {
+			businessHours[schema.AttributeTypeDays] = b.Days
+		}
+		if b.Timezone != nil {
+			businessHours[schema.AttributeTypeTimezone] = *b.Timezone
+		}
+		if b.Outside != nil {
+			businessHours[schema.AttributeTypeOutside] = *b.Outside
+		}
+		res[schema.BlockTypeBusinessHours] = businessHours
+	}
+
+	return res
+}
+
+func ctyValueToNotifyMatch(val cty.Value) (*NotifyMatch, error) {
+	if val.IsNull() {
+		return nil, nil
+	}
+
+	m := &NotifyMatch{}
+	valMap := val.AsValueMap()
+
+	if severity, ok := valMap[schema.AttributeTypeSeverity]; ok && !severity.IsNull() {
+		for _, s := range severity.AsValueSlice() {
+			m.Severity = append(m.Severity, s.AsString())
+		}
+	}
+
+	if tags, ok := valMap[schema.AttributeTypeTags]; ok && !tags.IsNull() {
+		m.Tags = map[string]string{}
+		for k, v := range tags.AsValueMap() {
+			m.Tags[k] = v.AsString()
+		}
+	}
+
+	if pipeline, ok := valMap[schema.AttributeTypePipeline]; ok && !pipeline.IsNull() {
+		m.Pipeline = utils.ToPointer(pipeline.AsString())
+	}
+
+	if businessHours, ok := valMap[schema.BlockTypeBusinessHours]; ok && !businessHours.IsNull() {
+		bhMap := businessHours.AsValueMap()
+		b := &NotifyMatchBusinessHours{}
+		if v, ok := bhMap[schema.AttributeTypeStart]; ok && !v.IsNull() {
+			b.Start = v.AsString()
+		}
+		if v, ok := bhMap[schema.AttributeTypeEnd]; ok && !v.IsNull() {
+			b.End = v.AsString()
+		}
+		if v, ok := bhMap[schema.AttributeTypeDays]; ok && !v.IsNull() {
+			for _, d := range v.AsValueSlice() {
+				b.Days = append(b.Days, d.AsString())
+			}
+		}
+		if v, ok := bhMap[schema.AttributeTypeTimezone]; ok && !v.IsNull() {
+			b.Timezone = utils.ToPointer(v.AsString())
+		}
+		if v, ok := bhMap[schema.AttributeTypeOutside]; ok && !v.IsNull() {
+			b.Outside = utils.ToPointer(v.True())
+		}
+		m.BusinessHours = b
+	}
+
+	return m, nil
+}
+
+// ResolveNotifies returns the notify targets matching the notification, in the order they are declared
+func (c *NotifierImpl) ResolveNotifies(nc NotificationContext) ([]Notify, error) {
+	var res []Notify
+	for _, notify := range c.Notifies {
+		match, err := notify.Match.Matches(nc)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			res = append(res, notify)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package modconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+func TestNotifyMatchBusinessHours(t *testing.T) {
+	// a night shift from Friday 22:00 to Saturday 06:00
+	nightShift := &NotifyMatchBusinessHours{Days: []string{"fri"}, Start: "22:00", End: "06:00", Timezone: utils.ToPointer("Asia/Tokyo")}
+
+	tests := []struct {
+		title  string
+		window *NotifyMatchBusinessHours
+		time   time.Time
+		inside bool
+	}{
+		{"office hours", &NotifyMatchBusinessHours{Start: "09:00", End: "17:00"}, time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), true},
+		{"end is exclusive", &NotifyMatchBusinessHours{Start: "09:00", End: "17:00"}, time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC), false},
+		{"weekend", &NotifyMatchBusinessHours{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}, time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC), false},
+		{"night shift before midnight", nightShift, time.Date(2024, 1, 12, 13, 30, 0, 0, time.UTC), true},
+		{"night shift after midnight", nightShift, time.Date(2024, 1, 12, 20, 0, 0, 0, time.UTC), true},
+		{"night shift the next evening", nightShift, time.Date(2024, 1, 13, 13, 30, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			inside, err := tt.window.Contains(tt.time)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.inside, inside)
+		})
+	}
+}
+
+func TestNotifyMatchMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &NotifyMatch{
+		Severity: []string{RichMessageSeverityError},
+		Tags:     map[string]string{"team": "infra"},
+		Pipeline: utils.ToPointer("deploy.pipeline.*"),
+	}
+
+	nc := NotificationContext{Severity: RichMessageSeverityError, Tags: map[string]string{"team": "infra", "env": "prod"}, PipelineName: "deploy.pipeline.api"}
+	match, err := m.Matches(nc)
+	assert.NoError(err)
+	assert.True(match)
+
+	nc.PipelineName = "report.pipeline.daily"
+	match, err = m.Matches(nc)
+	assert.NoError(err)
+	assert.False(match)
+
+	// no match block matches every notification
+	match, err = (*NotifyMatch)(nil).Matches(NotificationContext{})
+	assert.NoError(err)
+	assert.True(match)
+}
+
+func TestNotifyMatchValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	m := &NotifyMatch{
+		Severity:      []string{"urgent"},
+		Pipeline:      utils.ToPointer("deploy.pipeline.[a"),
+		BusinessHours: &NotifyMatchBusinessHours{Start: "9am", End: "17:00"},
+	}
+
+	// every diagnostic points at the notify block
+	subject := &hcl.Range{Filename: "notifiers.fpc", Start: hcl.Pos{Line: 3, Column: 3}, End: hcl.Pos{Line: 3, Column: 9}}
+	diags := m.Validate(subject)
+	assert.Equal(3, len(diags))
+	for _, diag := range diags {
+		assert.Equal(subject, diag.Subject, diag.Summary)
+	}
+}
@@ -0,0 +1,192 @@
+package modconfig
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const JsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JsonSchema is the subset of JSON Schema (draft 2020-12) needed to describe pipeline params and outputs.
+//
+// It is also the schema object used by the OpenAPI 3.1 document generated for HTTP triggers, so it must not
+// use any keyword that OpenAPI does not understand.
+type JsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Id                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	PrefixItems          []*JsonSchema          `json:"prefixItems,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Const                any                    `json:"const,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+}
+
+// CtyTypeToJsonSchema converts a cty type to the equivalent JSON Schema.
+//
+// Capsule types (connection and notifier params) are described as the object representation that Flowpipe
+// accepts for them, i.e. { name, type, resource_type }. The dynamic type ("any") is an empty schema which
+// accepts any value.
+func CtyTypeToJsonSchema(t cty.Type) *JsonSchema {
+	switch {
+	case t == cty.NilType || t == cty.DynamicPseudoType:
+		return &JsonSchema{}
+
+	case t == cty.String:
+		return &JsonSchema{Type: "string"}
+
+	case t == cty.Number:
+		return &JsonSchema{Type: "number"}
+
+	case t == cty.Bool:
+		return &JsonSchema{Type: "boolean"}
+
+	case t.IsListType():
+		return &JsonSchema{Type: "array", Items: CtyTypeToJsonSchema(t.ElementType())}
+
+	case t.IsSetType():
+		return &JsonSchema{Type: "array", Items: CtyTypeToJsonSchema(t.ElementType()), UniqueItems: true}
+
+	case t.IsTupleType():
+		elementTypes := t.TupleElementTypes()
+		count := len(elementTypes)
+		res := &JsonSchema{Type: "array", MinItems: &count, MaxItems: &count}
+		for _, et := range elementTypes {
+			res.PrefixItems = append(res.PrefixItems, CtyTypeToJsonSchema(et))
+		}
+		return res
+
+	case t.IsMapType():
+		return &JsonSchema{Type: "object", AdditionalProperties: CtyTypeToJsonSchema(t.ElementType())}
+
+	case t.IsObjectType():
+		res := &JsonSchema{Type: "object", Properties: map[string]*JsonSchema{}}
+		for name, at := range t.AttributeTypes() {
+			res.Properties[name] = CtyTypeToJsonSchema(at)
+			if !t.AttributeOptional(name) {
+				res.Required = append(res.Required, name)
+			}
+		}
+		// need stable order otherwise testing is difficult
+		sort.Strings(res.Required)
+		return res
+
+	case t.IsCapsuleType():
+		return customTypeJsonSchema(t)
+	}
+
+	return &JsonSchema{}
+}
+
+func customTypeJsonSchema(t cty.Type) *JsonSchema {
+	resourceType := schema.BlockTypeConnection
+	if t.EncapsulatedType() == reflect.TypeOf(&NotifierImpl{}) {
+		resourceType = schema.BlockTypeNotifier
+	}
+
+	res := &JsonSchema{
+		Type: "object",
+		Properties: map[string]*JsonSchema{
+			schema.AttributeTypeName: {Type: "string"},
+			"resource_type":          {Type: "string", Const: resourceType},
+		},
+		Required: []string{schema.AttributeTypeName, "resource_type"},
+	}
+
+	if resourceType == schema.BlockTypeConnection {
+		res.Properties[schema.AttributeTypeType] = &JsonSchema{Type: "string"}
+		res.Required = append(res.Required, schema.AttributeTypeType)
+	}
+
+	return res
+}
+
+// JsonSchema returns the JSON Schema for a single pipeline param, including its description, default and enum.
+func (p *PipelineParam) JsonSchema() *JsonSchema {
+	res := CtyTypeToJsonSchema(p.Type)
+	res.Description = p.Description
+
+	if !p.Default.IsNull() {
+		if def, err := hclhelpers.CtyToGo(p.Default); err == nil {
+			res.Default = def
+		}
+	}
+
+	if len(p.EnumGo) == 0 {
+		return res
+	}
+
+	// enum on a list param restricts the elements of the list, not the list itself
+	if res.Type == "array" && res.Items != nil {
+		res.Items.Enum = p.EnumGo
+	} else {
+		res.Enum = p.EnumGo
+	}
+
+	return res
+}
+
+// IsRequired returns true if a value must be supplied for the param, i.e. it is not optional and has no default
+func (p *PipelineParam) IsRequired() bool {
+	return p.Default.IsNull() && !p.Optional
+}
+
+// ParamsJsonSchema returns a JSON Schema describing the args accepted by the pipeline
+func (p *Pipeline) ParamsJsonSchema() *JsonSchema {
+	res := &JsonSchema{
+		Schema:               JsonSchemaDraft,
+		Id:                   p.FullName + ".params",
+		Type:                 "object",
+		Properties:           map[string]*JsonSchema{},
+		AdditionalProperties: false,
+	}
+	if p.Title != nil {
+		res.Title = *p.Title
+	}
+	if p.Description != nil {
+		res.Description = *p.Description
+	}
+
+	for i := range p.Params {
+		param := &p.Params[i]
+		res.Properties[param.Name] = param.JsonSchema()
+		if param.IsRequired() {
+			res.Required = append(res.Required, param.Name)
+		}
+	}
+	sort.Strings(res.Required)
+
+	return res
+}
+
+// OutputsJsonSchema returns a JSON Schema describing the outputs of the pipeline.
+//
+// Output values are expressions which are only resolved at runtime so their types are not known, every
+// output is therefore described by its description only.
+func (p *Pipeline) OutputsJsonSchema() *JsonSchema {
+	res := &JsonSchema{
+		Schema:     JsonSchemaDraft,
+		Id:         p.FullName + ".outputs",
+		Type:       "object",
+		Properties: map[string]*JsonSchema{},
+	}
+
+	for _, o := range p.OutputConfig {
+		res.Properties[o.Name] = &JsonSchema{Description: o.Description}
+	}
+
+	return res
+}
@@ -0,0 +1,23 @@
+package modconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCapsuleTypeJsonSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	notifierSchema := CtyTypeToJsonSchema(cty.Capsule("NotifierCtyType", reflect.TypeOf(&NotifierImpl{})))
+	assert.Equal(schema.BlockTypeNotifier, notifierSchema.Properties["resource_type"].Const)
+	assert.NotContains(notifierSchema.Properties, schema.AttributeTypeType)
+
+	// any other capsule type is a connection
+	connectionSchema := CtyTypeToJsonSchema(cty.Capsule("ConnectionCtyType", reflect.TypeOf(&HclResourceImpl{})))
+	assert.Equal(schema.BlockTypeConnection, connectionSchema.Properties["resource_type"].Const)
+	assert.Contains(connectionSchema.Properties, schema.AttributeTypeType)
+}
@@ -27,6 +27,30 @@ var IntegrationSlackBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeWebhookUrl,
 			Required: false,
 		},
+		{
+			Name:     schema.AttributeTypeProxyUrl,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeNoProxy,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeConnectTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeMaxRedirects,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeHttp2,
+			Required: false,
+		},
 	},
 }
 
@@ -105,6 +129,95 @@ var IntegrationTeamsBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeWebhookUrl,
 			Required: true,
 		},
+		{
+			Name:     schema.AttributeTypeProxyUrl,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeNoProxy,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeConnectTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeMaxRedirects,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeHttp2,
+			Required: false,
+		},
+	},
+}
+
+var IntegrationWebhookBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeDescription,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTitle,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeUrl,
+			Required: true,
+		},
+		{
+			Name:     schema.AttributeTypeMethod,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeHeaders,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeAuthConnection,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeBodyTemplate,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTemplateFormat,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeSuccessCodes,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeProxyUrl,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeNoProxy,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeConnectTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTimeout,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeMaxRedirects,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeHttp2,
+			Required: false,
+		},
 	},
 }
 
@@ -130,6 +243,24 @@ var TriggerScheduleBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeSchedule,
 			Required: true,
 		},
+		{
+			Name: schema.AttributeTypeTimezone,
+		},
+		{
+			Name: schema.AttributeTypeStartTime,
+		},
+		{
+			Name: schema.AttributeTypeEndTime,
+		},
+		{
+			Name: schema.AttributeTypeJitter,
+		},
+		{
+			Name: schema.AttributeTypeExcludeDates,
+		},
+		{
+			Name: schema.AttributeTypeCalendar,
+		},
 		{
 			Name:     schema.AttributeTypePipeline,
 			Required: true,
@@ -206,6 +337,24 @@ var TriggerQueryBlockSchema = &hcl.BodySchema{
 			// Schedule is not a required attribute for Query Trigger, default to every 15 minutes
 			Name: schema.AttributeTypeSchedule,
 		},
+		{
+			Name: schema.AttributeTypeTimezone,
+		},
+		{
+			Name: schema.AttributeTypeStartTime,
+		},
+		{
+			Name: schema.AttributeTypeEndTime,
+		},
+		{
+			Name: schema.AttributeTypeJitter,
+		},
+		{
+			Name: schema.AttributeTypeExcludeDates,
+		},
+		{
+			Name: schema.AttributeTypeCalendar,
+		},
 		{
 			Name:     schema.AttributeTypeSql,
 			Required: true,
@@ -269,6 +418,81 @@ var TriggerHttpBlockSchema = &hcl.BodySchema{
 			Type:       schema.BlockTypeMethod,
 			LabelNames: []string{schema.LabelName},
 		},
+		{
+			Type: schema.BlockTypeVerify,
+		},
+		{
+			Type:       schema.BlockTypeParam,
+			LabelNames: []string{schema.LabelName},
+		},
+	},
+}
+
+var TriggerHttpVerifyBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeType,
+			Required: true,
+		},
+		{
+			Name:     schema.AttributeTypeSecret,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeHeader,
+		},
+		{
+			Name: schema.AttributeTypePrefix,
+		},
+		{
+			Name: schema.AttributeTypeTolerance,
+		},
+	},
+}
+
+var TriggerFileBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeDescription,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTitle,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeDocumentation,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeTags,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypePath,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeEvents,
+		},
+		{
+			Name: schema.AttributeTypeDebounce,
+		},
+		{
+			Name: schema.AttributeTypeIgnore,
+		},
+		{
+			Name:     schema.AttributeTypePipeline,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeArgs,
+		},
+		{
+			Name: schema.AttributeTypeEnabled,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
 		{
 			Type:       schema.BlockTypeParam,
 			LabelNames: []string{schema.LabelName},
@@ -392,6 +616,21 @@ var PipelineStepHttpBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeRequestHeaders,
 		},
+		{
+			Name: schema.AttributeTypeProxyUrl,
+		},
+		{
+			Name: schema.AttributeTypeNoProxy,
+		},
+		{
+			Name: schema.AttributeTypeConnectTimeout,
+		},
+		{
+			Name: schema.AttributeTypeMaxRedirects,
+		},
+		{
+			Name: schema.AttributeTypeHttp2,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
@@ -407,6 +646,12 @@ var PipelineStepHttpBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypePipelineBasicAuth,
 		},
+		{
+			Type: schema.BlockTypePipelineAuth,
+		},
+		{
+			Type: schema.BlockTypePagination,
+		},
 		{
 			Type: schema.BlockTypeLoop,
 		},
@@ -416,6 +661,9 @@ var PipelineStepHttpBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 	},
 }
 
@@ -432,6 +680,75 @@ var PipelineBasicAuthBlockSchema = &hcl.BodySchema{
 	},
 }
 
+var PipelineHttpAuthBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeType,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeTokenUrl,
+		},
+		{
+			Name: schema.AttributeTypeClientId,
+		},
+		{
+			Name: schema.AttributeTypeClientSecret,
+		},
+		{
+			Name: schema.AttributeTypeScopes,
+		},
+		{
+			Name: schema.AttributeTypeAudience,
+		},
+		{
+			Name: schema.AttributeTypeToken,
+		},
+		{
+			Name: schema.AttributeTypeConnection,
+		},
+		{
+			Name: schema.AttributeTypeRegion,
+		},
+		{
+			Name: schema.AttributeTypeService,
+		},
+		{
+			Name: schema.AttributeTypeClientCertPem,
+		},
+		{
+			Name: schema.AttributeTypeClientKeyPem,
+		},
+	},
+}
+
+var PipelineHttpPaginationBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeType,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeCursorPath,
+		},
+		{
+			Name: schema.AttributeTypeQueryParam,
+		},
+		{
+			Name: schema.AttributeTypeStart,
+		},
+		{
+			Name: schema.AttributeTypeIncrement,
+		},
+		{
+			Name: schema.AttributeTypeItemsPath,
+		},
+		{
+			Name: schema.AttributeTypeMaxPages,
+		},
+	},
+}
+
 var PipelineStepSleepBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{
@@ -474,6 +791,9 @@ var PipelineStepSleepBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 	},
 }
 
@@ -536,11 +856,23 @@ var PipelineStepEmailBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeSubject,
 		},
+		{
+			Name: schema.AttributeTypeHtmlBody,
+		},
+		{
+			Name: schema.AttributeTypeReplyTo,
+		},
+		{
+			Name: schema.AttributeTypeHeaders,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: schema.BlockTypeAttachment,
+		},
 		{
 			Type: schema.BlockTypeError,
 		},
@@ -557,6 +889,29 @@ var PipelineStepEmailBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
+	},
+}
+
+var PipelineEmailAttachmentBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: schema.AttributeTypePath,
+		},
+		{
+			Name: schema.AttributeTypeContent,
+		},
+		{
+			Name: schema.AttributeTypeFilename,
+		},
+		{
+			Name: schema.AttributeTypeContentType,
+		},
+		{
+			Name: schema.AttributeTypeContentId,
+		},
 	},
 }
 
@@ -589,6 +944,15 @@ var PipelineStepQueryBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeArgs,
 		},
+		{
+			Name: schema.AttributeTypeStatements,
+		},
+		{
+			Name: schema.AttributeTypeTransaction,
+		},
+		{
+			Name: schema.AttributeTypeIsolationLevel,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
@@ -610,6 +974,9 @@ var PipelineStepQueryBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 	},
 }
 
@@ -654,6 +1021,9 @@ var PipelineStepTransformBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 	},
 }
 
@@ -701,6 +1071,9 @@ var PipelineStepPipelineBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 	},
 }
 
@@ -761,9 +1134,29 @@ var PipelineStepFunctionBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 		{
 			Type: schema.BlockTypeLoop,
 		},
+		{
+			Type: schema.BlockTypeWasm,
+		},
+	},
+}
+
+var PipelineFunctionWasmBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: schema.AttributeTypeMemoryLimit,
+		},
+		{
+			Name: schema.AttributeTypeFuelLimit,
+		},
+		{
+			Name: schema.AttributeTypeAllowedEnv,
+		},
 	},
 }
 
@@ -828,6 +1221,21 @@ var PipelineStepContainerBlockSchema = &hcl.BodySchema{
 		{
 			Name: schema.AttributeTypeWorkdir,
 		},
+		{
+			Name: schema.AttributeTypeNetworkMode,
+		},
+		{
+			Name: schema.AttributeTypeStdin,
+		},
+		{
+			Name: schema.AttributeTypePidsLimit,
+		},
+		{
+			Name: schema.AttributeTypeCapDrop,
+		},
+		{
+			Name: schema.AttributeTypeOutputFormat,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
@@ -849,6 +1257,28 @@ var PipelineStepContainerBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
+		{
+			Type: schema.BlockTypeMount,
+		},
+	},
+}
+
+var PipelineContainerMountBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     schema.AttributeTypeSource,
+			Required: true,
+		},
+		{
+			Name:     schema.AttributeTypeTarget,
+			Required: true,
+		},
+		{
+			Name: schema.AttributeTypeReadOnly,
+		},
 	},
 }
 
@@ -903,6 +1333,48 @@ var PipelineStepInputBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeChannel,
 			Required: false,
 		},
+		{
+			Name: schema.AttributeTypeMin,
+		},
+		{
+			Name: schema.AttributeTypeMax,
+		},
+		{
+			Name: schema.AttributeTypePattern,
+		},
+		{
+			Name: schema.AttributeTypePlaceholder,
+		},
+		{
+			Name: schema.AttributeTypeValidation,
+		},
+		{
+			Name: schema.AttributeTypeConfirmPhrase,
+		},
+		{
+			Name: schema.AttributeTypeColor,
+		},
+		{
+			Name: schema.AttributeTypeSections,
+		},
+		{
+			Name: schema.AttributeTypeTimeout,
+		},
+		{
+			Name: schema.AttributeTypeDefaultValue,
+		},
+		{
+			Name: schema.AttributeTypeOnTimeout,
+		},
+		{
+			Name: schema.AttributeTypeReminder,
+		},
+		{
+			Name: schema.AttributeTypeEscalation,
+		},
+		{
+			Name: schema.AttributeTypeEscalationWindow,
+		},
 		{
 			Name: schema.AttributeTypeMaxConcurrency,
 		},
@@ -921,6 +1393,9 @@ var PipelineStepInputBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 		{
 			Type:       schema.BlockTypeOption,
 			LabelNames: []string{schema.LabelName},
@@ -976,6 +1451,16 @@ var PipelineStepMessageBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeChannel,
 			Required: false,
 		},
+		{
+			Name:     schema.AttributeTypeSeverity,
+			Required: false,
+		},
+		{
+			Name: schema.AttributeTypeColor,
+		},
+		{
+			Name: schema.AttributeTypeSections,
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -991,6 +1476,9 @@ var PipelineStepMessageBlockSchema = &hcl.BodySchema{
 		{
 			Type: schema.BlockTypeThrow,
 		},
+		{
+			Type: schema.BlockTypeCompensate,
+		},
 		{
 			Type: schema.BlockTypeLoop,
 		},
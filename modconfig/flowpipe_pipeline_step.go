@@ -299,6 +299,7 @@ type PipelineStep interface {
 	GetRetryConfig(*hcl.EvalContext, bool) (*RetryConfig, hcl.Diagnostics)
 	GetLoopConfig() LoopDefn
 	GetThrowConfig() []*ThrowConfig
+	GetCompensateConfig() *CompensateConfig
 	SetOutputConfig(map[string]*PipelineOutput)
 	GetOutputConfig() map[string]*PipelineOutput
 	Equals(other PipelineStep) bool
@@ -319,20 +320,21 @@ type PipelineStepBaseInterface interface {
 
 // A common base struct that all pipeline steps must embed
 type PipelineStepBase struct {
-	Title               *string        `json:"title,omitempty"`
-	Description         *string        `json:"description,omitempty"`
-	Name                string         `json:"name"`
-	Type                string         `json:"step_type"`
-	PipelineName        string         `json:"pipeline_name,omitempty"`
-	Pipeline            *Pipeline      `json:"-"`
-	Timeout             interface{}    `json:"timeout,omitempty"`
-	DependsOn           []string       `json:"depends_on,omitempty"`
-	CredentialDependsOn []string       `json:"credential_depends_on,omitempty"`
-	ConnectionDependsOn []string       `json:"connection_depends_on,omitempty"`
-	Resolved            bool           `json:"resolved,omitempty"`
-	ErrorConfig         *ErrorConfig   `json:"-"`
-	RetryConfig         *RetryConfig   `json:"retry,omitempty"`
-	ThrowConfig         []*ThrowConfig `json:"throw,omitempty"`
+	Title               *string           `json:"title,omitempty"`
+	Description         *string           `json:"description,omitempty"`
+	Name                string            `json:"name"`
+	Type                string            `json:"step_type"`
+	PipelineName        string            `json:"pipeline_name,omitempty"`
+	Pipeline            *Pipeline         `json:"-"`
+	Timeout             interface{}       `json:"timeout,omitempty"`
+	DependsOn           []string          `json:"depends_on,omitempty"`
+	CredentialDependsOn []string          `json:"credential_depends_on,omitempty"`
+	ConnectionDependsOn []string          `json:"connection_depends_on,omitempty"`
+	Resolved            bool              `json:"resolved,omitempty"`
+	ErrorConfig         *ErrorConfig      `json:"-"`
+	RetryConfig         *RetryConfig      `json:"retry,omitempty"`
+	ThrowConfig         []*ThrowConfig    `json:"throw,omitempty"`
+	CompensateConfig    *CompensateConfig `json:"compensate,omitempty"`
 	// TODO: we should serialise this, it's used in PipelineLoaded event to have a record the exact pipeline config loaded. There's no further need apart from record keeping, so it's OK to have it unserializeable for now.
 	LoopConfig      LoopDefn                   `json:"-"`
 	OutputConfig    map[string]*PipelineOutput `json:"-"`
@@ -492,6 +494,12 @@ func (p *PipelineStepBase) GetThrowConfig() []*ThrowConfig {
 	return p.ThrowConfig
 }
 
+// The compensate config is returned unresolved, the executor resolves it with Resolve when the pipeline fails and the
+// compensation has to be run.
+func (p *PipelineStepBase) GetCompensateConfig() *CompensateConfig {
+	return p.CompensateConfig
+}
+
 func (p *PipelineStepBase) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
 	diags := hcl.Diagnostics{}
 
@@ -605,6 +613,32 @@ func (p *PipelineStepBase) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.Ev
 
 	}
 
+	compensateBlocks := blocks.ByType()[schema.BlockTypeCompensate]
+	if len(compensateBlocks) > 1 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Only one compensate block is allowed per step",
+			Subject:  &compensateBlocks[1].DefRange,
+		})
+	}
+
+	if len(compensateBlocks) == 1 {
+		compensateBlock := compensateBlocks[0]
+		compensateConfig := NewCompensateConfig(p)
+
+		attrs, moreDiags := compensateBlock.Body.JustAttributes()
+		if len(moreDiags) > 0 {
+			return append(diags, moreDiags...)
+		}
+
+		moreDiags = compensateConfig.SetAttributes(compensateBlock, attrs, evalContext)
+		if len(moreDiags) > 0 {
+			return append(diags, moreDiags...)
+		}
+
+		p.CompensateConfig = compensateConfig
+	}
+
 	return diags
 }
 
@@ -688,6 +722,10 @@ func (p *PipelineStepBase) Equals(other *PipelineStepBase) bool {
 		}
 	}
 
+	if !p.CompensateConfig.Equals(other.CompensateConfig) {
+		return false
+	}
+
 	// Compare ForEach (if not nil)
 	if (p.ForEach == nil && other.ForEach != nil) || (p.ForEach != nil && other.ForEach == nil) {
 		return false
@@ -0,0 +1,365 @@
+package modconfig
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func NewCompensateConfig(p *PipelineStepBase) *CompensateConfig {
+	return &CompensateConfig{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// CompensateConfig is the "compensate" block of a step. It names the pipeline to run to undo the work of the step
+// when a later step of the pipeline fails after this step has succeeded (saga pattern).
+//
+// The compensation is always a pipeline, run with the args of the block. Inline compensating steps are not
+// supported, a step that needs one must wrap it in a pipeline.
+type CompensateConfig struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	// The steps referenced by the compensate block. These are not added to the parent step depends_on, the
+	// compensation runs after the pipeline failed so it must only reference steps that completed before the
+	// parent step (or the parent step itself)
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	If           *bool     `json:"if,omitempty"`
+	Pipeline     cty.Value `json:"-"`
+	PipelineName string    `json:"pipeline,omitempty"`
+	Args         Input     `json:"args,omitempty"`
+}
+
+func (c *CompensateConfig) AppendDependsOn(dependsOn ...string) {
+	for _, dep := range dependsOn {
+		if !slices.Contains(c.DependsOn, dep) {
+			c.DependsOn = append(c.DependsOn, dep)
+		}
+	}
+}
+
+func (c *CompensateConfig) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	c.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (c *CompensateConfig) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	c.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (c *CompensateConfig) GetPipeline() *Pipeline {
+	return c.PipelineStepBase.GetPipeline()
+}
+
+func (c *CompensateConfig) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	c.UnresolvedAttributes[name] = expr
+}
+
+func (c *CompensateConfig) SetAttributes(compensateBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case schema.AttributeTypeIf:
+			c.AddUnresolvedAttribute(name, attr.Expr)
+
+			dependsOn, _, _ := allDependsOnFromVariables(attr.Expr.Variables())
+			c.AppendDependsOn(dependsOn...)
+
+		case schema.AttributeTypePipeline:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, c)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				if !val.Type().IsObjectType() || !val.Type().HasAttribute(schema.AttributeTypeName) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "The " + schema.AttributeTypePipeline + " attribute of the compensate block must be a reference to a pipeline",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+
+				c.Pipeline = val
+				c.PipelineName = val.GetAttr(schema.AttributeTypeName).AsString()
+			}
+
+		case schema.AttributeTypeArgs:
+			val, stepDiags := dependsOnFromExpressionsWithResultControl(attr, evalContext, c, true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				goVals, err := hclhelpers.CtyToGoMapInterface(val)
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse " + schema.AttributeTypeArgs + " attribute to Go values",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				c.Args = goVals
+			}
+
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid argument",
+				Detail:   "Unsupported argument '" + name + "' in compensate block",
+				Subject:  &attr.Range,
+			})
+		}
+	}
+
+	if hclAttributes[schema.AttributeTypePipeline] == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   "The argument 'pipeline' is required, but no definition was found.",
+			Subject:  &compensateBlock.DefRange,
+		})
+	}
+
+	return diags
+}
+
+func (c *CompensateConfig) Equals(other *CompensateConfig) bool {
+	if c == nil && other == nil {
+		return true
+	}
+
+	if c == nil && other != nil || c != nil && other == nil {
+		return false
+	}
+
+	for k, v := range c.UnresolvedAttributes {
+		if other.UnresolvedAttributes[k] == nil || !hclhelpers.ExpressionsEqual(v, other.UnresolvedAttributes[k]) {
+			return false
+		}
+	}
+
+	// reverse
+	for k := range other.UnresolvedAttributes {
+		if _, ok := c.UnresolvedAttributes[k]; !ok {
+			return false
+		}
+	}
+
+	if !helpers.StringSliceEqualIgnoreOrder(c.DependsOn, other.DependsOn) {
+		return false
+	}
+
+	if !reflect.DeepEqual(c.Args, other.Args) {
+		return false
+	}
+
+	return utils.BoolPtrEqual(c.If, other.If) &&
+		c.PipelineName == other.PipelineName
+}
+
+// Resolve resolves the compensate block at runtime. If the "if" attribute resolves to false the returned config
+// will only have the If field set, the caller should not run the compensation.
+func (c *CompensateConfig) Resolve(evalContext *hcl.EvalContext) (*CompensateConfig, hcl.Diagnostics) {
+	// make a copy, don't point to the same memory
+	newCompensateConfig := &CompensateConfig{}
+	diags := hcl.Diagnostics{}
+
+	if c.If != nil {
+		newCompensateConfig.If = utils.ToPointer(*c.If)
+	} else if c.UnresolvedAttributes[schema.AttributeTypeIf] != nil {
+		expr := c.UnresolvedAttributes[schema.AttributeTypeIf]
+		val, moreDiags := expr.Value(evalContext)
+		if len(moreDiags) > 0 {
+			return nil, moreDiags
+		}
+
+		if val.Type() != cty.Bool || val.IsNull() {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + schema.AttributeTypeIf + " attribute to bool",
+				Subject:  expr.Range().Ptr(),
+			}}
+		}
+		newCompensateConfig.If = utils.ToPointer(val.True())
+	}
+
+	if newCompensateConfig.If != nil && !*newCompensateConfig.If {
+		return newCompensateConfig, diags
+	}
+
+	if c.UnresolvedAttributes[schema.AttributeTypePipeline] != nil {
+		expr := c.UnresolvedAttributes[schema.AttributeTypePipeline]
+		val, moreDiags := expr.Value(evalContext)
+		if len(moreDiags) > 0 {
+			return nil, moreDiags
+		}
+
+		if !val.Type().IsObjectType() || !val.Type().HasAttribute(schema.AttributeTypeName) {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The " + schema.AttributeTypePipeline + " attribute of the compensate block must be a reference to a pipeline",
+				Subject:  expr.Range().Ptr(),
+			}}
+		}
+		newCompensateConfig.Pipeline = val
+		newCompensateConfig.PipelineName = val.GetAttr(schema.AttributeTypeName).AsString()
+	} else {
+		newCompensateConfig.Pipeline = c.Pipeline
+		newCompensateConfig.PipelineName = c.PipelineName
+	}
+
+	if c.UnresolvedAttributes[schema.AttributeTypeArgs] != nil {
+		expr := c.UnresolvedAttributes[schema.AttributeTypeArgs]
+		val, moreDiags := expr.Value(evalContext)
+		if len(moreDiags) > 0 {
+			return nil, moreDiags
+		}
+
+		args, err := hclhelpers.CtyToGoMapInterface(val)
+		if err != nil {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + schema.AttributeTypeArgs + " attribute to Go values",
+				Subject:  expr.Range().Ptr(),
+			}}
+		}
+		newCompensateConfig.Args = args
+	} else if c.Args != nil {
+		newCompensateConfig.Args = c.Args
+	}
+
+	return newCompensateConfig, diags
+}
+
+// Validate checks that the compensate block only references steps that are guaranteed to have completed before
+// the parent step, i.e. the parent step itself or one of its (direct or indirect) dependencies.
+func (c *CompensateConfig) Validate(step PipelineStep, pipeline *Pipeline) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if pipeline == nil {
+		return diags
+	}
+
+	ancestors := pipeline.GetStepAncestors(step.GetFullyQualifiedName())
+
+	for _, dep := range c.DependsOn {
+		if dep == step.GetFullyQualifiedName() || ancestors[dep] {
+			continue
+		}
+
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The compensate block of step " + step.GetFullyQualifiedName() + " references step " + dep + " which does not run before it",
+			Detail:   "A compensate block may only reference the step itself or the steps it depends on",
+			Subject:  step.GetRange(),
+		})
+	}
+
+	return diags
+}
+
+// GetStepAncestors returns all the steps that the given step directly or indirectly depends on
+func (p *Pipeline) GetStepAncestors(stepFullyQualifiedName string) map[string]bool {
+	ancestors := map[string]bool{}
+
+	queue := []string{stepFullyQualifiedName}
+	for len(queue) > 0 {
+		current := p.GetStep(queue[0])
+		queue = queue[1:]
+
+		if current == nil {
+			continue
+		}
+
+		for _, dep := range current.GetDependsOn() {
+			if !ancestors[dep] {
+				ancestors[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	return ancestors
+}
+
+// GetCompensationOrder returns the steps with a compensate block in the order their compensation must be run,
+// i.e. the reverse of the order they were run: a step is always compensated before any of the steps it depends on.
+//
+// completedSteps is the list of the steps that completed successfully. Only these steps are compensated, if it
+// is nil every step with a compensate block is returned. Steps that do not depend on each other are compensated in
+// reverse declaration order.
+func (p *Pipeline) GetCompensationOrder(completedSteps []string) []PipelineStep {
+	candidates := map[string]PipelineStep{}
+	for _, step := range p.Steps {
+		if step.GetCompensateConfig() == nil {
+			continue
+		}
+
+		if completedSteps != nil && !slices.Contains(completedSteps, step.GetFullyQualifiedName()) {
+			continue
+		}
+
+		candidates[step.GetFullyQualifiedName()] = step
+	}
+
+	// number of candidate steps that depend on each candidate, a step can only be compensated once all the
+	// steps that depend on it have been compensated
+	dependents := map[string]int{}
+	ancestors := map[string]map[string]bool{}
+	for name := range candidates {
+		ancestors[name] = p.GetStepAncestors(name)
+		for ancestor := range ancestors[name] {
+			if _, ok := candidates[ancestor]; ok {
+				dependents[ancestor]++
+			}
+		}
+	}
+
+	var res []PipelineStep
+	done := map[string]bool{}
+
+	for len(res) < len(candidates) {
+		progress := false
+
+		// walk backwards so independent steps are compensated in reverse declaration order
+		for i := len(p.Steps) - 1; i >= 0; i-- {
+			name := p.Steps[i].GetFullyQualifiedName()
+			if _, ok := candidates[name]; !ok || done[name] || dependents[name] > 0 {
+				continue
+			}
+
+			res = append(res, p.Steps[i])
+			done[name] = true
+			progress = true
+
+			for ancestor := range ancestors[name] {
+				if _, ok := candidates[ancestor]; ok {
+					dependents[ancestor]--
+				}
+			}
+			break
+		}
+
+		// dependency cycle, this is caught by the pipeline validation so it should never happen
+		if !progress {
+			break
+		}
+	}
+
+	return res
+}
@@ -1,10 +1,13 @@
 package modconfig
 
 import (
+	"errors"
 	"reflect"
+	"slices"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/iancoleman/strcase"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
@@ -30,6 +33,12 @@ type PipelineStepContainer struct {
 	ReadOnly          *bool             `json:"read_only"`
 	User              *string           `json:"user"`
 	Workdir           *string           `json:"workdir"`
+	Mounts            []*ContainerMount `json:"mounts,omitempty"`
+	NetworkMode       *string           `json:"network_mode,omitempty"`
+	Stdin             *string           `json:"stdin,omitempty"`
+	PidsLimit         *int64            `json:"pids_limit,omitempty"`
+	CapDrop           []string          `json:"cap_drop,omitempty"`
+	OutputFormat      *string           `json:"output_format,omitempty"`
 }
 
 func (p *PipelineStepContainer) Equals(iOther PipelineStep) bool {
@@ -51,9 +60,18 @@ func (p *PipelineStepContainer) Equals(iOther PipelineStep) bool {
 		return false
 	}
 
+	if !slices.EqualFunc(p.Mounts, other.Mounts, func(a, b *ContainerMount) bool { return a.Equals(b) }) {
+		return false
+	}
+
 	return utils.PtrEqual(p.Image, other.Image) &&
 		reflect.DeepEqual(p.Cmd, other.Cmd) &&
-		reflect.DeepEqual(p.Env, other.Env)
+		reflect.DeepEqual(p.Env, other.Env) &&
+		utils.PtrEqual(p.NetworkMode, other.NetworkMode) &&
+		utils.PtrEqual(p.Stdin, other.Stdin) &&
+		utils.PtrEqual(p.PidsLimit, other.PidsLimit) &&
+		slices.Equal(p.CapDrop, other.CapDrop) &&
+		utils.PtrEqual(p.OutputFormat, other.OutputFormat)
 }
 
 func (p *PipelineStepContainer) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -156,6 +174,53 @@ func (p *PipelineStepContainer) GetInputs(evalContext *hcl.EvalContext) (map[str
 		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
 	}
 
+	// network_mode
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeNetworkMode, p.NetworkMode)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// stdin
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeStdin, p.Stdin)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// pids_limit
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypePidsLimit, p.PidsLimit)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// cap_drop
+	results, diags = stringSliceInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeCapDrop, &p.CapDrop)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// output_format
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeOutputFormat, p.OutputFormat)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// mounts
+	if len(p.Mounts) > 0 {
+		mounts := make([]map[string]interface{}, 0, len(p.Mounts))
+		for _, mount := range p.Mounts {
+			mountInput, err := mount.GetInputs(evalContext)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage(p.Name + ": unable to resolve mount: " + err.Error())
+			}
+			mounts = append(mounts, mountInput)
+		}
+		results[schema.AttributeTypeMounts] = mounts
+	}
+
+	if err := validateContainerInputs(results); err != nil {
+		return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+	}
+
 	results[schema.LabelName] = p.Name
 
 	memorySwappinessI, ok := results[schema.AttributeTypeMemorySwappiness]
@@ -370,6 +435,27 @@ func (p *PipelineStepContainer) SetAttributes(hclAttributes hcl.Attributes, eval
 					p.ReadOnly = &boolVal
 				}
 			}
+		case schema.AttributeTypeNetworkMode, schema.AttributeTypeStdin, schema.AttributeTypeOutputFormat:
+			stepDiags := setStringAttribute(attr, evalContext, p, strcase.ToCamel(name), true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case schema.AttributeTypePidsLimit:
+			stepDiags := setInt64AttributeWithResultReference(attr, evalContext, p, "PidsLimit", true, false)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case schema.AttributeTypeCapDrop:
+			stepDiags := setStringSliceAttribute(attr, evalContext, p, "CapDrop", false)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -402,5 +488,74 @@ func (p *PipelineStepContainer) Validate() hcl.Diagnostics {
 		})
 	}
 
+	for _, err := range validateContainerSettings(p.NetworkMode, p.PidsLimit, p.CapDrop, p.OutputFormat) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid container settings: " + err.Error(),
+			Subject:  p.GetRange(),
+		})
+	}
+
+	// two mounts can not share a target
+	targets := map[string]bool{}
+	for _, mount := range p.Mounts {
+		if mount.Target == "" {
+			continue
+		}
+		if targets[mount.Target] {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate mount target " + mount.Target + ": " + p.GetFullyQualifiedName(),
+				Subject:  p.GetRange(),
+			})
+		}
+		targets[mount.Target] = true
+	}
+
 	return diags
 }
+
+func (p *PipelineStepContainer) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := p.PipelineStepBase.SetBlockConfig(blocks, evalContext)
+
+	for _, mountBlock := range blocks.ByType()[schema.BlockTypeMount] {
+		mountContent, moreDiags := mountBlock.Body.Content(PipelineContainerMountBlockSchema)
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+
+		mount := NewContainerMount(&p.PipelineStepBase)
+		moreDiags = mount.SetAttributes(mountContent.Attributes, evalContext)
+		if len(moreDiags) > 0 {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+		p.Mounts = append(p.Mounts, mount)
+	}
+
+	return diags
+}
+
+// validateContainerInputs checks the container settings of the step inputs, which are only known at runtime when
+// they reference params or other steps
+func validateContainerInputs(inputs map[string]interface{}) error {
+	var networkMode, outputFormat *string
+	var pidsLimit *int64
+	var capDrop []string
+
+	if v, ok := inputs[schema.AttributeTypeNetworkMode].(string); ok {
+		networkMode = &v
+	}
+	if v, ok := inputs[schema.AttributeTypeOutputFormat].(string); ok {
+		outputFormat = &v
+	}
+	if v, ok := inputs[schema.AttributeTypePidsLimit].(int64); ok {
+		pidsLimit = &v
+	}
+	if v, ok := inputs[schema.AttributeTypeCapDrop].([]string); ok {
+		capDrop = v
+	}
+
+	return errors.Join(validateContainerSettings(networkMode, pidsLimit, capDrop, outputFormat)...)
+}
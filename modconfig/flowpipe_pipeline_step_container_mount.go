@@ -0,0 +1,330 @@
+package modconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	ContainerOutputFormatText = "text"
+	ContainerOutputFormatJson = "json"
+
+	ContainerNetworkModeNone   = "none"
+	ContainerNetworkModeBridge = "bridge"
+	ContainerNetworkModeHost   = "host"
+)
+
+var ValidContainerOutputFormats = []string{ContainerOutputFormatText, ContainerOutputFormatJson}
+
+var (
+	// a user defined network, or container:<name|id> to join the network of another container
+	containerNetworkNameRegex = regexp.MustCompile(`^(container:)?[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	// a Linux capability, with or without the CAP_ prefix, or ALL
+	containerCapabilityRegex = regexp.MustCompile(`^(?i)(cap_)?[a-z_]+$`)
+)
+
+func NewContainerMount(p *PipelineStepBase) *ContainerMount {
+	return &ContainerMount{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// ContainerMount is a "mount" block of the container step, a bind mount of a host path into the container
+type ContainerMount struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	Source   string `json:"source" cty:"source"`
+	Target   string `json:"target" cty:"target"`
+	ReadOnly *bool  `json:"read_only,omitempty" cty:"read_only"`
+}
+
+func (m *ContainerMount) AppendDependsOn(dependsOn ...string) {
+	m.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (m *ContainerMount) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	m.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (m *ContainerMount) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	m.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (m *ContainerMount) GetPipeline() *Pipeline {
+	return m.PipelineStepBase.GetPipeline()
+}
+
+func (m *ContainerMount) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	m.UnresolvedAttributes[name] = expr
+}
+
+func (m *ContainerMount) Equals(other *ContainerMount) bool {
+	if m == nil && other == nil {
+		return true
+	}
+
+	if m == nil && other != nil || m != nil && other == nil {
+		return false
+	}
+
+	if len(m.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for name, expr := range m.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[name]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return m.Source == other.Source &&
+		m.Target == other.Target &&
+		utils.BoolPtrEqual(m.ReadOnly, other.ReadOnly)
+}
+
+func (m *ContainerMount) IsReadOnly() bool {
+	return m.ReadOnly != nil && *m.ReadOnly
+}
+
+func (m *ContainerMount) SetAttributes(hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, attr := range hclAttributes {
+		val, stepDiags := dependsOnFromExpressions(attr, evalContext, m)
+		if stepDiags.HasErrors() {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+
+		if val == cty.NilVal {
+			continue
+		}
+
+		switch name {
+		case schema.AttributeTypeSource, schema.AttributeTypeTarget:
+			strVal, err := hclhelpers.CtyToString(val)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			if name == schema.AttributeTypeSource {
+				m.Source = strVal
+			} else {
+				m.Target = strVal
+			}
+
+			if err := m.validateField(name, strVal); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid mount: " + err.Error(),
+					Subject:  &attr.Range,
+				})
+			}
+
+		case schema.AttributeTypeReadOnly:
+			if val.Type() != cty.Bool {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to bool",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			readOnly := val.True()
+			m.ReadOnly = &readOnly
+		}
+	}
+
+	return diags
+}
+
+func (m *ContainerMount) validateField(name, value string) error {
+	switch name {
+	case schema.AttributeTypeSource:
+		if value == "" {
+			return fmt.Errorf("the source of a mount must not be empty")
+		}
+	case schema.AttributeTypeTarget:
+		if !path.IsAbs(value) {
+			return fmt.Errorf("the target of a mount must be an absolute path: %s", value)
+		}
+	}
+	return nil
+}
+
+// GetInputs returns the mount with the attributes resolved at runtime
+func (m *ContainerMount) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
+	resolved := &ContainerMount{
+		Source:   m.Source,
+		Target:   m.Target,
+		ReadOnly: m.ReadOnly,
+	}
+
+	for name, expr := range m.UnresolvedAttributes {
+		switch name {
+		case schema.AttributeTypeSource, schema.AttributeTypeTarget:
+			var value string
+			diags := gohcl.DecodeExpression(expr, evalContext, &value)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			if err := m.validateField(name, value); err != nil {
+				return nil, err
+			}
+			if name == schema.AttributeTypeSource {
+				resolved.Source = value
+			} else {
+				resolved.Target = value
+			}
+		case schema.AttributeTypeReadOnly:
+			var value bool
+			diags := gohcl.DecodeExpression(expr, evalContext, &value)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			resolved.ReadOnly = &value
+		}
+	}
+
+	return resolved.inputMap(), nil
+}
+
+func (m *ContainerMount) inputMap() map[string]interface{} {
+	return map[string]interface{}{
+		schema.AttributeTypeSource:   m.Source,
+		schema.AttributeTypeTarget:   m.Target,
+		schema.AttributeTypeReadOnly: m.IsReadOnly(),
+	}
+}
+
+// containerMountsFromGoValue converts the mounts attribute of the loop block: a list of objects with source, target
+// and optional read_only
+func containerMountsFromGoValue(value interface{}) ([]map[string]interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mounts must be a list of objects with source, target and read_only")
+	}
+
+	mounts := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mount %d must be an object with source, target and read_only", i+1)
+		}
+
+		mount := &ContainerMount{}
+		for key, v := range itemMap {
+			switch key {
+			case schema.AttributeTypeSource, schema.AttributeTypeTarget:
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("the %s of mount %d must be a string", key, i+1)
+				}
+				if err := mount.validateField(key, s); err != nil {
+					return nil, err
+				}
+				if key == schema.AttributeTypeSource {
+					mount.Source = s
+				} else {
+					mount.Target = s
+				}
+			case schema.AttributeTypeReadOnly:
+				b, ok := v.(bool)
+				if !ok {
+					return nil, fmt.Errorf("the read_only of mount %d must be a bool", i+1)
+				}
+				mount.ReadOnly = &b
+			default:
+				return nil, fmt.Errorf("unsupported attribute %s in mount %d", key, i+1)
+			}
+		}
+
+		if mount.Source == "" || mount.Target == "" {
+			return nil, fmt.Errorf("mount %d must set source and target", i+1)
+		}
+		mounts = append(mounts, mount.inputMap())
+	}
+
+	return mounts, nil
+}
+
+// ValidateContainerNetworkMode returns an error if the network mode is not none, bridge, host, the name of a user
+// defined network or container:<name|id>
+func ValidateContainerNetworkMode(networkMode string) error {
+	switch networkMode {
+	case ContainerNetworkModeNone, ContainerNetworkModeBridge, ContainerNetworkModeHost:
+		return nil
+	}
+	if !containerNetworkNameRegex.MatchString(networkMode) {
+		return fmt.Errorf("invalid network_mode %s: must be one of none, bridge, host, the name of a network or container:<name|id>", networkMode)
+	}
+	return nil
+}
+
+// ValidateContainerCapability returns an error if the capability is not a Linux capability name or ALL
+func ValidateContainerCapability(capability string) error {
+	if !containerCapabilityRegex.MatchString(capability) {
+		return fmt.Errorf("invalid capability %s: specify a capability name such as NET_RAW or ALL", capability)
+	}
+	return nil
+}
+
+// ParseContainerOutput returns the stdout of the container according to the output format: unchanged for text, the
+// decoded value for json
+func ParseContainerOutput(outputFormat string, stdout string) (interface{}, error) {
+	switch outputFormat {
+	case "", ContainerOutputFormatText:
+		return stdout, nil
+	case ContainerOutputFormatJson:
+		var value interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &value); err != nil {
+			return nil, fmt.Errorf("unable to parse the container output as json: %w", err)
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid output_format %s: must be one of %s", outputFormat, strings.Join(ValidContainerOutputFormats, ", "))
+}
+
+// validateContainerSettings checks the container settings shared by the step and its loop block
+func validateContainerSettings(networkMode *string, pidsLimit *int64, capDrop []string, outputFormat *string) []error {
+	var errs []error
+
+	if networkMode != nil {
+		if err := ValidateContainerNetworkMode(*networkMode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if pidsLimit != nil && *pidsLimit <= 0 {
+		errs = append(errs, fmt.Errorf("invalid pids_limit %d: must be greater than 0", *pidsLimit))
+	}
+	for _, capability := range capDrop {
+		if err := ValidateContainerCapability(capability); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if outputFormat != nil && !slices.Contains(ValidContainerOutputFormats, *outputFormat) {
+		errs = append(errs, fmt.Errorf("invalid output_format %s: must be one of %s", *outputFormat, strings.Join(ValidContainerOutputFormats, ", ")))
+	}
+
+	return errs
+}
@@ -2,11 +2,14 @@ package modconfig
 
 import (
 	"reflect"
+	"slices"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/email"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -25,6 +28,11 @@ type PipelineStepEmail struct {
 	Body         *string  `json:"body"`
 	ContentType  *string  `json:"content_type"`
 	Subject      *string  `json:"subject"`
+
+	HtmlBody    *string            `json:"html_body,omitempty"`
+	ReplyTo     []string           `json:"reply_to,omitempty"`
+	Headers     map[string]string  `json:"headers,omitempty"`
+	Attachments []*EmailAttachment `json:"attachments,omitempty"`
 }
 
 func (p *PipelineStepEmail) Equals(iOther PipelineStep) bool {
@@ -54,8 +62,11 @@ func (p *PipelineStepEmail) Equals(iOther PipelineStep) bool {
 		reflect.DeepEqual(p.Bcc, other.Bcc) &&
 		reflect.DeepEqual(p.Body, other.Body) &&
 		reflect.DeepEqual(p.ContentType, other.ContentType) &&
-		reflect.DeepEqual(p.Subject, other.Subject)
-
+		reflect.DeepEqual(p.Subject, other.Subject) &&
+		reflect.DeepEqual(p.HtmlBody, other.HtmlBody) &&
+		reflect.DeepEqual(p.ReplyTo, other.ReplyTo) &&
+		reflect.DeepEqual(p.Headers, other.Headers) &&
+		slices.EqualFunc(p.Attachments, other.Attachments, func(a, b *EmailAttachment) bool { return a.Equals(b) })
 }
 
 func (p *PipelineStepEmail) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -179,6 +190,50 @@ func (p *PipelineStepEmail) GetInputs(evalContext *hcl.EvalContext) (map[string]
 		}
 	}
 
+	var htmlBody *string
+	if p.UnresolvedAttributes[schema.AttributeTypeHtmlBody] == nil {
+		htmlBody = p.HtmlBody
+	} else {
+		diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeHtmlBody], evalContext, &htmlBody)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError(p.Name, diags)
+		}
+	}
+
+	var replyTo []string
+	if p.UnresolvedAttributes[schema.AttributeTypeReplyTo] == nil {
+		replyTo = p.ReplyTo
+	} else {
+		diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeReplyTo], evalContext, &replyTo)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError(p.Name, diags)
+		}
+	}
+
+	var headers map[string]string
+	if p.UnresolvedAttributes[schema.AttributeTypeHeaders] == nil {
+		headers = p.Headers
+	} else {
+		diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeHeaders], evalContext, &headers)
+		if diags.HasErrors() {
+			return nil, error_helpers.HclDiagsToError(p.Name, diags)
+		}
+		for name, value := range headers {
+			if err := email.ValidateHeader(name, value); err != nil {
+				return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+			}
+		}
+	}
+
+	var attachments []map[string]interface{}
+	for _, attachment := range p.Attachments {
+		attachmentInputs, err := attachment.GetInputs(evalContext)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse attachment: " + err.Error())
+		}
+		attachments = append(attachments, attachmentInputs)
+	}
+
 	results := map[string]interface{}{}
 
 	if to != nil {
@@ -229,6 +284,22 @@ func (p *PipelineStepEmail) GetInputs(evalContext *hcl.EvalContext) (map[string]
 		results[schema.AttributeTypeSubject] = *subject
 	}
 
+	if htmlBody != nil {
+		results[schema.AttributeTypeHtmlBody] = *htmlBody
+	}
+
+	if replyTo != nil {
+		results[schema.AttributeTypeReplyTo] = replyTo
+	}
+
+	if headers != nil {
+		results[schema.AttributeTypeHeaders] = headers
+	}
+
+	if attachments != nil {
+		results[schema.AttributeTypeAttachments] = attachments
+	}
+
 	return results, nil
 }
 
@@ -472,6 +543,48 @@ func (p *PipelineStepEmail) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				p.Subject = &subject
 			}
 
+		case schema.AttributeTypeHtmlBody:
+			stepDiags := setStringAttribute(attr, evalContext, p, "HtmlBody", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypeReplyTo:
+			stepDiags := setStringSliceAttribute(attr, evalContext, p, "ReplyTo", false)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypeHeaders:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				headers, err := hclhelpers.CtyToGoMapString(val)
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse " + schema.AttributeTypeHeaders + " attribute to string map",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+
+				for name, value := range headers {
+					if err := email.ValidateHeader(name, value); err != nil {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Invalid headers: " + err.Error(),
+							Subject:  &attr.Range,
+						})
+					}
+				}
+				p.Headers = headers
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -484,3 +597,48 @@ func (p *PipelineStepEmail) SetAttributes(hclAttributes hcl.Attributes, evalCont
 	}
 	return diags
 }
+
+func (p *PipelineStepEmail) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := p.PipelineStepBase.SetBlockConfig(blocks, evalContext)
+
+	for _, attachmentBlock := range blocks.ByType()[schema.BlockTypeAttachment] {
+		attachmentContent, moreDiags := attachmentBlock.Body.Content(PipelineEmailAttachmentBlockSchema)
+		if moreDiags.HasErrors() {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+
+		attachment := NewEmailAttachment(&p.PipelineStepBase)
+		moreDiags = attachment.SetAttributes(attachmentBlock, attachmentContent.Attributes, evalContext)
+		if len(moreDiags) > 0 {
+			diags = append(diags, moreDiags...)
+			continue
+		}
+		p.Attachments = append(p.Attachments, attachment)
+	}
+
+	return diags
+}
+
+func (p *PipelineStepEmail) Validate() hcl.Diagnostics {
+	// validate the base attributes
+	diags := p.ValidateBaseAttributes()
+
+	// inline images are referenced from the html body, which is html_body or body with the text/html content type
+	hasHtml := p.HtmlBody != nil || p.UnresolvedAttributes[schema.AttributeTypeHtmlBody] != nil ||
+		p.UnresolvedAttributes[schema.AttributeTypeContentType] != nil ||
+		(p.ContentType != nil && *p.ContentType == "text/html")
+
+	for _, attachment := range p.Attachments {
+		if attachment.IsInline() && !hasHtml {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid attachment: an attachment with a content_id is an inline image, which requires an html body",
+				Subject:  p.GetRange(),
+			})
+			break
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,225 @@
+package modconfig
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/email"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func NewEmailAttachment(p *PipelineStepBase) *EmailAttachment {
+	return &EmailAttachment{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// EmailAttachment is an "attachment" block of the email step, a file read from a path or inline content such as the
+// output of another step. An attachment with a content_id is an inline image referenced from the html body.
+type EmailAttachment struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	Path        *string `json:"path,omitempty" cty:"path"`
+	Content     *string `json:"content,omitempty" cty:"content"`
+	Filename    *string `json:"filename,omitempty" cty:"filename"`
+	ContentType *string `json:"content_type,omitempty" cty:"content_type"`
+	ContentId   *string `json:"content_id,omitempty" cty:"content_id"`
+}
+
+func (a *EmailAttachment) AppendDependsOn(dependsOn ...string) {
+	a.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (a *EmailAttachment) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	a.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (a *EmailAttachment) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	a.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (a *EmailAttachment) GetPipeline() *Pipeline {
+	return a.PipelineStepBase.GetPipeline()
+}
+
+func (a *EmailAttachment) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	a.UnresolvedAttributes[name] = expr
+}
+
+func (a *EmailAttachment) Equals(other *EmailAttachment) bool {
+	if a == nil && other == nil {
+		return true
+	}
+
+	if a == nil && other != nil || a != nil && other == nil {
+		return false
+	}
+
+	if len(a.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for name, expr := range a.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[name]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return utils.PtrEqual(a.Path, other.Path) &&
+		utils.PtrEqual(a.Content, other.Content) &&
+		utils.PtrEqual(a.Filename, other.Filename) &&
+		utils.PtrEqual(a.ContentType, other.ContentType) &&
+		utils.PtrEqual(a.ContentId, other.ContentId)
+}
+
+// IsInline returns true if the attachment is an inline image, it may only be known at runtime
+func (a *EmailAttachment) IsInline() bool {
+	_, unresolved := a.UnresolvedAttributes[schema.AttributeTypeContentId]
+	return a.ContentId != nil || unresolved
+}
+
+func (a *EmailAttachment) SetAttributes(attachmentBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	_, hasPath := hclAttributes[schema.AttributeTypePath]
+	_, hasContent := hclAttributes[schema.AttributeTypeContent]
+	_, hasFilename := hclAttributes[schema.AttributeTypeFilename]
+
+	switch {
+	case hasPath && hasContent:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attachment: only one of path or content can be set",
+			Subject:  &attachmentBlock.DefRange,
+		})
+	case !hasPath && !hasContent:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attachment: one of path or content must be set",
+			Subject:  &attachmentBlock.DefRange,
+		})
+	case hasContent && !hasFilename:
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid attachment: filename is required with content",
+			Subject:  &attachmentBlock.DefRange,
+		})
+	}
+
+	for name, attr := range hclAttributes {
+		val, stepDiags := dependsOnFromExpressions(attr, evalContext, a)
+		if stepDiags.HasErrors() {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+
+		if val == cty.NilVal {
+			continue
+		}
+
+		strVal, err := hclhelpers.CtyToString(val)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + name + " attribute to string",
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		if err := a.validateField(name, strVal); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid attachment: " + err.Error(),
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		a.setField(name, &strVal)
+	}
+
+	return diags
+}
+
+func (a *EmailAttachment) setField(name string, value *string) {
+	switch name {
+	case schema.AttributeTypePath:
+		a.Path = value
+	case schema.AttributeTypeContent:
+		a.Content = value
+	case schema.AttributeTypeFilename:
+		a.Filename = value
+	case schema.AttributeTypeContentType:
+		a.ContentType = value
+	case schema.AttributeTypeContentId:
+		a.ContentId = value
+	}
+}
+
+func (a *EmailAttachment) validateField(name, value string) error {
+	switch name {
+	case schema.AttributeTypePath, schema.AttributeTypeFilename:
+		if value == "" {
+			return fmt.Errorf("the %s of an attachment must not be empty", name)
+		}
+	case schema.AttributeTypeContentId:
+		return email.ValidateContentId(value)
+	}
+	return nil
+}
+
+// GetInputs returns the attachment with the attributes resolved at runtime, the filename defaults to the base name of
+// the path
+func (a *EmailAttachment) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
+	resolved := &EmailAttachment{
+		Path:        a.Path,
+		Content:     a.Content,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		ContentId:   a.ContentId,
+	}
+
+	for name, expr := range a.UnresolvedAttributes {
+		var value string
+		diags := gohcl.DecodeExpression(expr, evalContext, &value)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if err := a.validateField(name, value); err != nil {
+			return nil, err
+		}
+		resolved.setField(name, &value)
+	}
+
+	inputs := map[string]interface{}{}
+	if resolved.Path != nil {
+		inputs[schema.AttributeTypePath] = *resolved.Path
+		if resolved.Filename == nil {
+			filename := filepath.Base(*resolved.Path)
+			resolved.Filename = &filename
+		}
+	}
+	if resolved.Content != nil {
+		inputs[schema.AttributeTypeContent] = *resolved.Content
+	}
+	if resolved.Filename != nil {
+		inputs[schema.AttributeTypeFilename] = *resolved.Filename
+	}
+	if resolved.ContentType != nil {
+		inputs[schema.AttributeTypeContentType] = *resolved.ContentType
+	}
+	if resolved.ContentId != nil {
+		inputs[schema.AttributeTypeContentId] = *resolved.ContentId
+	}
+	return inputs, nil
+}
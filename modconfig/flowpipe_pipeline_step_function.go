@@ -20,6 +20,9 @@ type PipelineStepFunction struct {
 
 	Event map[string]interface{} `json:"event"`
 	Env   map[string]string      `json:"env"`
+
+	// Wasm is the sandbox configuration of the wasm runtime
+	Wasm *FunctionWasmConfig `json:"wasm,omitempty"`
 }
 
 func (p *PipelineStepFunction) Equals(iOther PipelineStep) bool {
@@ -44,7 +47,8 @@ func (p *PipelineStepFunction) Equals(iOther PipelineStep) bool {
 	return p.Name == other.Name &&
 		p.Runtime == other.Runtime &&
 		p.Handler == other.Handler &&
-		p.Source == other.Source
+		p.Source == other.Source &&
+		p.Wasm.Equals(other.Wasm)
 }
 
 func (p *PipelineStepFunction) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -117,6 +121,23 @@ func (p *PipelineStepFunction) GetInputs(evalContext *hcl.EvalContext) (map[stri
 		}
 	}
 
+	if runtime == FunctionRuntimeWasm {
+		if err := ValidateFunctionWasmSource(src); err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+		}
+		if handler == "" {
+			handler = FunctionWasmDefaultHandler
+		}
+	}
+
+	if p.Wasm != nil {
+		wasm, err := p.Wasm.GetInputs(evalContext)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse wasm block: " + err.Error())
+		}
+		results[schema.BlockTypeWasm] = wasm
+	}
+
 	results[schema.LabelName] = p.PipelineName + "." + p.GetFullyQualifiedName()
 	results[schema.AttributeTypeSource] = src
 	results[schema.AttributeTypeRuntime] = runtime
@@ -216,8 +237,60 @@ func (p *PipelineStepFunction) SetAttributes(hclAttributes hcl.Attributes, evalC
 	return diags
 }
 
+func (p *PipelineStepFunction) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := p.PipelineStepBase.SetBlockConfig(blocks, evalContext)
+
+	if wasmBlocks := blocks.ByType()[schema.BlockTypeWasm]; len(wasmBlocks) > 0 {
+		if len(wasmBlocks) > 1 {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple wasm blocks found for step function",
+				Subject:  &wasmBlocks[1].DefRange,
+			})
+		}
+		wasmBlock := wasmBlocks[0]
+
+		wasmContent, moreDiags := wasmBlock.Body.Content(PipelineFunctionWasmBlockSchema)
+		if moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		wasmConfig := NewFunctionWasmConfig(&p.PipelineStepBase)
+		moreDiags = wasmConfig.SetAttributes(wasmBlock, wasmContent.Attributes, evalContext)
+		if len(moreDiags) > 0 {
+			return append(diags, moreDiags...)
+		}
+		p.Wasm = wasmConfig
+	}
+
+	return diags
+}
+
 func (p *PipelineStepFunction) Validate() hcl.Diagnostics {
 	// validate the base attributes
 	diags := p.ValidateBaseAttributes()
+
+	// the runtime and source are only checked here when they are known at parse time
+	_, runtimeUnresolved := p.UnresolvedAttributes[schema.AttributeTypeRuntime]
+	_, sourceUnresolved := p.UnresolvedAttributes[schema.AttributeTypeSource]
+
+	if !runtimeUnresolved && p.Runtime == FunctionRuntimeWasm && !sourceUnresolved {
+		if err := ValidateFunctionWasmSource(p.Source); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid wasm function: " + err.Error(),
+				Subject:  p.GetRange(),
+			})
+		}
+	}
+
+	if !runtimeUnresolved && p.Runtime != FunctionRuntimeWasm && p.Wasm != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The wasm block can only be set for a function step with runtime " + FunctionRuntimeWasm,
+			Subject:  p.GetRange(),
+		})
+	}
+
 	return diags
 }
@@ -0,0 +1,223 @@
+package modconfig
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// FunctionRuntimeWasm runs a WebAssembly (WASI preview 1) module in-process rather than in a container
+	FunctionRuntimeWasm = "wasm"
+
+	// FunctionWasmDefaultHandler is the exported function called when the step does not set a handler, the WASI
+	// command entry point
+	FunctionWasmDefaultHandler = "_start"
+)
+
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func NewFunctionWasmConfig(p *PipelineStepBase) *FunctionWasmConfig {
+	return &FunctionWasmConfig{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// FunctionWasmConfig is the "wasm" block of the function step, the sandbox limits of a module run by the wasm runtime
+type FunctionWasmConfig struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	// MemoryLimit is the maximum memory of the module in MB
+	MemoryLimit *int64 `json:"memory_limit,omitempty" cty:"memory_limit"`
+	// FuelLimit is the maximum number of function calls the module may make before it is stopped
+	FuelLimit *int64 `json:"fuel_limit,omitempty" cty:"fuel_limit"`
+	// AllowedEnv are the names of the host environment variables passed through to the module
+	AllowedEnv []string `json:"allowed_env,omitempty" cty:"allowed_env"`
+}
+
+func (w *FunctionWasmConfig) AppendDependsOn(dependsOn ...string) {
+	w.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (w *FunctionWasmConfig) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	w.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (w *FunctionWasmConfig) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	w.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (w *FunctionWasmConfig) GetPipeline() *Pipeline {
+	return w.PipelineStepBase.GetPipeline()
+}
+
+func (w *FunctionWasmConfig) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	w.UnresolvedAttributes[name] = expr
+}
+
+func (w *FunctionWasmConfig) Equals(other *FunctionWasmConfig) bool {
+	if w == nil && other == nil {
+		return true
+	}
+
+	if w == nil && other != nil || w != nil && other == nil {
+		return false
+	}
+
+	if len(w.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for name, expr := range w.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[name]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return utils.PtrEqual(w.MemoryLimit, other.MemoryLimit) &&
+		utils.PtrEqual(w.FuelLimit, other.FuelLimit) &&
+		slices.Equal(w.AllowedEnv, other.AllowedEnv)
+}
+
+func (w *FunctionWasmConfig) SetAttributes(wasmBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, attr := range hclAttributes {
+		val, stepDiags := dependsOnFromExpressions(attr, evalContext, w)
+		if stepDiags.HasErrors() {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+
+		if val == cty.NilVal {
+			continue
+		}
+
+		switch name {
+		case schema.AttributeTypeMemoryLimit, schema.AttributeTypeFuelLimit:
+			intVal, moreDiags := hclhelpers.CtyToInt64(val)
+			if moreDiags.HasErrors() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to integer",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			if name == schema.AttributeTypeMemoryLimit {
+				w.MemoryLimit = intVal
+			} else {
+				w.FuelLimit = intVal
+			}
+
+		case schema.AttributeTypeAllowedEnv:
+			allowedEnv, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string slice",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			w.AllowedEnv = allowedEnv
+		}
+	}
+
+	for _, err := range validateFunctionWasmSettings(w.MemoryLimit, w.FuelLimit, w.AllowedEnv) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid wasm settings: " + err.Error(),
+			Subject:  &wasmBlock.DefRange,
+		})
+	}
+
+	return diags
+}
+
+// GetInputs returns the wasm settings with the attributes resolved at runtime
+func (w *FunctionWasmConfig) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
+	memoryLimit := w.MemoryLimit
+	fuelLimit := w.FuelLimit
+	allowedEnv := w.AllowedEnv
+
+	for name, expr := range w.UnresolvedAttributes {
+		switch name {
+		case schema.AttributeTypeMemoryLimit, schema.AttributeTypeFuelLimit:
+			var value int64
+			diags := gohcl.DecodeExpression(expr, evalContext, &value)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			if name == schema.AttributeTypeMemoryLimit {
+				memoryLimit = &value
+			} else {
+				fuelLimit = &value
+			}
+		case schema.AttributeTypeAllowedEnv:
+			var value []string
+			diags := gohcl.DecodeExpression(expr, evalContext, &value)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			allowedEnv = value
+		}
+	}
+
+	if errs := validateFunctionWasmSettings(memoryLimit, fuelLimit, allowedEnv); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	inputs := map[string]interface{}{}
+	if memoryLimit != nil {
+		inputs[schema.AttributeTypeMemoryLimit] = *memoryLimit
+	}
+	if fuelLimit != nil {
+		inputs[schema.AttributeTypeFuelLimit] = *fuelLimit
+	}
+	if allowedEnv != nil {
+		inputs[schema.AttributeTypeAllowedEnv] = allowedEnv
+	}
+	return inputs, nil
+}
+
+// validateFunctionWasmSettings checks the limits of the wasm block
+func validateFunctionWasmSettings(memoryLimit, fuelLimit *int64, allowedEnv []string) []error {
+	var errs []error
+
+	// wasm memory grows in 64KiB pages up to 4GiB
+	if memoryLimit != nil && (*memoryLimit <= 0 || *memoryLimit > 4096) {
+		errs = append(errs, fmt.Errorf("invalid memory_limit %d: must be between 1 and 4096 MB", *memoryLimit))
+	}
+	if fuelLimit != nil && *fuelLimit <= 0 {
+		errs = append(errs, fmt.Errorf("invalid fuel_limit %d: must be greater than 0", *fuelLimit))
+	}
+	for _, name := range allowedEnv {
+		if !envVarNameRegex.MatchString(name) {
+			errs = append(errs, fmt.Errorf("invalid allowed_env %s: must be an environment variable name", name))
+		}
+	}
+
+	return errs
+}
+
+// ValidateFunctionWasmSource returns an error if the source of a wasm function is not a .wasm module
+func ValidateFunctionWasmSource(source string) error {
+	if !strings.HasSuffix(strings.ToLower(source), ".wasm") {
+		return fmt.Errorf("the source of a wasm function must be the path to a .wasm module: %s", source)
+	}
+	return nil
+}
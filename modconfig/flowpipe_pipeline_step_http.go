@@ -2,7 +2,9 @@ package modconfig
 
 import (
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -10,6 +12,8 @@ import (
 	"github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/httpclient"
+	"github.com/turbot/pipe-fittings/perr"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/turbot/pipe-fittings/utils"
 	"github.com/zclconf/go-cty/cty"
@@ -34,13 +38,23 @@ var ValidHttpMethods = []string{
 type PipelineStepHttp struct {
 	PipelineStepBase
 
-	Url             *string                `json:"url" binding:"required"`
-	Method          *string                `json:"method,omitempty"`
-	CaCertPem       *string                `json:"ca_cert_pem,omitempty"`
-	Insecure        *bool                  `json:"insecure,omitempty"`
-	RequestBody     *string                `json:"request_body,omitempty"`
-	RequestHeaders  map[string]interface{} `json:"request_headers,omitempty"`
-	BasicAuthConfig *BasicAuthConfig       `json:"basic_auth,omitempty"`
+	Url              *string                `json:"url" binding:"required"`
+	Method           *string                `json:"method,omitempty"`
+	CaCertPem        *string                `json:"ca_cert_pem,omitempty"`
+	Insecure         *bool                  `json:"insecure,omitempty"`
+	RequestBody      *string                `json:"request_body,omitempty"`
+	RequestHeaders   map[string]interface{} `json:"request_headers,omitempty"`
+	BasicAuthConfig  *BasicAuthConfig       `json:"basic_auth,omitempty"`
+	AuthConfig       *HttpAuthConfig        `json:"auth,omitempty"`
+	PaginationConfig *HttpPaginationConfig  `json:"pagination,omitempty"`
+
+	// transport settings, the total request timeout is the step timeout. Unset values fall back to the workspace
+	// profile settings.
+	ProxyUrl       *string  `json:"proxy_url,omitempty"`
+	NoProxy        []string `json:"no_proxy,omitempty"`
+	ConnectTimeout *string  `json:"connect_timeout,omitempty"`
+	MaxRedirects   *int64   `json:"max_redirects,omitempty"`
+	Http2          *bool    `json:"http2,omitempty"`
 }
 
 func (p *PipelineStepHttp) Equals(iOther PipelineStep) bool {
@@ -68,12 +82,25 @@ func (p *PipelineStepHttp) Equals(iOther PipelineStep) bool {
 		return false
 	}
 
+	if !p.AuthConfig.Equals(other.AuthConfig) {
+		return false
+	}
+
+	if !p.PaginationConfig.Equals(other.PaginationConfig) {
+		return false
+	}
+
 	return utils.PtrEqual(p.Url, other.Url) &&
 		utils.PtrEqual(p.Method, other.Method) &&
 		utils.PtrEqual(p.CaCertPem, other.CaCertPem) &&
 		utils.BoolPtrEqual(p.Insecure, other.Insecure) &&
 		utils.PtrEqual(p.RequestBody, other.RequestBody) &&
-		reflect.DeepEqual(p.RequestHeaders, other.RequestHeaders)
+		reflect.DeepEqual(p.RequestHeaders, other.RequestHeaders) &&
+		utils.PtrEqual(p.ProxyUrl, other.ProxyUrl) &&
+		slices.Equal(p.NoProxy, other.NoProxy) &&
+		utils.PtrEqual(p.ConnectTimeout, other.ConnectTimeout) &&
+		utils.PtrEqual(p.MaxRedirects, other.MaxRedirects) &&
+		utils.BoolPtrEqual(p.Http2, other.Http2)
 }
 
 func (p *PipelineStepHttp) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -134,6 +161,36 @@ func (p *PipelineStepHttp) GetInputs(evalContext *hcl.EvalContext) (map[string]i
 		inputs[schema.AttributeTypeRequestHeaders] = requestHeaders
 	}
 
+	// transport settings
+	inputs, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), inputs, evalContext, schema.AttributeTypeProxyUrl, p.ProxyUrl)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	inputs, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), inputs, evalContext, schema.AttributeTypeNoProxy, p.NoProxy)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	inputs, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), inputs, evalContext, schema.AttributeTypeConnectTimeout, p.ConnectTimeout)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	inputs, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), inputs, evalContext, schema.AttributeTypeMaxRedirects, p.MaxRedirects)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	inputs, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), inputs, evalContext, schema.AttributeTypeHttp2, p.Http2)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	if _, err := httpclient.ConfigFromMap(inputs); err != nil {
+		return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+	}
+
 	if p.BasicAuthConfig != nil {
 		basicAuth, diags := p.BasicAuthConfig.GetInputs(evalContext, p.UnresolvedAttributes)
 		if diags.HasErrors() {
@@ -144,6 +201,22 @@ func (p *PipelineStepHttp) GetInputs(evalContext *hcl.EvalContext) (map[string]i
 		basicAuthMap["Password"] = basicAuth.Password
 		inputs[schema.BlockTypePipelineBasicAuth] = basicAuthMap
 	}
+
+	if p.AuthConfig != nil {
+		auth, diags := p.AuthConfig.GetInputs(evalContext)
+		if diags.HasErrors() {
+			return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		inputs[schema.BlockTypePipelineAuth] = auth
+	}
+
+	if p.PaginationConfig != nil {
+		pagination, diags := p.PaginationConfig.GetInputs(evalContext)
+		if diags.HasErrors() {
+			return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+		inputs[schema.BlockTypePagination] = pagination
+	}
 	inputs[schema.AttributeTypeStepName] = p.Name
 
 	return inputs, nil
@@ -236,6 +309,68 @@ func (p *PipelineStepHttp) SetAttributes(hclAttributes hcl.Attributes, evalConte
 					continue
 				}
 			}
+		case schema.AttributeTypeProxyUrl:
+			stepDiags := setStringAttribute(attr, evalContext, p, "ProxyUrl", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if p.ProxyUrl != nil {
+				if err := httpclient.ValidateProxyUrl(*p.ProxyUrl); err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid proxy_url: " + *p.ProxyUrl,
+						Detail:   err.Error(),
+						Subject:  &attr.Range,
+					})
+				}
+			}
+
+		case schema.AttributeTypeNoProxy:
+			stepDiags := setStringSliceAttribute(attr, evalContext, p, "NoProxy", false)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
+		case schema.AttributeTypeConnectTimeout:
+			stepDiags := setStringAttribute(attr, evalContext, p, "ConnectTimeout", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if p.ConnectTimeout != nil {
+				if d, err := time.ParseDuration(*p.ConnectTimeout); err != nil || d < 0 {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid connect_timeout: " + *p.ConnectTimeout + ". Specify a duration such as 5s",
+						Subject:  &attr.Range,
+					})
+				}
+			}
+
+		case schema.AttributeTypeMaxRedirects:
+			stepDiags := setInt64AttributeWithResultReference(attr, evalContext, p, "MaxRedirects", true, false)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if p.MaxRedirects != nil && *p.MaxRedirects < 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid max_redirects: the value must not be negative",
+					Subject:  &attr.Range,
+				})
+			}
+
+		case schema.AttributeTypeHttp2:
+			stepDiags := setBoolAttribute(attr, evalContext, p, "Http2", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -318,6 +453,69 @@ func (p *PipelineStepHttp) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.Ev
 		p.BasicAuthConfig = basicAuthConfig
 	}
 
+	if authBlocks := blocks.ByType()[schema.BlockTypePipelineAuth]; len(authBlocks) > 0 {
+		if len(authBlocks) > 1 {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple auth blocks found for step http",
+				Subject:  &authBlocks[1].DefRange,
+			})
+		}
+		authBlock := authBlocks[0]
+
+		if p.BasicAuthConfig != nil {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The auth and basic_auth blocks can not both be set for step http",
+				Subject:  &authBlock.DefRange,
+			})
+		}
+
+		authContent, moreDiags := authBlock.Body.Content(PipelineHttpAuthBlockSchema)
+		if moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		authConfig := NewHttpAuthConfig(&p.PipelineStepBase)
+		moreDiags = authConfig.SetAttributes(authBlock, authContent.Attributes, evalContext)
+		if len(moreDiags) > 0 {
+			return append(diags, moreDiags...)
+		}
+		p.AuthConfig = authConfig
+	}
+
+	if paginationBlocks := blocks.ByType()[schema.BlockTypePagination]; len(paginationBlocks) > 0 {
+		if len(paginationBlocks) > 1 {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Multiple pagination blocks found for step http",
+				Subject:  &paginationBlocks[1].DefRange,
+			})
+		}
+		paginationBlock := paginationBlocks[0]
+
+		// both would re-run the request, the pagination block replaces the hand written loop
+		if p.LoopConfig != nil {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The loop and pagination blocks can not both be set for step http",
+				Subject:  &paginationBlock.DefRange,
+			})
+		}
+
+		paginationContent, moreDiags := paginationBlock.Body.Content(PipelineHttpPaginationBlockSchema)
+		if moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		paginationConfig := NewHttpPaginationConfig(&p.PipelineStepBase)
+		moreDiags = paginationConfig.SetAttributes(paginationBlock, paginationContent.Attributes, evalContext)
+		if len(moreDiags) > 0 {
+			return append(diags, moreDiags...)
+		}
+		p.PaginationConfig = paginationConfig
+	}
+
 	return diags
 }
 
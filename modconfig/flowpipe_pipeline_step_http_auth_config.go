@@ -0,0 +1,390 @@
+package modconfig
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	HttpAuthTypeOAuth2   = "oauth2"
+	HttpAuthTypeBearer   = "bearer"
+	HttpAuthTypeAwsSigV4 = "aws_sigv4"
+	HttpAuthTypeMtls     = "mtls"
+)
+
+var ValidHttpAuthTypes = []string{
+	HttpAuthTypeOAuth2,
+	HttpAuthTypeBearer,
+	HttpAuthTypeAwsSigV4,
+	HttpAuthTypeMtls,
+}
+
+// the attributes required and supported by each auth type, type is supported by all
+var httpAuthRequiredAttributes = map[string][]string{
+	HttpAuthTypeOAuth2:   {schema.AttributeTypeTokenUrl, schema.AttributeTypeClientId, schema.AttributeTypeClientSecret},
+	HttpAuthTypeBearer:   {schema.AttributeTypeToken},
+	HttpAuthTypeAwsSigV4: {schema.AttributeTypeConnection, schema.AttributeTypeRegion, schema.AttributeTypeService},
+	HttpAuthTypeMtls:     {schema.AttributeTypeClientCertPem, schema.AttributeTypeClientKeyPem},
+}
+
+var httpAuthOptionalAttributes = map[string][]string{
+	HttpAuthTypeOAuth2: {schema.AttributeTypeScopes, schema.AttributeTypeAudience},
+}
+
+// the attributes of the aws connection passed to the http step to sign the request
+var httpAuthAwsConnectionAttributes = []string{"access_key", "secret_key", "session_token"}
+
+func NewHttpAuthConfig(p *PipelineStepBase) *HttpAuthConfig {
+	return &HttpAuthConfig{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// HttpAuthConfig is the "auth" block of the http step.
+//
+// The json field names of the secrets match the sanitizer excluded fields so they are redacted from the logs and
+// the step inputs.
+type HttpAuthConfig struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	Type string `json:"type"`
+
+	// oauth2 client credentials
+	TokenUrl     *string  `json:"token_url,omitempty"`
+	ClientId     *string  `json:"client_id,omitempty"`
+	ClientSecret *string  `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Audience     *string  `json:"audience,omitempty"`
+
+	// bearer
+	Token *string `json:"token,omitempty"`
+
+	// aws_sigv4, the connection is always resolved at runtime
+	Region  *string `json:"region,omitempty"`
+	Service *string `json:"service,omitempty"`
+
+	// mtls
+	ClientCertPem *string `json:"client_cert_pem,omitempty"`
+	ClientKeyPem  *string `json:"client_key_pem,omitempty"`
+}
+
+func (a *HttpAuthConfig) AppendDependsOn(dependsOn ...string) {
+	a.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (a *HttpAuthConfig) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	a.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (a *HttpAuthConfig) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	a.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (a *HttpAuthConfig) GetPipeline() *Pipeline {
+	return a.PipelineStepBase.GetPipeline()
+}
+
+func (a *HttpAuthConfig) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	a.UnresolvedAttributes[name] = expr
+}
+
+func (a *HttpAuthConfig) SetAttributes(authBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	// the type decides which attributes are allowed so it must be read first
+	typeAttr := hclAttributes[schema.AttributeTypeType]
+	if typeAttr == nil {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   "The argument 'type' is required, but no definition was found.",
+			Subject:  &authBlock.DefRange,
+		}}
+	}
+
+	authType, moreDiags := hclhelpers.AttributeToString(typeAttr, evalContext, false)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return moreDiags
+	}
+
+	if !slices.Contains(ValidHttpAuthTypes, *authType) {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid auth type: " + *authType,
+			Detail:   "The auth type must be one of: " + strings.Join(ValidHttpAuthTypes, ","),
+			Subject:  &typeAttr.Range,
+		}}
+	}
+	a.Type = *authType
+
+	required := httpAuthRequiredAttributes[a.Type]
+	optional := httpAuthOptionalAttributes[a.Type]
+
+	for _, name := range required {
+		if hclAttributes[name] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required argument",
+				Detail:   "The argument '" + name + "' is required for auth type " + a.Type + ", but no definition was found.",
+				Subject:  &authBlock.DefRange,
+			})
+		}
+	}
+
+	for name, attr := range hclAttributes {
+		if name == schema.AttributeTypeType {
+			continue
+		}
+
+		if !slices.Contains(required, name) && !slices.Contains(optional, name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute " + name + " for auth type " + a.Type,
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		switch name {
+		case schema.AttributeTypeConnection:
+			// always resolved at runtime, the connection values are not available when the pipeline is parsed
+			a.AddUnresolvedAttribute(name, attr.Expr)
+
+			dependsOn, credentialDependsOn, connectionDependsOn := allDependsOnFromVariables(attr.Expr.Variables())
+			a.AppendDependsOn(dependsOn...)
+			a.AppendCredentialDependsOn(credentialDependsOn...)
+			a.AppendConnectionDependsOn(connectionDependsOn...)
+
+		case schema.AttributeTypeScopes:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, a)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				scopes, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse " + schema.AttributeTypeScopes + " attribute to list of strings",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				a.Scopes = scopes
+			}
+
+		default:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, a)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				strVal, err := hclhelpers.CtyToString(val)
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unable to parse " + name + " attribute to string",
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+				a.setStringField(name, &strVal)
+			}
+		}
+	}
+
+	return diags
+}
+
+func (a *HttpAuthConfig) setStringField(name string, val *string) {
+	switch name {
+	case schema.AttributeTypeTokenUrl:
+		a.TokenUrl = val
+	case schema.AttributeTypeClientId:
+		a.ClientId = val
+	case schema.AttributeTypeClientSecret:
+		a.ClientSecret = val
+	case schema.AttributeTypeAudience:
+		a.Audience = val
+	case schema.AttributeTypeToken:
+		a.Token = val
+	case schema.AttributeTypeRegion:
+		a.Region = val
+	case schema.AttributeTypeService:
+		a.Service = val
+	case schema.AttributeTypeClientCertPem:
+		a.ClientCertPem = val
+	case schema.AttributeTypeClientKeyPem:
+		a.ClientKeyPem = val
+	}
+}
+
+func (a *HttpAuthConfig) getStringField(name string) *string {
+	switch name {
+	case schema.AttributeTypeTokenUrl:
+		return a.TokenUrl
+	case schema.AttributeTypeClientId:
+		return a.ClientId
+	case schema.AttributeTypeClientSecret:
+		return a.ClientSecret
+	case schema.AttributeTypeAudience:
+		return a.Audience
+	case schema.AttributeTypeToken:
+		return a.Token
+	case schema.AttributeTypeRegion:
+		return a.Region
+	case schema.AttributeTypeService:
+		return a.Service
+	case schema.AttributeTypeClientCertPem:
+		return a.ClientCertPem
+	case schema.AttributeTypeClientKeyPem:
+		return a.ClientKeyPem
+	}
+	return nil
+}
+
+// GetInputs resolves the auth block and returns it as the "auth" input of the http step. For aws_sigv4 the access
+// key, secret key and session token of the connection are returned.
+func (a *HttpAuthConfig) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, hcl.Diagnostics) {
+	inputs := map[string]interface{}{
+		schema.AttributeTypeType: a.Type,
+	}
+
+	names := append(slices.Clone(httpAuthRequiredAttributes[a.Type]), httpAuthOptionalAttributes[a.Type]...)
+	for _, name := range names {
+		expr := a.UnresolvedAttributes[name]
+
+		switch name {
+		case schema.AttributeTypeConnection:
+			if expr == nil {
+				continue
+			}
+
+			val, diags := expr.Value(evalContext)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+
+			if val.IsNull() || !val.Type().IsObjectType() {
+				return nil, hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "The " + schema.AttributeTypeConnection + " attribute of the auth block must be an aws connection",
+					Subject:  expr.Range().Ptr(),
+				}}
+			}
+
+			for _, connAttr := range httpAuthAwsConnectionAttributes {
+				if !val.Type().HasAttribute(connAttr) {
+					continue
+				}
+				connVal := val.GetAttr(connAttr)
+				if connVal.IsNull() || connVal.Type() != cty.String {
+					continue
+				}
+				inputs[connAttr] = connVal.AsString()
+			}
+
+		case schema.AttributeTypeScopes:
+			if expr == nil {
+				if a.Scopes != nil {
+					inputs[name] = a.Scopes
+				}
+				continue
+			}
+
+			val, diags := expr.Value(evalContext)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+
+			scopes, err := hclhelpers.CtyToGoStringSlice(val, val.Type())
+			if err != nil {
+				return nil, hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + schema.AttributeTypeScopes + " attribute to list of strings",
+					Subject:  expr.Range().Ptr(),
+				}}
+			}
+			inputs[name] = scopes
+
+		default:
+			if expr == nil {
+				if val := a.getStringField(name); val != nil {
+					inputs[name] = *val
+				}
+				continue
+			}
+
+			val, diags := expr.Value(evalContext)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+
+			strVal, err := hclhelpers.CtyToString(val)
+			if err != nil {
+				return nil, hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to string",
+					Subject:  expr.Range().Ptr(),
+				}}
+			}
+			inputs[name] = strVal
+		}
+	}
+
+	return inputs, hcl.Diagnostics{}
+}
+
+func (a *HttpAuthConfig) Equals(other *HttpAuthConfig) bool {
+	if a == nil && other == nil {
+		return true
+	}
+
+	if a == nil && other != nil || a != nil && other == nil {
+		return false
+	}
+
+	if len(a.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for key, expr := range a.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[key]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	// and reverse
+	for key := range other.UnresolvedAttributes {
+		if _, ok := a.UnresolvedAttributes[key]; !ok {
+			return false
+		}
+	}
+
+	return a.Type == other.Type &&
+		utils.PtrEqual(a.TokenUrl, other.TokenUrl) &&
+		utils.PtrEqual(a.ClientId, other.ClientId) &&
+		utils.PtrEqual(a.ClientSecret, other.ClientSecret) &&
+		reflect.DeepEqual(a.Scopes, other.Scopes) &&
+		utils.PtrEqual(a.Audience, other.Audience) &&
+		utils.PtrEqual(a.Token, other.Token) &&
+		utils.PtrEqual(a.Region, other.Region) &&
+		utils.PtrEqual(a.Service, other.Service) &&
+		utils.PtrEqual(a.ClientCertPem, other.ClientCertPem) &&
+		utils.PtrEqual(a.ClientKeyPem, other.ClientKeyPem)
+}
@@ -0,0 +1,702 @@
+package modconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	HttpPaginationTypeLinkHeader = "link_header"
+	HttpPaginationTypeCursor     = "cursor"
+	HttpPaginationTypePage       = "page"
+	HttpPaginationTypeOffset     = "offset"
+
+	// DefaultHttpPaginationMaxPages is the maximum number of pages fetched when max_pages is not set
+	DefaultHttpPaginationMaxPages = 100
+)
+
+var ValidHttpPaginationTypes = []string{
+	HttpPaginationTypeLinkHeader,
+	HttpPaginationTypeCursor,
+	HttpPaginationTypePage,
+	HttpPaginationTypeOffset,
+}
+
+// the attributes required and supported by each pagination type, type, items_path and max_pages are supported by all
+var httpPaginationRequiredAttributes = map[string][]string{
+	HttpPaginationTypeLinkHeader: {},
+	HttpPaginationTypeCursor:     {schema.AttributeTypeCursorPath, schema.AttributeTypeQueryParam},
+	HttpPaginationTypePage:       {schema.AttributeTypeQueryParam},
+	HttpPaginationTypeOffset:     {schema.AttributeTypeQueryParam, schema.AttributeTypeIncrement},
+}
+
+var httpPaginationOptionalAttributes = map[string][]string{
+	HttpPaginationTypeLinkHeader: {schema.AttributeTypeItemsPath, schema.AttributeTypeMaxPages},
+	HttpPaginationTypeCursor:     {schema.AttributeTypeItemsPath, schema.AttributeTypeMaxPages},
+	HttpPaginationTypePage:       {schema.AttributeTypeItemsPath, schema.AttributeTypeMaxPages, schema.AttributeTypeStart, schema.AttributeTypeIncrement},
+	HttpPaginationTypeOffset:     {schema.AttributeTypeItemsPath, schema.AttributeTypeMaxPages, schema.AttributeTypeStart},
+}
+
+func NewHttpPaginationConfig(p *PipelineStepBase) *HttpPaginationConfig {
+	return &HttpPaginationConfig{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+// HttpPaginationConfig is the "pagination" block of the http step. The step fetches the pages one after the other
+// and its output aggregates the items of all the pages.
+//
+// The supported types are:
+//   - link_header: follows the rel="next" link of the Link response header (RFC 5988)
+//   - cursor: reads the next cursor from the response body (cursor_path) and sends it in the query_param
+//   - page: sends the page number in the query_param, starting at start (default 1) and incremented by increment (default 1)
+//   - offset: sends the offset in the query_param, starting at start (default 0) and incremented by increment (the page size)
+//
+// For the page and offset types the pagination stops at the first page without items.
+type HttpPaginationConfig struct {
+	// Circular reference to its parent
+	PipelineStepBase     *PipelineStepBase         `json:"-"`
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	Type       string  `json:"type"`
+	CursorPath *string `json:"cursor_path,omitempty"`
+	QueryParam *string `json:"query_param,omitempty"`
+	Start      *int64  `json:"start,omitempty"`
+	Increment  *int64  `json:"increment,omitempty"`
+	ItemsPath  *string `json:"items_path,omitempty"`
+	MaxPages   *int64  `json:"max_pages,omitempty"`
+}
+
+func (c *HttpPaginationConfig) AppendDependsOn(dependsOn ...string) {
+	c.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (c *HttpPaginationConfig) AppendCredentialDependsOn(credentialDependsOn ...string) {
+	c.PipelineStepBase.AppendCredentialDependsOn(credentialDependsOn...)
+}
+
+func (c *HttpPaginationConfig) AppendConnectionDependsOn(connectionDependsOn ...string) {
+	c.PipelineStepBase.AppendConnectionDependsOn(connectionDependsOn...)
+}
+
+func (c *HttpPaginationConfig) GetPipeline() *Pipeline {
+	return c.PipelineStepBase.GetPipeline()
+}
+
+func (c *HttpPaginationConfig) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	c.UnresolvedAttributes[name] = expr
+}
+
+func (c *HttpPaginationConfig) Equals(other *HttpPaginationConfig) bool {
+	if c == nil && other == nil {
+		return true
+	}
+
+	if c == nil && other != nil || c != nil && other == nil {
+		return false
+	}
+
+	if len(c.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for name, expr := range c.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[name]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	return c.Type == other.Type &&
+		utils.PtrEqual(c.CursorPath, other.CursorPath) &&
+		utils.PtrEqual(c.QueryParam, other.QueryParam) &&
+		utils.PtrEqual(c.Start, other.Start) &&
+		utils.PtrEqual(c.Increment, other.Increment) &&
+		utils.PtrEqual(c.ItemsPath, other.ItemsPath) &&
+		utils.PtrEqual(c.MaxPages, other.MaxPages)
+}
+
+func (c *HttpPaginationConfig) SetAttributes(paginationBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	// the type decides which attributes are allowed so it must be read first
+	typeAttr := hclAttributes[schema.AttributeTypeType]
+	if typeAttr == nil {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required argument",
+			Detail:   "The argument 'type' is required, but no definition was found.",
+			Subject:  &paginationBlock.DefRange,
+		}}
+	}
+
+	paginationType, moreDiags := hclhelpers.AttributeToString(typeAttr, evalContext, false)
+	if moreDiags != nil && moreDiags.HasErrors() {
+		return moreDiags
+	}
+
+	if !slices.Contains(ValidHttpPaginationTypes, *paginationType) {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid pagination type: " + *paginationType,
+			Detail:   "The pagination type must be one of: " + strings.Join(ValidHttpPaginationTypes, ","),
+			Subject:  &typeAttr.Range,
+		}}
+	}
+	c.Type = *paginationType
+
+	required := httpPaginationRequiredAttributes[c.Type]
+	optional := httpPaginationOptionalAttributes[c.Type]
+
+	for _, name := range required {
+		if hclAttributes[name] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required argument",
+				Detail:   "The argument '" + name + "' is required for pagination type " + c.Type + ", but no definition was found.",
+				Subject:  &paginationBlock.DefRange,
+			})
+		}
+	}
+
+	for name, attr := range hclAttributes {
+		if name == schema.AttributeTypeType {
+			continue
+		}
+
+		if !slices.Contains(required, name) && !slices.Contains(optional, name) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute " + name + " for pagination type " + c.Type,
+				Subject:  &attr.Range,
+			})
+			continue
+		}
+
+		val, stepDiags := dependsOnFromExpressions(attr, evalContext, c)
+		if stepDiags.HasErrors() {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+
+		if val == cty.NilVal {
+			continue
+		}
+
+		diags = append(diags, c.setField(name, val, attr.Range.Ptr())...)
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	return c.validate(&paginationBlock.DefRange)
+}
+
+func (c *HttpPaginationConfig) setField(name string, val cty.Value, subject *hcl.Range) hcl.Diagnostics {
+	switch name {
+	case schema.AttributeTypeStart, schema.AttributeTypeIncrement, schema.AttributeTypeMaxPages:
+		intVal, diags := hclhelpers.CtyToInt64(val)
+		if diags.HasErrors() {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + name + " attribute to integer",
+				Subject:  subject,
+			}}
+		}
+
+		switch name {
+		case schema.AttributeTypeStart:
+			c.Start = intVal
+		case schema.AttributeTypeIncrement:
+			c.Increment = intVal
+		case schema.AttributeTypeMaxPages:
+			c.MaxPages = intVal
+		}
+
+	default:
+		strVal, err := hclhelpers.CtyToString(val)
+		if err != nil {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + name + " attribute to string",
+				Subject:  subject,
+			}}
+		}
+
+		switch name {
+		case schema.AttributeTypeCursorPath:
+			c.CursorPath = &strVal
+		case schema.AttributeTypeQueryParam:
+			c.QueryParam = &strVal
+		case schema.AttributeTypeItemsPath:
+			c.ItemsPath = &strVal
+		}
+	}
+
+	return hcl.Diagnostics{}
+}
+
+// Validate checks the resolved attributes, the attributes which are only resolved at runtime are checked by Resolve
+func (c *HttpPaginationConfig) Validate() hcl.Diagnostics {
+	var subject *hcl.Range
+	if c.PipelineStepBase != nil {
+		subject = c.PipelineStepBase.GetRange()
+	}
+	return c.validate(subject)
+}
+
+func (c *HttpPaginationConfig) validate(subject *hcl.Range) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, path := range map[string]*string{schema.AttributeTypeCursorPath: c.CursorPath, schema.AttributeTypeItemsPath: c.ItemsPath} {
+		if path == nil {
+			continue
+		}
+		if _, err := parseJsonPath(*path); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + name + ": " + *path,
+				Detail:   err.Error(),
+				Subject:  subject,
+			})
+		}
+	}
+
+	if c.QueryParam != nil && *c.QueryParam == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The " + schema.AttributeTypeQueryParam + " attribute of the pagination block must not be empty",
+			Subject:  subject,
+		})
+	}
+
+	if c.Start != nil && *c.Start < 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The " + schema.AttributeTypeStart + " attribute of the pagination block must not be negative",
+			Subject:  subject,
+		})
+	}
+
+	if c.Increment != nil && *c.Increment <= 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The " + schema.AttributeTypeIncrement + " attribute of the pagination block must be greater than 0",
+			Subject:  subject,
+		})
+	}
+
+	if c.MaxPages != nil && *c.MaxPages <= 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The " + schema.AttributeTypeMaxPages + " attribute of the pagination block must be greater than 0",
+			Subject:  subject,
+		})
+	}
+
+	return diags
+}
+
+// Resolve returns a copy of the pagination config with the attributes which could not be resolved when the pipeline
+// was parsed resolved with the given eval context
+func (c *HttpPaginationConfig) Resolve(evalContext *hcl.EvalContext) (*HttpPaginationConfig, hcl.Diagnostics) {
+	resolved := &HttpPaginationConfig{
+		PipelineStepBase:     c.PipelineStepBase,
+		UnresolvedAttributes: map[string]hcl.Expression{},
+		Type:                 c.Type,
+		CursorPath:           c.CursorPath,
+		QueryParam:           c.QueryParam,
+		Start:                c.Start,
+		Increment:            c.Increment,
+		ItemsPath:            c.ItemsPath,
+		MaxPages:             c.MaxPages,
+	}
+
+	for name, expr := range c.UnresolvedAttributes {
+		val, diags := expr.Value(evalContext)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		if val.IsNull() {
+			continue
+		}
+
+		diags = resolved.setField(name, val, expr.Range().Ptr())
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	diags := resolved.Validate()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return resolved, diags
+}
+
+// GetInputs resolves the pagination block and returns it as the "pagination" input of the http step
+func (c *HttpPaginationConfig) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, hcl.Diagnostics) {
+	resolved, diags := c.Resolve(evalContext)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	inputs := map[string]interface{}{
+		schema.AttributeTypeType:     resolved.Type,
+		schema.AttributeTypeMaxPages: resolved.GetMaxPages(),
+	}
+
+	if resolved.CursorPath != nil {
+		inputs[schema.AttributeTypeCursorPath] = *resolved.CursorPath
+	}
+	if resolved.QueryParam != nil {
+		inputs[schema.AttributeTypeQueryParam] = *resolved.QueryParam
+	}
+	if resolved.ItemsPath != nil {
+		inputs[schema.AttributeTypeItemsPath] = *resolved.ItemsPath
+	}
+	if resolved.Type == HttpPaginationTypePage || resolved.Type == HttpPaginationTypeOffset {
+		inputs[schema.AttributeTypeStart] = resolved.GetStart()
+		inputs[schema.AttributeTypeIncrement] = resolved.GetIncrement()
+	}
+
+	return inputs, diags
+}
+
+// GetMaxPages returns the maximum number of pages to fetch
+func (c *HttpPaginationConfig) GetMaxPages() int64 {
+	if c.MaxPages == nil {
+		return DefaultHttpPaginationMaxPages
+	}
+	return *c.MaxPages
+}
+
+// GetStart returns the page number or offset of the first page
+func (c *HttpPaginationConfig) GetStart() int64 {
+	if c.Start != nil {
+		return *c.Start
+	}
+	if c.Type == HttpPaginationTypePage {
+		return 1
+	}
+	return 0
+}
+
+// GetIncrement returns the increment of the page number or offset between two pages
+func (c *HttpPaginationConfig) GetIncrement() int64 {
+	if c.Increment == nil {
+		return 1
+	}
+	return *c.Increment
+}
+
+// HttpPage is a page fetched by the http step, the body is the decoded JSON response body
+type HttpPage struct {
+	Url    string
+	Header http.Header
+	Body   interface{}
+}
+
+// HttpPageFetcher fetches a page of a paginated http step
+type HttpPageFetcher func(ctx context.Context, url string) (*HttpPage, error)
+
+// HttpPaginationOutput is the aggregated output of a paginated http step. Truncated is set when max_pages was
+// reached while there were more pages.
+type HttpPaginationOutput struct {
+	Items     []interface{} `json:"items"`
+	Pages     int           `json:"pages"`
+	Truncated bool          `json:"truncated"`
+}
+
+// Paginate fetches the pages, starting with the request url, and aggregates their items. The config must be resolved.
+func (c *HttpPaginationConfig) Paginate(ctx context.Context, requestUrl string, fetch HttpPageFetcher) (*HttpPaginationOutput, error) {
+	output := &HttpPaginationOutput{
+		Items: []interface{}{},
+	}
+
+	nextUrl, err := c.FirstUrl(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPages := c.GetMaxPages()
+	for nextUrl != "" {
+		if int64(output.Pages) >= maxPages {
+			output.Truncated = true
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page, err := fetch(ctx, nextUrl)
+		if err != nil {
+			return nil, err
+		}
+		if page.Url == "" {
+			page.Url = nextUrl
+		}
+
+		items, err := c.Items(page.Body)
+		if err != nil {
+			return nil, err
+		}
+		output.Items = append(output.Items, items...)
+		output.Pages++
+
+		pageUrl := page.Url
+		nextUrl, err = c.NextUrl(page, output.Pages, len(items))
+		if err != nil {
+			return nil, err
+		}
+
+		// guard against an API returning the same page forever
+		if nextUrl == pageUrl {
+			break
+		}
+	}
+
+	return output, nil
+}
+
+// FirstUrl returns the url of the first page, for the page and offset types the start value is added to the request url
+func (c *HttpPaginationConfig) FirstUrl(requestUrl string) (string, error) {
+	switch c.Type {
+	case HttpPaginationTypePage, HttpPaginationTypeOffset:
+		return setUrlQueryParam(requestUrl, c.queryParam(), strconv.FormatInt(c.GetStart(), 10))
+	}
+	return requestUrl, nil
+}
+
+// NextUrl returns the url of the page following the given page, or an empty string if it is the last page.
+// pagesFetched is the number of pages fetched so far and itemCount the number of items in the given page.
+func (c *HttpPaginationConfig) NextUrl(page *HttpPage, pagesFetched int, itemCount int) (string, error) {
+	switch c.Type {
+	case HttpPaginationTypeLinkHeader:
+		next := nextLinkFromHeader(page.Header.Values("Link"))
+		if next == "" {
+			return "", nil
+		}
+
+		base, err := url.Parse(page.Url)
+		if err != nil {
+			return "", perr.BadRequestWithMessage("invalid url " + page.Url + ": " + err.Error())
+		}
+		nextUrl, err := base.Parse(next)
+		if err != nil {
+			return "", perr.BadRequestWithMessage("invalid next link " + next + ": " + err.Error())
+		}
+		return nextUrl.String(), nil
+
+	case HttpPaginationTypeCursor:
+		if c.CursorPath == nil {
+			return "", nil
+		}
+
+		cursor, found, err := lookupJsonPath(page.Body, *c.CursorPath)
+		if err != nil {
+			return "", err
+		}
+		if !found || cursor == nil {
+			return "", nil
+		}
+
+		cursorStr := jsonScalarToString(cursor)
+		if cursorStr == "" {
+			return "", nil
+		}
+		return setUrlQueryParam(page.Url, c.queryParam(), cursorStr)
+
+	case HttpPaginationTypePage, HttpPaginationTypeOffset:
+		if itemCount == 0 {
+			return "", nil
+		}
+
+		next := c.GetStart() + int64(pagesFetched)*c.GetIncrement()
+		return setUrlQueryParam(page.Url, c.queryParam(), strconv.FormatInt(next, 10))
+	}
+
+	return "", perr.BadRequestWithMessage("unsupported pagination type: " + c.Type)
+}
+
+// Items returns the items of a page, the value at items_path or the whole body. A list is flattened into its elements.
+func (c *HttpPaginationConfig) Items(body interface{}) ([]interface{}, error) {
+	value := body
+	if c.ItemsPath != nil {
+		found := false
+		var err error
+		value, found, err = lookupJsonPath(body, *c.ItemsPath)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return []interface{}{}, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case []interface{}:
+		return v, nil
+	}
+	return []interface{}{value}, nil
+}
+
+func (c *HttpPaginationConfig) queryParam() string {
+	if c.QueryParam == nil {
+		return ""
+	}
+	return *c.QueryParam
+}
+
+// nextLinkFromHeader returns the target of the rel="next" link of the Link header values, e.g.
+// <https://api.github.com/repositories/1300192/issues?page=4>; rel="next", <...>; rel="last"
+func nextLinkFromHeader(values []string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+
+				// rel may hold several space separated relation types
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func setUrlQueryParam(rawUrl, name, value string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", perr.BadRequestWithMessage("invalid url " + rawUrl + ": " + err.Error())
+	}
+
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func jsonScalarToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// parseJsonPath parses the subset of JSONPath used to locate a value in a response body: a path starting with $
+// followed by .name, ['name'] or [index] segments, e.g. $.meta.next_cursor or $.data[0]['id']. A segment is either
+// a string (object key) or an int (list index).
+func parseJsonPath(path string) ([]interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("the path must start with $")
+	}
+
+	var segments []interface{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty name in path")
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated [ in path")
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, inner[1:len(inner)-1])
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %s in path", inner)
+			}
+			segments = append(segments, index)
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q in path", rest[0])
+		}
+	}
+
+	return segments, nil
+}
+
+// lookupJsonPath returns the value at the path in the decoded JSON body, found is false if the path does not exist
+func lookupJsonPath(body interface{}, path string) (value interface{}, found bool, err error) {
+	segments, err := parseJsonPath(path)
+	if err != nil {
+		return nil, false, perr.BadRequestWithMessage("invalid path " + path + ": " + err.Error())
+	}
+
+	value = body
+	for _, segment := range segments {
+		switch s := segment.(type) {
+		case string:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if value, ok = obj[s]; !ok {
+				return nil, false, nil
+			}
+		case int:
+			list, ok := value.([]interface{})
+			if !ok || s >= len(list) {
+				return nil, false, nil
+			}
+			value = list[s]
+		}
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,196 @@
+package modconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/utils"
+)
+
+// testItemsServer serves 7 items, 3 per page, with the pagination style selected by the request path
+func testItemsServer() *httptest.Server {
+	const total = 7
+	const pageSize = 3
+
+	items := func(offset int) []int {
+		var res []int
+		for i := offset; i < total && i < offset+pageSize; i++ {
+			res = append(res, i)
+		}
+		return res
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if (page+1)*pageSize < total {
+			w.Header().Add("Link", fmt.Sprintf(`</link?page=%d>; rel="next", </link?page=2>; rel="last"`, page+1))
+		}
+		_ = json.NewEncoder(w).Encode(items(page * pageSize))
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		next := ""
+		if offset+pageSize < total {
+			next = strconv.Itoa(offset + pageSize)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": items(offset),
+			"meta": map[string]interface{}{"next": next},
+		})
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": items((page - 1) * pageSize)})
+	})
+	mux.HandleFunc("/offset", func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		_ = json.NewEncoder(w).Encode(items(offset))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func testFetcher(client *http.Client) HttpPageFetcher {
+	return func(ctx context.Context, url string) (*HttpPage, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var body interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		return &HttpPage{Url: url, Header: resp.Header, Body: body}, nil
+	}
+}
+
+func TestHttpPaginationConfig_Paginate(t *testing.T) {
+	server := testItemsServer()
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		config        *HttpPaginationConfig
+		wantItems     int
+		wantPages     int
+		wantTruncated bool
+	}{
+		{
+			name:      "link header",
+			path:      "/link",
+			config:    &HttpPaginationConfig{Type: HttpPaginationTypeLinkHeader},
+			wantItems: 7,
+			wantPages: 3,
+		},
+		{
+			name: "cursor",
+			path: "/cursor",
+			config: &HttpPaginationConfig{
+				Type:       HttpPaginationTypeCursor,
+				CursorPath: utils.ToPointer("$.meta.next"),
+				QueryParam: utils.ToPointer("cursor"),
+				ItemsPath:  utils.ToPointer("$.data"),
+			},
+			wantItems: 7,
+			wantPages: 3,
+		},
+		{
+			name: "page",
+			path: "/page",
+			config: &HttpPaginationConfig{
+				Type:       HttpPaginationTypePage,
+				QueryParam: utils.ToPointer("page"),
+				ItemsPath:  utils.ToPointer("$['data']"),
+			},
+			wantItems: 7,
+			// the last page is empty
+			wantPages: 4,
+		},
+		{
+			name: "offset",
+			path: "/offset",
+			config: &HttpPaginationConfig{
+				Type:       HttpPaginationTypeOffset,
+				QueryParam: utils.ToPointer("offset"),
+				Increment:  utils.ToPointer(int64(3)),
+			},
+			wantItems: 7,
+			wantPages: 4,
+		},
+		{
+			name: "max pages",
+			path: "/link",
+			config: &HttpPaginationConfig{
+				Type:     HttpPaginationTypeLinkHeader,
+				MaxPages: utils.ToPointer(int64(2)),
+			},
+			wantItems:     6,
+			wantPages:     2,
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.config.Paginate(context.Background(), server.URL+tt.path, testFetcher(server.Client()))
+			if err != nil {
+				t.Fatalf("Paginate() error = %v", err)
+			}
+
+			if len(output.Items) != tt.wantItems {
+				t.Errorf("Paginate() items = %v, want %d items", output.Items, tt.wantItems)
+			}
+			if output.Pages != tt.wantPages {
+				t.Errorf("Paginate() pages = %d, want %d", output.Pages, tt.wantPages)
+			}
+			if output.Truncated != tt.wantTruncated {
+				t.Errorf("Paginate() truncated = %v, want %v", output.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestNextLinkFromHeader(t *testing.T) {
+	tests := map[string]string{
+		`<https://api.github.com/repositories/1300192/issues?page=2>; rel="prev", <https://api.github.com/repositories/1300192/issues?page=4>; rel="next"`: "https://api.github.com/repositories/1300192/issues?page=4",
+		`</items?page=2>; rel="next last"`:    "/items?page=2",
+		`<https://example.com/a>; rel=next`:   "https://example.com/a",
+		`<https://example.com/a>; rel="last"`: "",
+		``:                                    "",
+	}
+
+	for header, want := range tests {
+		if got := nextLinkFromHeader([]string{header}); got != want {
+			t.Errorf("nextLinkFromHeader(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestParseJsonPath(t *testing.T) {
+	valid := []string{"$", "$.data", "$.meta.next_cursor", "$['data'][0].id", `$["a b"]`}
+	for _, path := range valid {
+		if _, err := parseJsonPath(path); err != nil {
+			t.Errorf("parseJsonPath(%q) error = %v", path, err)
+		}
+	}
+
+	invalid := []string{"data", "$.", "$[", "$[-1]", "$..data", "$data"}
+	for _, path := range invalid {
+		if _, err := parseJsonPath(path); err == nil {
+			t.Errorf("parseJsonPath(%q) expected an error", path)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package modconfig
 
 import (
+	"reflect"
+	"slices"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/turbot/go-kit/helpers"
@@ -20,6 +23,19 @@ type PipelineStepInput struct {
 	Prompt     *string `json:"prompt" cty:"prompt"`
 	OptionList []PipelineStepInputOption
 
+	// per type attributes: min and max for number, pattern for text, confirm_phrase for confirm
+	Min           *float64 `json:"min,omitempty" cty:"min"`
+	Max           *float64 `json:"max,omitempty" cty:"max"`
+	Pattern       *string  `json:"pattern,omitempty" cty:"pattern"`
+	Placeholder   *string  `json:"placeholder,omitempty" cty:"placeholder"`
+	ConfirmPhrase *string  `json:"confirm_phrase,omitempty" cty:"confirm_phrase"`
+
+	// Validation is a bool expression of the response, available as "value", evaluated when the response is received
+	Validation hcl.Expression `json:"-" cty:"-"`
+
+	// TimeoutConfig holds the reminders, escalation and timeout action of the step
+	TimeoutConfig *InputTimeoutConfig `json:"timeout_config,omitempty" cty:"-"`
+
 	// Notifier cty.Value `json:"-" cty:"notify"`
 	Notifier NotifierImpl `json:"notify" cty:"-"`
 
@@ -29,6 +45,11 @@ type PipelineStepInput struct {
 	Channel *string  `json:"channel,omitempty" cty:"channel" hcl:"channel,optional"`
 	Subject *string  `json:"subject,omitempty" cty:"subject" hcl:"subject,optional"`
 	To      []string `json:"to,omitempty" cty:"to" hcl:"to,optional"`
+
+	// Color of the rich message of the prompt, as a #RRGGBB hex colour
+	Color *string `json:"color,omitempty" cty:"color" hcl:"color,optional"`
+	// Sections of the rich message, after the prompt
+	Sections []RichMessageSection `json:"sections,omitempty" cty:"-"`
 }
 
 func (p *PipelineStepInput) Equals(other PipelineStep) bool {
@@ -60,9 +81,22 @@ func (p *PipelineStepInput) Equals(other PipelineStep) bool {
 		}
 	}
 
+	if (p.Validation == nil) != (pOther.Validation == nil) {
+		return false
+	}
+
+	if p.Validation != nil && !hclhelpers.ExpressionsEqual(p.Validation, pOther.Validation) {
+		return false
+	}
+
 	return p.Name == other.GetName() &&
 		p.InputType == pOther.InputType &&
 		utils.PtrEqual(p.Prompt, pOther.Prompt) &&
+		utils.PtrEqual(p.Min, pOther.Min) &&
+		utils.PtrEqual(p.Max, pOther.Max) &&
+		utils.PtrEqual(p.Pattern, pOther.Pattern) &&
+		utils.PtrEqual(p.Placeholder, pOther.Placeholder) &&
+		utils.PtrEqual(p.ConfirmPhrase, pOther.ConfirmPhrase) &&
 		helpers.StringSliceEqualIgnoreOrder(p.Cc, pOther.Cc) &&
 		helpers.StringSliceEqualIgnoreOrder(p.Bcc, pOther.Bcc) &&
 		utils.PtrEqual(p.Channel, pOther.Channel) &&
@@ -70,7 +104,10 @@ func (p *PipelineStepInput) Equals(other PipelineStep) bool {
 		utils.PtrEqual(p.Subject, pOther.Subject) &&
 		utils.PtrEqual(p.Title, pOther.Title) &&
 		helpers.StringSliceEqualIgnoreOrder(p.To, pOther.To) &&
-		p.Notifier.Equals(&pOther.Notifier)
+		utils.PtrEqual(p.Color, pOther.Color) &&
+		reflect.DeepEqual(p.Sections, pOther.Sections) &&
+		p.Notifier.Equals(&pOther.Notifier) &&
+		p.TimeoutConfig.Equals(pOther.TimeoutConfig)
 
 }
 
@@ -86,6 +123,40 @@ func (p *PipelineStepInput) GetInputs(evalContext *hcl.EvalContext) (map[string]
 		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
 	}
 
+	// min
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeMin, p.Min)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// max
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeMax, p.Max)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// pattern
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypePattern, p.Pattern)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// placeholder
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypePlaceholder, p.Placeholder)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	// confirm_phrase
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeConfirmPhrase, p.ConfirmPhrase)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	if err := validateInputTypeSettings(results); err != nil {
+		return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+	}
+
 	// channel
 	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeChannel, p.Channel)
 	if diags.HasErrors() {
@@ -116,8 +187,13 @@ func (p *PipelineStepInput) GetInputs(evalContext *hcl.EvalContext) (map[string]
 		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
 	}
 
+	// color and sections
+	results, err := richMessageInputs(p, results, evalContext, p.Color, p.Sections)
+	if err != nil {
+		return nil, err
+	}
+
 	// options
-	var err error
 	var resolvedOpts []PipelineStepInputOption
 
 	if p.UnresolvedAttributes[schema.AttributeTypeOptions] != nil {
@@ -169,7 +245,14 @@ func (p *PipelineStepInput) GetInputs(evalContext *hcl.EvalContext) (map[string]
 func (p *PipelineStepInput) SetAttributes(hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
 	diags := p.SetBaseAttributes(hclAttributes, evalContext)
 
+	timeoutAttributes := hcl.Attributes{}
+
 	for name, attr := range hclAttributes {
+		if slices.Contains(InputTimeoutAttributes, name) {
+			timeoutAttributes[name] = attr
+			continue
+		}
+
 		switch name {
 		case schema.AttributeTypeType:
 			stepDiags := setStringAttribute(attr, evalContext, p, "InputType", false)
@@ -178,7 +261,7 @@ func (p *PipelineStepInput) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				continue
 			}
 
-		case schema.AttributeTypePrompt, schema.AttributeTypeChannel, schema.AttributeTypeSubject:
+		case schema.AttributeTypePrompt, schema.AttributeTypeChannel, schema.AttributeTypeSubject, schema.AttributeTypePattern, schema.AttributeTypePlaceholder, schema.AttributeTypeColor:
 
 			structFieldName := utils.CapitalizeFirst(name)
 			stepDiags := setStringAttribute(attr, evalContext, p, structFieldName, true)
@@ -187,6 +270,49 @@ func (p *PipelineStepInput) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				continue
 			}
 
+		case schema.AttributeTypeConfirmPhrase:
+			stepDiags := setStringAttribute(attr, evalContext, p, "ConfirmPhrase", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case schema.AttributeTypeMin, schema.AttributeTypeMax:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val == cty.NilVal {
+				continue
+			}
+
+			if val.IsNull() || val.Type() != cty.Number {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute to number",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			number, _ := val.AsBigFloat().Float64()
+			if name == schema.AttributeTypeMin {
+				p.Min = &number
+			} else {
+				p.Max = &number
+			}
+
+		case schema.AttributeTypeValidation:
+			// the expression references the response, so it is only evaluated when the response is received
+			p.Validation = attr.Expr
+
+			dependsOn, credsDependsOn, connDependsOn := allDependsOnFromVariables(attr.Expr.Variables())
+			p.AppendDependsOn(dependsOn...)
+			p.AppendCredentialDependsOn(credsDependsOn...)
+			p.AppendConnectionDependsOn(connDependsOn...)
+
 		case schema.AttributeTypeCc, schema.AttributeTypeBcc, schema.AttributeTypeTo:
 			structFieldName := utils.CapitalizeFirst(name)
 			stepDiags := setStringSliceAttribute(attr, evalContext, p, structFieldName, false)
@@ -195,6 +321,14 @@ func (p *PipelineStepInput) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				continue
 			}
 
+		case schema.AttributeTypeSections:
+			sections, stepDiags := richMessageSectionsFromAttribute(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+			p.Sections = sections
+
 		case schema.AttributeTypeOptions:
 			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
 			if stepDiags.HasErrors() {
@@ -246,9 +380,23 @@ func (p *PipelineStepInput) SetAttributes(hclAttributes hcl.Attributes, evalCont
 		}
 	}
 
+	if len(timeoutAttributes) > 0 {
+		p.TimeoutConfig = NewInputTimeoutConfig(&p.PipelineStepBase)
+		diags = append(diags, p.TimeoutConfig.SetAttributes(timeoutAttributes, evalContext)...)
+	}
+
 	return diags
 }
 
+// GetTimeoutConfig returns the timeout config of the step with the attributes resolved, nil if it has none
+func (p *PipelineStepInput) GetTimeoutConfig(evalContext *hcl.EvalContext) (*InputTimeoutConfig, hcl.Diagnostics) {
+	if p.TimeoutConfig == nil {
+		return nil, hcl.Diagnostics{}
+	}
+
+	return p.TimeoutConfig.Resolve(evalContext)
+}
+
 func (p *PipelineStepInput) SetBlockConfig(blocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
 
 	diags := p.PipelineStepBase.SetBlockConfig(blocks, evalContext)
@@ -306,6 +454,95 @@ func (p *PipelineStepInput) Validate() hcl.Diagnostics {
 		})
 	}
 
+	// validate the per type attributes, an attribute resolved at runtime is only checked for its type
+	settings := map[string]interface{}{
+		schema.AttributeTypeType: p.InputType,
+	}
+	for name, value := range map[string]interface{}{
+		schema.AttributeTypeMin:           p.Min,
+		schema.AttributeTypeMax:           p.Max,
+		schema.AttributeTypePattern:       p.Pattern,
+		schema.AttributeTypePlaceholder:   p.Placeholder,
+		schema.AttributeTypeConfirmPhrase: p.ConfirmPhrase,
+	} {
+		if _, ok := p.UnresolvedAttributes[name]; ok {
+			settings[name] = nil
+		} else if !helpers.IsNil(value) {
+			settings[name] = reflect.ValueOf(value).Elem().Interface()
+		}
+	}
+
+	if constants.IsValidInputType(p.InputType) {
+		if err := validateInputTypeSettings(settings); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + p.InputType + " input: " + err.Error(),
+				Subject:  p.GetRange(),
+			})
+		}
+
+		if p.Validation != nil && constants.IsOptionInputType(p.InputType) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + p.InputType + " input: " + schema.AttributeTypeValidation + " can not be set for an input with options",
+				Subject:  p.Validation.Range().Ptr(),
+			})
+		}
+
+		_, unresolvedOptions := p.UnresolvedAttributes[schema.AttributeTypeOptions]
+		if (len(p.OptionList) > 0 || unresolvedOptions) && !constants.IsOptionInputType(p.InputType) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + p.InputType + " input: options can only be set for button, select and multiselect inputs",
+				Subject:  p.GetRange(),
+			})
+		}
+	}
+
+	if p.TimeoutConfig != nil {
+		diags = append(diags, p.TimeoutConfig.Validate()...)
+
+		_, unresolvedTimeout := p.UnresolvedAttributes[schema.AttributeTypeTimeout]
+		hasTimeout := p.Timeout != nil || unresolvedTimeout
+		if !hasTimeout && (p.TimeoutConfig.OnTimeout != nil || p.TimeoutConfig.DefaultValue != nil) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid input timeout: on_timeout and default_value require the timeout attribute",
+				Subject:  p.GetRange(),
+			})
+		}
+
+		// the value is used as the response, it must be a valid response when it is known at parse time
+		if p.TimeoutConfig.DefaultValue != nil && constants.IsValidInputType(p.InputType) && !constants.IsOptionInputType(p.InputType) {
+			if defaultValue, ok := p.TimeoutConfig.DefaultValue.(string); !ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid input timeout: default_value must be a string for " + p.InputType + " inputs",
+					Subject:  p.GetRange(),
+				})
+			} else if len(p.UnresolvedAttributes) == 0 {
+				settings[schema.AttributeTypeType] = p.InputType
+				if err := ValidateInputResponse(settings, defaultValue); err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid input timeout: default_value: " + err.Error(),
+						Subject:  p.GetRange(),
+					})
+				}
+			}
+		}
+
+		if timeout, err := InputDuration(p.Timeout); err == nil && p.TimeoutConfig.EscalationWindow != nil {
+			if window, err := InputDuration(p.TimeoutConfig.EscalationWindow); err == nil && window >= timeout {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid input timeout: escalation_window must be less than the timeout",
+					Subject:  p.GetRange(),
+				})
+			}
+		}
+	}
+
 	// check for and validate style on options
 	for _, o := range p.OptionList {
 		if !helpers.IsNil(o.Style) && !constants.IsValidInputStyleType(*o.Style) {
@@ -316,6 +553,8 @@ func (p *PipelineStepInput) Validate() hcl.Diagnostics {
 		}
 	}
 
+	diags = append(diags, validateRichMessageAttributes(p, p.Color, p.Sections)...)
+
 	return diags
 }
 
@@ -353,6 +592,15 @@ func ctyValueToPipelineStepNotifierValueMap(value cty.Value) (NotifierImpl, erro
 	if valueMap["short_name"] != cty.NilVal {
 		notifier.ShortName = valueMap["short_name"].AsString()
 	}
+	if v := valueMap[schema.AttributeTypeDedupeWindow]; v != cty.NilVal && !v.IsNull() {
+		notifier.DedupeWindow = utils.ToPointer(v.AsString())
+	}
+	if v := valueMap[schema.AttributeTypeDedupeKey]; v != cty.NilVal && !v.IsNull() {
+		notifier.DedupeKey = utils.ToPointer(v.AsString())
+	}
+	if v := valueMap[schema.BlockTypeRateLimit]; v != cty.NilVal {
+		notifier.RateLimit = ctyValueToNotifierRateLimit(v)
+	}
 
 	return notifier, nil
 }
@@ -415,6 +663,14 @@ func ctyValueToNotify(val cty.Value) (Notify, error) {
 		}
 	}
 
+	if match := valMap[schema.BlockTypeMatch]; match != cty.NilVal {
+		var err error
+		n.Match, err = ctyValueToNotifyMatch(match)
+		if err != nil {
+			return n, err
+		}
+	}
+
 	integration := valMap["integration"]
 
 	if integration != cty.NilVal {
@@ -606,6 +862,24 @@ func CtyValueToPipelineStepInputOptionList(value cty.Value) ([]PipelineStepInput
 	opts := value.AsValueSlice()
 
 	for _, opt := range opts {
+		// a list of strings, such as a column of the rows of a query step, sets the value and label of each option
+		if opt.Type() == cty.String {
+			if opt.IsNull() {
+				return nil, perr.BadRequestWithMessage("input options must declare a value")
+			}
+			val := opt.AsString()
+			output = append(output, PipelineStepInputOption{
+				UnresolvedAttributes: make(map[string]hcl.Expression),
+				Value:                &val,
+				Label:                utils.ToPointer(val),
+			})
+			continue
+		}
+
+		if !opt.Type().IsObjectType() && !opt.Type().IsMapType() {
+			return nil, perr.BadRequestWithMessage("input options must be strings or objects with a value")
+		}
+
 		valueMap := opt.AsValueMap()
 
 		isValid := false
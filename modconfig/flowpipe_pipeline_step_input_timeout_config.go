@@ -0,0 +1,401 @@
+package modconfig
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/utils"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// InputOnTimeoutFail fails the input step when nobody responds before the timeout
+	InputOnTimeoutFail = "fail"
+	// InputOnTimeoutContinue finishes the input step with the default value when nobody responds before the timeout
+	InputOnTimeoutContinue = "continue"
+
+	InputScheduleEventReminder   = "reminder"
+	InputScheduleEventEscalation = "escalation"
+	InputScheduleEventTimeout    = "timeout"
+)
+
+// the attributes of the input step which are held by its timeout config
+var InputTimeoutAttributes = []string{
+	schema.AttributeTypeDefaultValue,
+	schema.AttributeTypeOnTimeout,
+	schema.AttributeTypeReminder,
+	schema.AttributeTypeEscalation,
+	schema.AttributeTypeEscalationWindow,
+}
+
+// InputTimeoutConfig is what happens while an input step waits for a response: reminders sent every reminder
+// interval, escalation to the next notifier of the escalation list every escalation window, and the action taken when
+// the timeout of the step is reached. Durations are strings such as "4h" or whole numbers of milliseconds, like the
+// timeout of the step.
+type InputTimeoutConfig struct {
+	// circular link to its "parent"
+	PipelineStepBase *PipelineStepBase `json:"-"`
+
+	UnresolvedAttributes map[string]hcl.Expression `json:"-"`
+
+	OnTimeout        *string        `json:"on_timeout,omitempty" cty:"on_timeout"`
+	DefaultValue     interface{}    `json:"default_value,omitempty" cty:"-"`
+	Reminder         interface{}    `json:"reminder,omitempty" cty:"-"`
+	Escalation       []NotifierImpl `json:"escalation,omitempty" cty:"-"`
+	EscalationWindow interface{}    `json:"escalation_window,omitempty" cty:"-"`
+}
+
+func NewInputTimeoutConfig(p *PipelineStepBase) *InputTimeoutConfig {
+	return &InputTimeoutConfig{
+		PipelineStepBase:     p,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+	}
+}
+
+func (c *InputTimeoutConfig) Equals(other *InputTimeoutConfig) bool {
+	if c == nil && other == nil {
+		return true
+	}
+
+	if c == nil && other != nil || c != nil && other == nil {
+		return false
+	}
+
+	if len(c.UnresolvedAttributes) != len(other.UnresolvedAttributes) {
+		return false
+	}
+
+	for key, expr := range c.UnresolvedAttributes {
+		otherExpr, ok := other.UnresolvedAttributes[key]
+		if !ok || !hclhelpers.ExpressionsEqual(expr, otherExpr) {
+			return false
+		}
+	}
+
+	if len(c.Escalation) != len(other.Escalation) {
+		return false
+	}
+
+	for i := range c.Escalation {
+		if !c.Escalation[i].Equals(&other.Escalation[i]) {
+			return false
+		}
+	}
+
+	return utils.PtrEqual(c.OnTimeout, other.OnTimeout) &&
+		reflect.DeepEqual(c.DefaultValue, other.DefaultValue) &&
+		reflect.DeepEqual(c.Reminder, other.Reminder) &&
+		reflect.DeepEqual(c.EscalationWindow, other.EscalationWindow)
+}
+
+func (c *InputTimeoutConfig) AppendDependsOn(dependsOn ...string) {
+	c.PipelineStepBase.AppendDependsOn(dependsOn...)
+}
+
+func (c *InputTimeoutConfig) AppendCredentialDependsOn(...string) {
+	// not implemented
+}
+
+func (c *InputTimeoutConfig) AppendConnectionDependsOn(...string) {
+	// not implemented
+}
+
+func (c *InputTimeoutConfig) AddUnresolvedAttribute(name string, expr hcl.Expression) {
+	c.UnresolvedAttributes[name] = expr
+}
+
+func (c *InputTimeoutConfig) GetPipeline() *Pipeline {
+	return c.PipelineStepBase.GetPipeline()
+}
+
+func (c *InputTimeoutConfig) SetAttributes(hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, attr := range hclAttributes {
+		val, stepDiags := dependsOnFromExpressions(attr, evalContext, c)
+		if stepDiags.HasErrors() {
+			diags = append(diags, stepDiags...)
+			continue
+		}
+
+		if val == cty.NilVal {
+			continue
+		}
+
+		if err := c.setValue(name, val); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to parse " + name + " attribute",
+				Detail:   err.Error(),
+				Subject:  &attr.Range,
+			})
+		}
+	}
+
+	return diags
+}
+
+func (c *InputTimeoutConfig) setValue(name string, val cty.Value) error {
+	switch name {
+	case schema.AttributeTypeOnTimeout:
+		onTimeout, err := hclhelpers.CtyToString(val)
+		if err != nil {
+			return err
+		}
+		c.OnTimeout = &onTimeout
+
+	case schema.AttributeTypeDefaultValue:
+		defaultValue, err := hclhelpers.CtyToGo(val)
+		if err != nil {
+			return err
+		}
+		c.DefaultValue = defaultValue
+
+	case schema.AttributeTypeReminder, schema.AttributeTypeEscalationWindow:
+		duration, err := hclhelpers.CtyToGo(val)
+		if err != nil {
+			return err
+		}
+		if name == schema.AttributeTypeReminder {
+			c.Reminder = duration
+		} else {
+			c.EscalationWindow = duration
+		}
+
+	case schema.AttributeTypeEscalation:
+		if val.IsNull() || !val.CanIterateElements() {
+			return fmt.Errorf("%s must be a list of notifiers", name)
+		}
+		escalation := []NotifierImpl{}
+		for _, notifierVal := range val.AsValueSlice() {
+			notifier, err := ctyValueToPipelineStepNotifierValueMap(notifierVal)
+			if err != nil {
+				return err
+			}
+			escalation = append(escalation, notifier)
+		}
+		c.Escalation = escalation
+	}
+
+	return nil
+}
+
+// Resolve returns a copy of the config with the attributes that are only known at runtime resolved
+func (c *InputTimeoutConfig) Resolve(evalContext *hcl.EvalContext) (*InputTimeoutConfig, hcl.Diagnostics) {
+	resolved := &InputTimeoutConfig{
+		PipelineStepBase:     c.PipelineStepBase,
+		UnresolvedAttributes: make(map[string]hcl.Expression),
+		OnTimeout:            c.OnTimeout,
+		DefaultValue:         c.DefaultValue,
+		Reminder:             c.Reminder,
+		Escalation:           c.Escalation,
+		EscalationWindow:     c.EscalationWindow,
+	}
+
+	for name, expr := range c.UnresolvedAttributes {
+		val, diags := expr.Value(evalContext)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		if err := resolved.setValue(name, val); err != nil {
+			return nil, hcl.Diagnostics{
+				{
+					Severity: hcl.DiagError,
+					Summary:  "Unable to parse " + name + " attribute",
+					Detail:   err.Error(),
+					Subject:  expr.Range().Ptr(),
+				},
+			}
+		}
+	}
+
+	diags := resolved.Validate()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return resolved, hcl.Diagnostics{}
+}
+
+// TimeoutAction returns the action taken when the timeout is reached, the default value implies continue
+func (c *InputTimeoutConfig) TimeoutAction() string {
+	if c.OnTimeout != nil {
+		return *c.OnTimeout
+	}
+	if c.DefaultValue != nil {
+		return InputOnTimeoutContinue
+	}
+	return InputOnTimeoutFail
+}
+
+// Validate validates the attributes known at parse time
+func (c *InputTimeoutConfig) Validate() hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	invalid := func(detail string) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid input timeout: " + detail,
+			Subject:  c.PipelineStepBase.Range,
+		})
+	}
+
+	if c.OnTimeout != nil && *c.OnTimeout != InputOnTimeoutFail && *c.OnTimeout != InputOnTimeoutContinue {
+		invalid("on_timeout must be " + InputOnTimeoutFail + " or " + InputOnTimeoutContinue)
+	}
+
+	if c.OnTimeout != nil && *c.OnTimeout == InputOnTimeoutFail && c.DefaultValue != nil {
+		invalid("default_value can not be set when on_timeout is " + InputOnTimeoutFail)
+	}
+
+	for _, d := range []struct {
+		name  string
+		value interface{}
+	}{{schema.AttributeTypeReminder, c.Reminder}, {schema.AttributeTypeEscalationWindow, c.EscalationWindow}} {
+		if d.value == nil {
+			continue
+		}
+		if duration, err := InputDuration(d.value); err != nil {
+			invalid(d.name + ": " + err.Error())
+		} else if duration <= 0 {
+			invalid(d.name + " must be greater than 0")
+		}
+	}
+
+	_, unresolvedEscalation := c.UnresolvedAttributes[schema.AttributeTypeEscalation]
+	_, unresolvedWindow := c.UnresolvedAttributes[schema.AttributeTypeEscalationWindow]
+	hasEscalation := len(c.Escalation) > 0 || unresolvedEscalation
+	hasWindow := c.EscalationWindow != nil || unresolvedWindow
+
+	if hasEscalation && !hasWindow {
+		invalid("escalation_window is required with escalation")
+	}
+	if hasWindow && !hasEscalation {
+		invalid("escalation_window can only be set with escalation")
+	}
+
+	return diags
+}
+
+// InputDuration converts a duration string, such as "90m", or a whole number of milliseconds to a duration
+func InputDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case string:
+		return time.ParseDuration(v)
+	case int:
+		return time.Duration(v) * time.Millisecond, nil
+	case int64:
+		return time.Duration(v) * time.Millisecond, nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not a whole number of milliseconds", v)
+		}
+		return time.Duration(v) * time.Millisecond, nil
+	}
+	return 0, fmt.Errorf("a duration must be a string or a whole number of milliseconds")
+}
+
+// InputScheduleEvent is a reminder, an escalation or the timeout of an input step, After is the time since the step
+// started waiting for a response
+type InputScheduleEvent struct {
+	Type  string        `json:"type"`
+	After time.Duration `json:"after"`
+	// Level is the number of escalations at the time of the event, 0 is the notifier of the step
+	Level int `json:"level"`
+	// Notifier is the escalation notifier to send the event to, nil for the notifier of the step
+	Notifier *NotifierImpl `json:"notifier,omitempty"`
+}
+
+// Next returns the first event after the elapsed time, or nil if there is none. The timeout is the timeout of the
+// step, 0 if it has none. When events coincide the timeout wins over an escalation and an escalation over a reminder,
+// so the executor never sends a reminder to a notifier it is escalating from.
+func (c *InputTimeoutConfig) Next(timeout, elapsed time.Duration) (*InputScheduleEvent, error) {
+	var reminder, window time.Duration
+	var err error
+
+	if c.Reminder != nil {
+		if reminder, err = InputDuration(c.Reminder); err != nil {
+			return nil, err
+		}
+	}
+	if c.EscalationWindow != nil {
+		if window, err = InputDuration(c.EscalationWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []InputScheduleEvent
+
+	if timeout > elapsed {
+		candidates = append(candidates, InputScheduleEvent{Type: InputScheduleEventTimeout, After: timeout})
+	}
+
+	if window > 0 && len(c.Escalation) > 0 {
+		next := (elapsed/window + 1) * window
+		if level := int(next / window); level <= len(c.Escalation) {
+			candidates = append(candidates, InputScheduleEvent{Type: InputScheduleEventEscalation, After: next})
+		}
+	}
+
+	if reminder > 0 {
+		candidates = append(candidates, InputScheduleEvent{Type: InputScheduleEventReminder, After: (elapsed/reminder + 1) * reminder})
+	}
+
+	priority := []string{InputScheduleEventTimeout, InputScheduleEventEscalation, InputScheduleEventReminder}
+	var next *InputScheduleEvent
+	for i := range candidates {
+		candidate := &candidates[i]
+		if timeout > 0 && candidate.After > timeout {
+			continue
+		}
+		if next == nil || candidate.After < next.After ||
+			candidate.After == next.After && slices.Index(priority, candidate.Type) < slices.Index(priority, next.Type) {
+			next = candidate
+		}
+	}
+
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Level = c.escalationLevel(window, next.After)
+	if next.Level > 0 {
+		next.Notifier = &c.Escalation[next.Level-1]
+	}
+	return next, nil
+}
+
+func (c *InputTimeoutConfig) escalationLevel(window, after time.Duration) int {
+	if window <= 0 {
+		return 0
+	}
+	return min(int(after/window), len(c.Escalation))
+}
+
+// Schedule returns the events up to the timeout of the step, or up to the horizon when the step has no timeout
+func (c *InputTimeoutConfig) Schedule(timeout, horizon time.Duration) ([]InputScheduleEvent, error) {
+	end := horizon
+	if timeout > 0 {
+		end = timeout
+	}
+
+	var events []InputScheduleEvent
+	var elapsed time.Duration
+	for {
+		next, err := c.Next(timeout, elapsed)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || next.After > end {
+			return events, nil
+		}
+		events = append(events, *next)
+		elapsed = next.After
+	}
+}
@@ -0,0 +1,354 @@
+package modconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	InputDateLayout = "2006-01-02"
+	// InputDateTimeLocalLayout is the layout of an HTML datetime-local input, a datetime response may also be RFC 3339
+	InputDateTimeLocalLayout = "2006-01-02T15:04"
+)
+
+// the per type attributes of the input step, and the input types they can be set for
+var inputTypeAttributes = map[string][]string{
+	schema.AttributeTypeMin:           {constants.InputTypeNumber},
+	schema.AttributeTypeMax:           {constants.InputTypeNumber},
+	schema.AttributeTypePattern:       {constants.InputTypeText},
+	schema.AttributeTypeConfirmPhrase: {constants.InputTypeConfirm},
+	schema.AttributeTypePlaceholder:   {constants.InputTypeText, constants.InputTypeNumber, constants.InputTypeDate, constants.InputTypeDateTime, constants.InputTypeConfirm},
+}
+
+// validateInputTypeSettings validates the per type attributes of the inputs of an input step. A nil value is an
+// attribute which is resolved at runtime, only its presence is checked.
+func validateInputTypeSettings(input map[string]interface{}) error {
+	inputType, _ := input[schema.AttributeTypeType].(string)
+
+	names := make([]string, 0, len(inputTypeAttributes))
+	for name := range inputTypeAttributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, ok := input[name]; ok && !slices.Contains(inputTypeAttributes[name], inputType) {
+			return fmt.Errorf("%s can only be set for %s inputs", name, strings.Join(inputTypeAttributes[name], ", "))
+		}
+	}
+
+	min, hasMin := input[schema.AttributeTypeMin].(float64)
+	max, hasMax := input[schema.AttributeTypeMax].(float64)
+	if hasMin && hasMax && min > max {
+		return fmt.Errorf("min %v must not be greater than max %v", min, max)
+	}
+
+	if pattern, ok := input[schema.AttributeTypePattern].(string); ok {
+		if _, err := compileInputPattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	if inputType == constants.InputTypeConfirm {
+		if _, ok := input[schema.AttributeTypeConfirmPhrase]; !ok {
+			return fmt.Errorf("%s is required for confirm inputs", schema.AttributeTypeConfirmPhrase)
+		}
+		if phrase, ok := input[schema.AttributeTypeConfirmPhrase].(string); ok && strings.TrimSpace(phrase) == "" {
+			return fmt.Errorf("%s must not be empty", schema.AttributeTypeConfirmPhrase)
+		}
+	}
+
+	return nil
+}
+
+// compileInputPattern compiles the pattern of a text input, which must match the whole response
+func compileInputPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// ValidateInputResponse validates the response to an input step against the resolved inputs of the step. The
+// response of a multiselect input is a list of option values, the response of any other input is a string.
+func ValidateInputResponse(input map[string]interface{}, response interface{}) error {
+	inputType, _ := input[schema.AttributeTypeType].(string)
+
+	if inputType == constants.InputTypeMultiSelect {
+		values, ok := response.([]string)
+		if !ok {
+			return perr.BadRequestWithMessage("the response to a multiselect input must be a list of option values")
+		}
+		for _, value := range values {
+			if err := validateInputOptionResponse(input, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value, ok := response.(string)
+	if !ok {
+		return perr.BadRequestWithMessage("the response to a " + inputType + " input must be a string")
+	}
+
+	switch inputType {
+	case constants.InputTypeButton, constants.InputTypeSelect:
+		return validateInputOptionResponse(input, value)
+
+	case constants.InputTypeNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		// ParseFloat accepts NaN and Inf, which are not numbers a user can answer and NaN passes any min and max
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return perr.BadRequestWithMessage("the response " + value + " is not a number")
+		}
+		if min, ok := input[schema.AttributeTypeMin].(float64); ok && number < min {
+			return perr.BadRequestWithMessage(fmt.Sprintf("the response %v must be at least %v", number, min))
+		}
+		if max, ok := input[schema.AttributeTypeMax].(float64); ok && number > max {
+			return perr.BadRequestWithMessage(fmt.Sprintf("the response %v must be at most %v", number, max))
+		}
+
+	case constants.InputTypeDate:
+		if _, err := time.Parse(InputDateLayout, strings.TrimSpace(value)); err != nil {
+			return perr.BadRequestWithMessage("the response " + value + " is not a date in the format YYYY-MM-DD")
+		}
+
+	case constants.InputTypeDateTime:
+		if _, err := parseInputDateTime(value); err != nil {
+			return perr.BadRequestWithMessage("the response " + value + " is not a datetime in the format YYYY-MM-DDTHH:MM or RFC 3339")
+		}
+
+	case constants.InputTypeConfirm:
+		phrase, _ := input[schema.AttributeTypeConfirmPhrase].(string)
+		if strings.TrimSpace(value) != phrase {
+			return perr.BadRequestWithMessage("the response does not match the confirmation phrase " + phrase)
+		}
+
+	case constants.InputTypeText:
+		if pattern, ok := input[schema.AttributeTypePattern].(string); ok {
+			re, err := compileInputPattern(pattern)
+			if err != nil {
+				return perr.BadRequestWithMessage("invalid pattern: " + err.Error())
+			}
+			if !re.MatchString(value) {
+				return perr.BadRequestWithMessage("the response does not match the pattern " + pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateInputOptionResponse(input map[string]interface{}, value string) error {
+	options, _ := input[schema.AttributeTypeOptions].([]PipelineStepInputOption)
+	for _, option := range options {
+		if option.Value != nil && *option.Value == value {
+			return nil
+		}
+	}
+	return perr.BadRequestWithMessage("the response " + value + " is not one of the options of the input")
+}
+
+func parseInputDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(InputDateTimeLocalLayout, value)
+}
+
+// ValidateResponse validates the response against the resolved inputs of the step, then evaluates the validation
+// expression with the response as "value": a number for number inputs, a list of strings for multiselect inputs and
+// a string otherwise.
+func (p *PipelineStepInput) ValidateResponse(input map[string]interface{}, response interface{}, evalContext *hcl.EvalContext) error {
+	if err := ValidateInputResponse(input, response); err != nil {
+		return err
+	}
+
+	if p.Validation == nil {
+		return nil
+	}
+
+	var value cty.Value
+	switch response := response.(type) {
+	case []string:
+		values := make([]cty.Value, len(response))
+		for i, v := range response {
+			values[i] = cty.StringVal(v)
+		}
+		value = cty.ListValEmpty(cty.String)
+		if len(values) > 0 {
+			value = cty.ListVal(values)
+		}
+	case string:
+		value = cty.StringVal(response)
+		if p.InputType == constants.InputTypeNumber {
+			number, _ := strconv.ParseFloat(strings.TrimSpace(response), 64)
+			value = cty.NumberFloatVal(number)
+		}
+	}
+
+	if evalContext == nil {
+		evalContext = &hcl.EvalContext{}
+	}
+	validationContext := evalContext.NewChild()
+	validationContext.Variables = map[string]cty.Value{
+		"value": value,
+	}
+
+	result, diags := p.Validation.Value(validationContext)
+	if diags.HasErrors() {
+		return error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	if result.IsNull() || !result.IsKnown() || result.Type() != cty.Bool {
+		return perr.BadRequestWithMessage(p.Name + ": the validation expression must return a bool")
+	}
+
+	if result.False() {
+		return perr.BadRequestWithMessage("the response does not satisfy the validation of the input " + p.Name)
+	}
+
+	return nil
+}
+
+// InputRenderHint describes how an integration renders an input: the native elements, such as a Slack Block Kit
+// element or an Adaptive Card input, and their properties
+type InputRenderHint struct {
+	Elements   []string               `json:"elements"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// InputRenderHintFromInput returns the render hint of the resolved inputs of an input step for the integration type
+func InputRenderHintFromInput(input map[string]interface{}, integrationType string) (*InputRenderHint, error) {
+	inputType, _ := input[schema.AttributeTypeType].(string)
+	if !constants.IsValidInputType(inputType) {
+		return nil, fmt.Errorf("invalid input type %s", inputType)
+	}
+
+	hint := &InputRenderHint{Properties: map[string]interface{}{}}
+	placeholder, hasPlaceholder := input[schema.AttributeTypePlaceholder].(string)
+	min, hasMin := input[schema.AttributeTypeMin].(float64)
+	max, hasMax := input[schema.AttributeTypeMax].(float64)
+	pattern, hasPattern := input[schema.AttributeTypePattern].(string)
+
+	switch integrationType {
+	case schema.IntegrationTypeSlack:
+		switch inputType {
+		case constants.InputTypeButton:
+			hint.Elements = []string{"button"}
+		case constants.InputTypeSelect:
+			hint.Elements = []string{"static_select"}
+		case constants.InputTypeMultiSelect:
+			hint.Elements = []string{"multi_static_select"}
+		case constants.InputTypeNumber:
+			hint.Elements = []string{"number_input"}
+			hint.Properties["is_decimal_allowed"] = true
+			// Block Kit number bounds are strings
+			if hasMin {
+				hint.Properties["min_value"] = strconv.FormatFloat(min, 'f', -1, 64)
+			}
+			if hasMax {
+				hint.Properties["max_value"] = strconv.FormatFloat(max, 'f', -1, 64)
+			}
+		case constants.InputTypeDate:
+			hint.Elements = []string{"datepicker"}
+		case constants.InputTypeDateTime:
+			hint.Elements = []string{"datetimepicker"}
+		default:
+			hint.Elements = []string{"plain_text_input"}
+		}
+		if hasPlaceholder {
+			hint.Properties["placeholder"] = placeholder
+		}
+
+	case schema.IntegrationTypeMsTeams:
+		switch inputType {
+		case constants.InputTypeButton:
+			hint.Elements = []string{"Action.Submit"}
+		case constants.InputTypeSelect:
+			hint.Elements = []string{"Input.ChoiceSet"}
+			hint.Properties["style"] = "compact"
+		case constants.InputTypeMultiSelect:
+			hint.Elements = []string{"Input.ChoiceSet"}
+			hint.Properties["isMultiSelect"] = true
+		case constants.InputTypeNumber:
+			hint.Elements = []string{"Input.Number"}
+			if hasMin {
+				hint.Properties["min"] = min
+			}
+			if hasMax {
+				hint.Properties["max"] = max
+			}
+		case constants.InputTypeDate:
+			hint.Elements = []string{"Input.Date"}
+		case constants.InputTypeDateTime:
+			// Adaptive Cards have no datetime input
+			hint.Elements = []string{"Input.Date", "Input.Time"}
+		default:
+			hint.Elements = []string{"Input.Text"}
+			if hasPattern {
+				hint.Properties["regex"] = "^(?:" + pattern + ")$"
+			}
+		}
+		if hasPlaceholder {
+			hint.Properties["placeholder"] = placeholder
+		}
+
+	case schema.IntegrationTypeEmail, schema.IntegrationTypeHttp, schema.IntegrationTypeWebhook:
+		// HTML form controls
+		switch inputType {
+		case constants.InputTypeButton:
+			hint.Elements = []string{"button"}
+		case constants.InputTypeSelect:
+			hint.Elements = []string{"select"}
+		case constants.InputTypeMultiSelect:
+			hint.Elements = []string{"select"}
+			hint.Properties["multiple"] = true
+		default:
+			hint.Elements = []string{"input"}
+			switch inputType {
+			case constants.InputTypeNumber:
+				hint.Properties["type"] = "number"
+				hint.Properties["step"] = "any"
+				if hasMin {
+					hint.Properties["min"] = min
+				}
+				if hasMax {
+					hint.Properties["max"] = max
+				}
+			case constants.InputTypeDate:
+				hint.Properties["type"] = "date"
+			case constants.InputTypeDateTime:
+				hint.Properties["type"] = "datetime-local"
+			default:
+				hint.Properties["type"] = "text"
+				if hasPattern {
+					// the HTML pattern attribute always matches the whole value
+					hint.Properties["pattern"] = pattern
+				}
+			}
+		}
+		if hasPlaceholder {
+			hint.Properties["placeholder"] = placeholder
+		}
+
+	default:
+		return nil, fmt.Errorf("no input render hint for integration type %s", integrationType)
+	}
+
+	if len(hint.Properties) == 0 {
+		hint.Properties = nil
+	}
+	return hint, nil
+}
@@ -1,6 +1,11 @@
 package modconfig
 
 import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/error_helpers"
@@ -24,6 +29,14 @@ type PipelineStepMessage struct {
 	Channel *string  `json:"channel,omitempty" cty:"channel" hcl:"channel,optional"`
 	Subject *string  `json:"subject,omitempty" cty:"subject" hcl:"subject,optional"`
 	To      []string `json:"to,omitempty" cty:"to" hcl:"to,optional"`
+
+	// Severity of the message, used to route it to the notify targets of the notifier
+	Severity *string `json:"severity,omitempty" cty:"severity" hcl:"severity,optional"`
+
+	// Color overrides the colour of the severity, as a #RRGGBB hex colour
+	Color *string `json:"color,omitempty" cty:"color" hcl:"color,optional"`
+	// Sections of the rich message, after the text
+	Sections []RichMessageSection `json:"sections,omitempty" cty:"-"`
 }
 
 func (p *PipelineStepMessage) Equals(iOther PipelineStep) bool {
@@ -50,6 +63,9 @@ func (p *PipelineStepMessage) Equals(iOther PipelineStep) bool {
 		helpers.StringSliceEqualIgnoreOrder(p.Bcc, other.Bcc) &&
 		utils.PtrEqual(p.Channel, other.Channel) &&
 		helpers.StringSliceEqualIgnoreOrder(p.To, other.To) &&
+		utils.PtrEqual(p.Severity, other.Severity) &&
+		utils.PtrEqual(p.Color, other.Color) &&
+		reflect.DeepEqual(p.Sections, other.Sections) &&
 		p.Notifier.Equals(&other.Notifier)
 }
 
@@ -75,6 +91,21 @@ func (p *PipelineStepMessage) GetInputs(evalContext *hcl.EvalContext) (map[strin
 		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
 	}
 
+	// severity
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeSeverity, p.Severity)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+	if severity, ok := results[schema.AttributeTypeSeverity].(string); ok && !slices.Contains(ValidRichMessageSeverities, severity) {
+		return nil, perr.BadRequestWithMessage(p.Name + ": invalid severity " + severity + ", must be one of " + strings.Join(ValidRichMessageSeverities, ", "))
+	}
+
+	// color and sections
+	results, err := richMessageInputs(p, results, evalContext, p.Color, p.Sections)
+	if err != nil {
+		return nil, err
+	}
+
 	// to
 	results, diags = stringSliceInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeTo, &p.To)
 	if diags.HasErrors() {
@@ -127,7 +158,7 @@ func (p *PipelineStepMessage) SetAttributes(hclAttributes hcl.Attributes, evalCo
 				continue
 			}
 
-		case schema.AttributeTypeChannel, schema.AttributeTypeSubject:
+		case schema.AttributeTypeChannel, schema.AttributeTypeSubject, schema.AttributeTypeSeverity, schema.AttributeTypeColor:
 
 			structFieldName := utils.CapitalizeFirst(name)
 			stepDiags := setStringAttribute(attr, evalContext, p, structFieldName, true)
@@ -144,6 +175,14 @@ func (p *PipelineStepMessage) SetAttributes(hclAttributes hcl.Attributes, evalCo
 				continue
 			}
 
+		case schema.AttributeTypeSections:
+			sections, stepDiags := richMessageSectionsFromAttribute(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+			p.Sections = sections
+
 		case schema.AttributeTypeNotifier:
 			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
 			if stepDiags.HasErrors() {
@@ -177,3 +216,101 @@ func (p *PipelineStepMessage) SetAttributes(hclAttributes hcl.Attributes, evalCo
 
 	return diags
 }
+
+func (p *PipelineStepMessage) Validate() hcl.Diagnostics {
+	// validate the base attributes
+	diags := p.ValidateBaseAttributes()
+
+	if p.Severity != nil && !slices.Contains(ValidRichMessageSeverities, *p.Severity) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid severity " + *p.Severity + ": must be one of " + strings.Join(ValidRichMessageSeverities, ", "),
+			Subject:  p.GetRange(),
+		})
+	}
+
+	diags = append(diags, validateRichMessageAttributes(p, p.Color, p.Sections)...)
+
+	return diags
+}
+
+// NotificationContext returns the routing context of the message sent by the pipeline, from the resolved inputs of
+// the step
+func (p *PipelineStepMessage) NotificationContext(input map[string]interface{}) NotificationContext {
+	nc := NotificationContext{Time: time.Now()}
+	nc.Severity, _ = input[schema.AttributeTypeSeverity].(string)
+
+	if pipeline := p.GetPipeline(); pipeline != nil {
+		nc.PipelineName = pipeline.Name()
+		nc.Tags = pipeline.Tags
+	}
+	return nc
+}
+
+// richMessageSectionsFromAttribute returns the sections of a message or input step, nil if they reference values
+// which are only resolved when the step runs
+func richMessageSectionsFromAttribute(attr *hcl.Attribute, evalContext *hcl.EvalContext, p PipelineStepBaseInterface) ([]RichMessageSection, hcl.Diagnostics) {
+	val, diags := dependsOnFromExpressions(attr, evalContext, p)
+	if diags.HasErrors() || val == cty.NilVal {
+		return nil, diags
+	}
+
+	sections, err := CtyValueToRichMessageSections(val)
+	if err != nil {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to parse " + schema.AttributeTypeSections + " attribute",
+			Detail:   err.Error(),
+			Subject:  &attr.Range,
+		}}
+	}
+	return sections, diags
+}
+
+// richMessageInputs adds the colour and sections of a message or input step to its inputs
+func richMessageInputs(p PipelineStep, results map[string]interface{}, evalContext *hcl.EvalContext, color *string, sections []RichMessageSection) (map[string]interface{}, error) {
+	results, diags := simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeColor, color)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.GetName(), diags)
+	}
+
+	if expr, ok := p.GetUnresolvedAttributes()[schema.AttributeTypeSections]; ok {
+		val, diags := expr.Value(evalContext)
+		if diags.HasErrors() {
+			return nil, error_helpers.BetterHclDiagsToError(p.GetName(), diags)
+		}
+
+		var err error
+		sections, err = CtyValueToRichMessageSections(val)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.GetName() + ": unable to parse sections attribute: " + err.Error())
+		}
+	}
+	if len(sections) > 0 {
+		results[schema.AttributeTypeSections] = sections
+	}
+
+	resolvedColor, _ := results[schema.AttributeTypeColor].(string)
+	m := &RichMessage{Color: resolvedColor, Sections: sections}
+	if err := m.Validate(); err != nil {
+		return nil, perr.BadRequestWithMessage(p.GetName() + ": " + err.Error())
+	}
+	return results, nil
+}
+
+// validateRichMessageAttributes validates the colour and sections of a message or input step which are resolved when
+// the pipeline is parsed
+func validateRichMessageAttributes(p PipelineStep, color *string, sections []RichMessageSection) hcl.Diagnostics {
+	m := &RichMessage{Sections: sections}
+	if color != nil {
+		m.Color = *color
+	}
+	if err := m.Validate(); err != nil {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid message: " + err.Error(),
+			Subject:  p.GetRange(),
+		}}
+	}
+	return hcl.Diagnostics{}
+}
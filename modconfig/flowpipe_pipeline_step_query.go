@@ -1,12 +1,17 @@
 package modconfig
 
 import (
+	"fmt"
 	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/app_specific_connection"
+	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/connection"
 	"github.com/turbot/pipe-fittings/error_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
@@ -23,6 +28,93 @@ type PipelineStepQuery struct {
 	ConnectionString *string       `json:"database"`
 	Sql              *string       `json:"sql"`
 	Args             []interface{} `json:"args"`
+
+	// NamedArgs is set when args is a map, the :name parameters of the sql are bound to the placeholders of the
+	// database when the inputs are resolved
+	NamedArgs map[string]interface{} `json:"named_args,omitempty"`
+
+	// Statements is the alternative to sql and args to run several statements, in a transaction if Transaction is set
+	Statements     []QueryStatement `json:"statements,omitempty"`
+	Transaction    *bool            `json:"transaction,omitempty"`
+	IsolationLevel *string          `json:"isolation_level,omitempty"`
+}
+
+// QueryStatement is an item of the statements list of the query step, the args are either a list of positional args
+// or a map of named args
+type QueryStatement struct {
+	Sql       string                 `json:"sql" cty:"sql"`
+	Args      []interface{}          `json:"args,omitempty" cty:"args"`
+	NamedArgs map[string]interface{} `json:"named_args,omitempty" cty:"named_args"`
+}
+
+func (s QueryStatement) Equals(other QueryStatement) bool {
+	return s.Sql == other.Sql &&
+		reflect.DeepEqual(s.Args, other.Args) &&
+		reflect.DeepEqual(s.NamedArgs, other.NamedArgs)
+}
+
+// Bind returns the statement sql and positional args for the database, binding the named args to its placeholders
+func (s QueryStatement) Bind(database string) (backend.Statement, error) {
+	if s.NamedArgs == nil {
+		return backend.Statement{Sql: s.Sql, Args: s.Args}, nil
+	}
+
+	sql, args, err := backend.BindNamedArgs(s.Sql, s.NamedArgs, backend.SqlDialectForConnectionString(database))
+	if err != nil {
+		return backend.Statement{}, err
+	}
+	return backend.Statement{Sql: sql, Args: args}, nil
+}
+
+// queryStatementsFromGoValue converts the value of the statements attribute: a list of objects with sql and
+// optional args
+func queryStatementsFromGoValue(value interface{}) ([]QueryStatement, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("statements must be a list of objects with sql and args")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("statements must contain at least one statement")
+	}
+
+	statements := make([]QueryStatement, 0, len(items))
+	for i, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("statement %d must be an object with sql and args", i+1)
+		}
+
+		var statement QueryStatement
+		for key, v := range itemMap {
+			switch key {
+			case schema.AttributeTypeSql:
+				sql, ok := v.(string)
+				if !ok || sql == "" {
+					return nil, fmt.Errorf("the sql of statement %d must be a non-empty string", i+1)
+				}
+				statement.Sql = sql
+			case schema.AttributeTypeArgs:
+				switch args := v.(type) {
+				case nil:
+				case []interface{}:
+					statement.Args = args
+				case map[string]interface{}:
+					statement.NamedArgs = args
+				default:
+					return nil, fmt.Errorf("the args of statement %d must be a list or a map", i+1)
+				}
+			default:
+				return nil, fmt.Errorf("unsupported attribute %s in statement %d", key, i+1)
+			}
+		}
+
+		if statement.Sql == "" {
+			return nil, fmt.Errorf("the sql of statement %d must be set", i+1)
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
 }
 
 func (p *PipelineStepQuery) Equals(iOther PipelineStep) bool {
@@ -53,8 +145,18 @@ func (p *PipelineStepQuery) Equals(iOther PipelineStep) bool {
 		}
 	}
 
+	if !reflect.DeepEqual(p.NamedArgs, other.NamedArgs) {
+		return false
+	}
+
+	if !slices.EqualFunc(p.Statements, other.Statements, func(a, b QueryStatement) bool { return a.Equals(b) }) {
+		return false
+	}
+
 	return utils.PtrEqual(p.Database, other.Database) &&
-		utils.PtrEqual(p.Sql, other.Sql)
+		utils.PtrEqual(p.Sql, other.Sql) &&
+		utils.BoolPtrEqual(p.Transaction, other.Transaction) &&
+		utils.PtrEqual(p.IsolationLevel, other.IsolationLevel)
 }
 
 func (p *PipelineStepQuery) GetInputs(evalContext *hcl.EvalContext) (map[string]interface{}, error) {
@@ -106,6 +208,12 @@ func (p *PipelineStepQuery) GetInputs(evalContext *hcl.EvalContext) (map[string]
 		return nil, perr.BadRequestWithMessage(p.Name + ": database must be supplied")
 	}
 
+	database, _ := results[schema.AttributeTypeDatabase].(string)
+	if databasePtr, ok := results[schema.AttributeTypeDatabase].(*string); ok {
+		database = *databasePtr
+	}
+
+	var namedArgs map[string]interface{}
 	if p.UnresolvedAttributes[schema.AttributeTypeArgs] != nil {
 		var args cty.Value
 		diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeArgs], evalContext, &args)
@@ -113,16 +221,84 @@ func (p *PipelineStepQuery) GetInputs(evalContext *hcl.EvalContext) (map[string]
 			return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
 		}
 
-		mapValue, err := hclhelpers.CtyToGoInterfaceSlice(args)
-		if err != nil {
-			return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse args attribute to an array " + err.Error())
+		if args.Type().IsObjectType() || args.Type().IsMapType() {
+			namedArgs, err = hclhelpers.CtyToGoMapInterface(args)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse args attribute to a map " + err.Error())
+			}
+		} else {
+			mapValue, err := hclhelpers.CtyToGoInterfaceSlice(args)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse args attribute to an array " + err.Error())
+			}
+			results[schema.AttributeTypeArgs] = mapValue
 		}
-		results[schema.AttributeTypeArgs] = mapValue
 
+	} else if p.NamedArgs != nil {
+		namedArgs = p.NamedArgs
 	} else if p.Args != nil {
 		results[schema.AttributeTypeArgs] = p.Args
 	}
 
+	// bind the named args to the positional placeholders of the database
+	if namedArgs != nil {
+		sql, _ := results[schema.AttributeTypeSql].(string)
+		statement, err := QueryStatement{Sql: sql, NamedArgs: namedArgs}.Bind(database)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+		}
+		results[schema.AttributeTypeSql] = statement.Sql
+		results[schema.AttributeTypeArgs] = statement.Args
+	}
+
+	// statements
+	statements := p.Statements
+	if p.UnresolvedAttributes[schema.AttributeTypeStatements] != nil {
+		var statementsValue cty.Value
+		diags := gohcl.DecodeExpression(p.UnresolvedAttributes[schema.AttributeTypeStatements], evalContext, &statementsValue)
+		if diags.HasErrors() {
+			return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+		}
+
+		goValue, err := hclhelpers.CtyToGo(statementsValue)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": unable to parse statements attribute " + err.Error())
+		}
+		statements, err = queryStatementsFromGoValue(goValue)
+		if err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+		}
+	}
+
+	if statements != nil {
+		boundStatements := make([]backend.Statement, 0, len(statements))
+		for i, statement := range statements {
+			boundStatement, err := statement.Bind(database)
+			if err != nil {
+				return nil, perr.BadRequestWithMessage(fmt.Sprintf("%s: statement %d: %s", p.Name, i+1, err.Error()))
+			}
+			boundStatements = append(boundStatements, boundStatement)
+		}
+		results[schema.AttributeTypeStatements] = boundStatements
+	}
+
+	// transaction
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeTransaction, p.Transaction)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	results, diags = simpleTypeInputFromAttribute(p.GetUnresolvedAttributes(), results, evalContext, schema.AttributeTypeIsolationLevel, p.IsolationLevel)
+	if diags.HasErrors() {
+		return nil, error_helpers.BetterHclDiagsToError(p.Name, diags)
+	}
+
+	if isolationLevel, ok := results[schema.AttributeTypeIsolationLevel].(string); ok {
+		if _, err := backend.ParseIsolationLevel(isolationLevel); err != nil {
+			return nil, perr.BadRequestWithMessage(p.Name + ": " + err.Error())
+		}
+	}
+
 	return results, nil
 }
 
@@ -147,6 +323,21 @@ func (p *PipelineStepQuery) SetAttributes(hclAttributes hcl.Attributes, evalCont
 			}
 
 			if val != cty.NilVal {
+				// a map of args binds the :name parameters of the sql
+				if val.Type().IsObjectType() || val.Type().IsMapType() {
+					goVals, err2 := hclhelpers.CtyToGoMapInterface(val)
+					if err2 != nil {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Unable to parse '" + schema.AttributeTypeArgs + "' attribute to Go values",
+							Subject:  &attr.Range,
+						})
+						continue
+					}
+					p.NamedArgs = goVals
+					continue
+				}
+
 				goVals, err2 := hclhelpers.CtyToGoInterfaceSlice(val)
 				if err2 != nil {
 					diags = append(diags, &hcl.Diagnostic{
@@ -159,6 +350,53 @@ func (p *PipelineStepQuery) SetAttributes(hclAttributes hcl.Attributes, evalCont
 				p.Args = goVals
 			}
 
+		case schema.AttributeTypeStatements:
+			val, stepDiags := dependsOnFromExpressions(attr, evalContext, p)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if val != cty.NilVal {
+				goVal, err := hclhelpers.CtyToGo(val)
+				if err == nil {
+					p.Statements, err = queryStatementsFromGoValue(goVal)
+				}
+				if err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid " + schema.AttributeTypeStatements + " attribute for Query Step: " + err.Error(),
+						Subject:  &attr.Range,
+					})
+					continue
+				}
+			}
+
+		case schema.AttributeTypeTransaction:
+			stepDiags := setBoolAttribute(attr, evalContext, p, "Transaction", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+		case schema.AttributeTypeIsolationLevel:
+			stepDiags := setStringAttribute(attr, evalContext, p, "IsolationLevel", true)
+			if stepDiags.HasErrors() {
+				diags = append(diags, stepDiags...)
+				continue
+			}
+
+			if p.IsolationLevel != nil {
+				if _, err := backend.ParseIsolationLevel(*p.IsolationLevel); err != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid isolation_level: " + *p.IsolationLevel,
+						Detail:   "The isolation level must be one of " + strings.Join(backend.IsolationLevels, ", "),
+						Subject:  &attr.Range,
+					})
+				}
+			}
+
 		default:
 			if !p.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -176,6 +414,56 @@ func (p *PipelineStepQuery) SetAttributes(hclAttributes hcl.Attributes, evalCont
 func (p *PipelineStepQuery) Validate() hcl.Diagnostics {
 	// validate the base attributes
 	diags := p.ValidateBaseAttributes()
+
+	hasSql := p.Sql != nil || p.UnresolvedAttributes[schema.AttributeTypeSql] != nil
+	hasStatements := p.Statements != nil || p.UnresolvedAttributes[schema.AttributeTypeStatements] != nil
+	hasArgs := p.Args != nil || p.NamedArgs != nil || p.UnresolvedAttributes[schema.AttributeTypeArgs] != nil
+
+	if hasSql && hasStatements {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The sql and statements attributes are mutually exclusive for Query Step: " + p.Name,
+			Subject:  p.GetRange(),
+		})
+	}
+
+	if hasStatements && hasArgs {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The args attribute can not be used with statements for Query Step, set the args of each statement instead: " + p.Name,
+			Subject:  p.GetRange(),
+		})
+	}
+
+	hasTransaction := p.Transaction != nil || p.UnresolvedAttributes[schema.AttributeTypeTransaction] != nil
+	if p.IsolationLevel != nil && !hasTransaction {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The isolation_level attribute requires transaction to be set for Query Step: " + p.Name,
+			Subject:  p.GetRange(),
+		})
+	}
+
+	// the named args are checked against the parameters of the sql, the placeholder style does not matter here
+	if p.Sql != nil && p.NamedArgs != nil {
+		if _, err := (QueryStatement{Sql: *p.Sql, NamedArgs: p.NamedArgs}).Bind(""); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid args for Query Step: " + err.Error(),
+				Subject:  p.GetRange(),
+			})
+		}
+	}
+	for i, statement := range p.Statements {
+		if _, err := statement.Bind(""); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid args for statement %d of Query Step: %s", i+1, err.Error()),
+				Subject:  p.GetRange(),
+			})
+		}
+	}
+
 	return diags
 }
 
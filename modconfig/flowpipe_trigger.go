@@ -274,6 +274,8 @@ type TriggerConfig interface {
 
 type TriggerSchedule struct {
 	Schedule string `json:"schedule"`
+
+	TriggerScheduleOptions
 }
 
 func (t *TriggerSchedule) GetType() string {
@@ -294,7 +296,8 @@ func (t *TriggerSchedule) Equals(other TriggerConfig) bool {
 		return true
 	}
 
-	return t.Schedule == otherTrigger.Schedule
+	return t.Schedule == otherTrigger.Schedule &&
+		t.TriggerScheduleOptions.Equals(&otherTrigger.TriggerScheduleOptions)
 }
 
 func (t *TriggerSchedule) SetAttributes(mod *Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
@@ -338,6 +341,9 @@ func (t *TriggerSchedule) SetAttributes(mod *Mod, trigger *Trigger, hclAttribute
 					Subject:  &attr.Range,
 				})
 			}
+		case schema.AttributeTypeTimezone, schema.AttributeTypeStartTime, schema.AttributeTypeEndTime,
+			schema.AttributeTypeJitter, schema.AttributeTypeExcludeDates, schema.AttributeTypeCalendar:
+			diags = append(diags, t.TriggerScheduleOptions.SetAttribute(trigger, attr, evalContext)...)
 		default:
 			if !trigger.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -348,6 +354,10 @@ func (t *TriggerSchedule) SetAttributes(mod *Mod, trigger *Trigger, hclAttribute
 			}
 		}
 	}
+
+	if !diags.HasErrors() {
+		diags = append(diags, t.TriggerScheduleOptions.validateWindow(hclAttributes)...)
+	}
 	return diags
 }
 
@@ -364,6 +374,8 @@ type TriggerQuery struct {
 	Database   string                          `json:"database"`
 	PrimaryKey string                          `json:"primary_key"`
 	Captures   map[string]*TriggerQueryCapture `json:"captures"`
+
+	TriggerScheduleOptions
 }
 
 func (t *TriggerQuery) GetType() string {
@@ -400,6 +412,10 @@ func (t *TriggerQuery) Equals(other TriggerConfig) bool {
 		return false
 	}
 
+	if !t.TriggerScheduleOptions.Equals(&otherTrigger.TriggerScheduleOptions) {
+		return false
+	}
+
 	if len(t.Captures) != len(otherTrigger.Captures) {
 		return false
 	}
@@ -504,6 +520,9 @@ func (t *TriggerQuery) SetAttributes(mod *Mod, trigger *Trigger, hclAttributes h
 
 			t.PrimaryKey = val.AsString()
 
+		case schema.AttributeTypeTimezone, schema.AttributeTypeStartTime, schema.AttributeTypeEndTime,
+			schema.AttributeTypeJitter, schema.AttributeTypeExcludeDates, schema.AttributeTypeCalendar:
+			diags = append(diags, t.TriggerScheduleOptions.SetAttribute(trigger, attr, evalContext)...)
 		default:
 			if !trigger.IsBaseAttribute(name) {
 				diags = append(diags, &hcl.Diagnostic{
@@ -515,6 +534,10 @@ func (t *TriggerQuery) SetAttributes(mod *Mod, trigger *Trigger, hclAttributes h
 		}
 	}
 
+	if !diags.HasErrors() {
+		diags = append(diags, t.TriggerScheduleOptions.validateWindow(hclAttributes)...)
+	}
+
 	return diags
 }
 
@@ -634,6 +657,7 @@ type TriggerHttp struct {
 	Url           string                        `json:"url"`
 	ExecutionMode string                        `json:"execution_mode"`
 	Methods       map[string]*TriggerHTTPMethod `json:"methods"`
+	Verify        *TriggerHttpVerify            `json:"verify,omitempty"`
 }
 
 func (t *TriggerHttp) GetType() string {
@@ -662,6 +686,10 @@ func (t *TriggerHttp) Equals(other TriggerConfig) bool {
 		return false
 	}
 
+	if !t.Verify.Equals(otherTrigger.Verify) {
+		return false
+	}
+
 	if len(t.Methods) != len(otherTrigger.Methods) {
 		return false
 	}
@@ -768,9 +796,41 @@ func (t *TriggerHttp) SetBlocks(mod *Mod, trigger *Trigger, hclBlocks hcl.Blocks
 	diags := hcl.Diagnostics{}
 
 	t.Methods = make(map[string]*TriggerHTTPMethod)
+	t.Verify = nil
+
+	var methodBlocks hcl.Blocks
+	for _, block := range hclBlocks {
+		switch block.Type {
+		case schema.BlockTypeMethod:
+			methodBlocks = append(methodBlocks, block)
+		case schema.BlockTypeVerify:
+			if t.Verify != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Only one verify block is allowed per trigger",
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			verifyOptions, moreDiags := block.Body.Content(TriggerHttpVerifyBlockSchema)
+			if moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			verify := &TriggerHttpVerify{}
+			moreDiags = verify.SetAttributes(block, verifyOptions.Attributes, evalContext)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			t.Verify = verify
+		}
+	}
 
 	// If no method blocks appear, only 'post' is supported, and the top-level `pipeline`, `args` and `execution_mode` will be applied
-	if len(hclBlocks) == 0 {
+	if len(methodBlocks) == 0 {
 		triggerMethod := &TriggerHTTPMethod{
 			Type: HttpMethodPost,
 		}
@@ -804,7 +864,7 @@ func (t *TriggerHttp) SetBlocks(mod *Mod, trigger *Trigger, hclBlocks hcl.Blocks
 	}
 
 	// If the method blocks provided, we will consider the configuration provided in the method block
-	for _, methodBlock := range hclBlocks {
+	for _, methodBlock := range methodBlocks {
 
 		if len(methodBlock.Labels) != 1 {
 			diags = append(diags, &hcl.Diagnostic{
@@ -969,6 +1029,8 @@ func NewTrigger(block *hcl.Block, mod *Mod, triggerType, triggerName string) *Tr
 		trigger.Config = &TriggerQuery{}
 	case schema.TriggerTypeHttp:
 		trigger.Config = &TriggerHttp{}
+	case schema.TriggerTypeFile:
+		trigger.Config = &TriggerFile{}
 	default:
 		return nil
 	}
@@ -985,6 +1047,8 @@ func GetTriggerTypeFromTriggerConfig(config TriggerConfig) string {
 		return schema.TriggerTypeQuery
 	case *TriggerHttp:
 		return schema.TriggerTypeHttp
+	case *TriggerFile:
+		return schema.TriggerTypeFile
 	}
 
 	return ""
@@ -0,0 +1,384 @@
+package modconfig
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/hcl/v2"
+	filehelpers "github.com/turbot/go-kit/files"
+	"github.com/turbot/go-kit/filewatcher"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	FileTriggerEventCreate = "create"
+	FileTriggerEventModify = "modify"
+	FileTriggerEventDelete = "delete"
+
+	DefaultFileTriggerDebounce = time.Second
+)
+
+var validFileTriggerEvents = []string{FileTriggerEventCreate, FileTriggerEventModify, FileTriggerEventDelete}
+
+// TriggerFile runs the pipeline when a file matching the path glob is created, modified or deleted.
+//
+// The event that fired the trigger is available to the trigger args as self.event, self.path, self.size and
+// self.mod_time.
+type TriggerFile struct {
+	Path     string   `json:"path"`
+	Events   []string `json:"events,omitempty"`
+	Debounce string   `json:"debounce,omitempty"`
+	Ignore   []string `json:"ignore,omitempty"`
+}
+
+func (t *TriggerFile) GetType() string {
+	return schema.TriggerTypeFile
+}
+
+func (t *TriggerFile) Equals(other TriggerConfig) bool {
+	otherTrigger, ok := other.(*TriggerFile)
+	if !ok {
+		return false
+	}
+
+	if t == nil && !helpers.IsNil(otherTrigger) || t != nil && helpers.IsNil(otherTrigger) {
+		return false
+	}
+
+	if t == nil && helpers.IsNil(otherTrigger) {
+		return true
+	}
+
+	return t.Path == otherTrigger.Path &&
+		helpers.StringSliceEqualIgnoreOrder(t.Events, otherTrigger.Events) &&
+		t.Debounce == otherTrigger.Debounce &&
+		helpers.StringSliceEqualIgnoreOrder(t.Ignore, otherTrigger.Ignore)
+}
+
+func (t *TriggerFile) SetAttributes(mod *Mod, trigger *Trigger, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := trigger.SetBaseAttributes(mod, hclAttributes, evalContext)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case schema.AttributeTypePath:
+			path, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if *path == "" || !isValidFileTriggerGlob(*path) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid path glob: " + *path,
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			t.Path = *path
+
+		case schema.AttributeTypeEvents:
+			events, moreDiags := hclhelpers.AttributeToStringSlice(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			for _, event := range events {
+				if !slices.Contains(validFileTriggerEvents, event) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid event: " + event + ". Valid events are: " + strings.Join(validFileTriggerEvents, ", "),
+						Subject:  &attr.Range,
+					})
+				}
+			}
+
+			t.Events = events
+
+		case schema.AttributeTypeDebounce:
+			debounce, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			duration, err := time.ParseDuration(*debounce)
+			if err != nil || duration < 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid debounce interval: " + *debounce + ". Specify a duration such as 500ms or 5s",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+
+			t.Debounce = *debounce
+
+		case schema.AttributeTypeIgnore:
+			ignore, moreDiags := hclhelpers.AttributeToStringSlice(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			for _, pattern := range ignore {
+				if !isValidFileTriggerGlob(pattern) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid ignore pattern: " + pattern,
+						Subject:  &attr.Range,
+					})
+				}
+			}
+
+			t.Ignore = ignore
+
+		default:
+			if !trigger.IsBaseAttribute(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unsupported attribute for Trigger File: " + attr.Name,
+					Subject:  &attr.Range,
+				})
+			}
+		}
+	}
+
+	return diags
+}
+
+func (t *TriggerFile) SetBlocks(mod *Mod, trigger *Trigger, hclBlocks hcl.Blocks, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+	return diags
+}
+
+func isValidFileTriggerGlob(pattern string) bool {
+	_, err := filepath.Match(pattern, "")
+	return err == nil
+}
+
+// GetEvents returns the events the trigger fires on, all events if none were specified
+func (t *TriggerFile) GetEvents() []string {
+	if len(t.Events) == 0 {
+		return validFileTriggerEvents
+	}
+	return t.Events
+}
+
+// GetDebounce returns the quiet period a file must have before the trigger fires for it
+func (t *TriggerFile) GetDebounce() time.Duration {
+	if t.Debounce == "" {
+		return DefaultFileTriggerDebounce
+	}
+
+	// validated at parse time
+	duration, _ := time.ParseDuration(t.Debounce)
+	return duration
+}
+
+// ResolvePath returns the path glob as an absolute path, relative paths are resolved against baseDir (usually the
+// mod directory)
+func (t *TriggerFile) ResolvePath(baseDir string) string {
+	if filepath.IsAbs(t.Path) {
+		return filepath.Clean(t.Path)
+	}
+	return filepath.Join(baseDir, t.Path)
+}
+
+// Matches returns true if the event for the given file must fire the trigger, i.e. the file matches the path glob,
+// does not match any of the ignore patterns and the event is one of the trigger events.
+//
+// Ignore patterns without a directory separator are matched against the file name only.
+func (t *TriggerFile) Matches(baseDir string, path string, event string) bool {
+	if !slices.Contains(t.GetEvents(), event) {
+		return false
+	}
+
+	if !filehelpers.Match(t.ResolvePath(baseDir), path) {
+		return false
+	}
+
+	for _, pattern := range t.Ignore {
+		if !strings.Contains(pattern, string(os.PathSeparator)) {
+			if filehelpers.Match(pattern, filepath.Base(path)) {
+				return false
+			}
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		if filehelpers.Match(pattern, path) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FileTriggerEvent is a single file event that fires a file trigger
+type FileTriggerEvent struct {
+	Event   string    `json:"event"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// NewFileTriggerEvent builds the event for the file, size and mod time are only set if the file still exists
+func NewFileTriggerEvent(path string, event string) FileTriggerEvent {
+	res := FileTriggerEvent{
+		Event: event,
+		Path:  path,
+	}
+
+	if event == FileTriggerEventDelete {
+		return res
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		res.Size = info.Size()
+		res.ModTime = info.ModTime()
+	}
+
+	return res
+}
+
+// CtyValue returns the value of "self" in the eval context used to resolve the trigger args
+func (e FileTriggerEvent) CtyValue() cty.Value {
+	modTime := cty.StringVal("")
+	if !e.ModTime.IsZero() {
+		modTime = cty.StringVal(e.ModTime.UTC().Format(time.RFC3339))
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"event":    cty.StringVal(e.Event),
+		"path":     cty.StringVal(e.Path),
+		"size":     cty.NumberIntVal(e.Size),
+		"mod_time": modTime,
+	})
+}
+
+// fileTriggerEventFromFsnotify maps a fsnotify operation to the file trigger event, renaming a file away is a delete
+func fileTriggerEventFromFsnotify(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return FileTriggerEventCreate
+	case op.Has(fsnotify.Write):
+		return FileTriggerEventModify
+	case op.Has(fsnotify.Remove), op.Has(fsnotify.Rename):
+		return FileTriggerEventDelete
+	}
+	return ""
+}
+
+// NewFileTriggerWatcher creates a file watcher which calls the handler for every event that fires the trigger.
+//
+// Events are debounced per file: the handler is called once the file has had no further events for the debounce
+// interval, with the last event received for the file. The watcher must be started by the caller.
+func NewFileTriggerWatcher(t *TriggerFile, baseDir string, handler func(FileTriggerEvent), errorHandler func(error)) (*filewatcher.FileWatcher, error) {
+	path := t.ResolvePath(baseDir)
+
+	debouncer := newFileTriggerDebouncer(t.GetDebounce(), handler)
+
+	watcherOptions := &filewatcher.WatcherOptions{
+		Directories: []string{fileTriggerGlobRoot(path)},
+		Include:     []string{path},
+		ListFlag:    fileTriggerListFlag(path),
+		EventMask:   fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename,
+		OnError:     errorHandler,
+		OnChange: func(events []fsnotify.Event) {
+			for _, ev := range events {
+				event := fileTriggerEventFromFsnotify(ev.Op)
+				if event == "" || !t.Matches(baseDir, ev.Name, event) {
+					continue
+				}
+				debouncer.add(ev.Name, event)
+			}
+		},
+	}
+
+	return filewatcher.NewWatcher(watcherOptions)
+}
+
+// fileTriggerListFlag returns whether the files of the sub directories of the glob root must be watched, i.e. the
+// glob has a ** or a wildcard in a directory name
+func fileTriggerListFlag(glob string) filehelpers.ListFlag {
+	if strings.Contains(glob, "**") || strings.ContainsAny(filepath.Dir(glob), "*?[") {
+		return filehelpers.FilesRecursive
+	}
+	return filehelpers.FilesFlat
+}
+
+// fileTriggerGlobRoot returns the longest leading directory of the glob which does not contain a wildcard
+func fileTriggerGlobRoot(glob string) string {
+	dir := filepath.Dir(glob)
+	for strings.ContainsAny(dir, "*?[") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+type fileTriggerDebouncer struct {
+	interval time.Duration
+	handler  func(FileTriggerEvent)
+
+	lock   sync.Mutex
+	timers map[string]*time.Timer
+	events map[string]string
+}
+
+func newFileTriggerDebouncer(interval time.Duration, handler func(FileTriggerEvent)) *fileTriggerDebouncer {
+	return &fileTriggerDebouncer{
+		interval: interval,
+		handler:  handler,
+		timers:   map[string]*time.Timer{},
+		events:   map[string]string{},
+	}
+}
+
+func (d *fileTriggerDebouncer) add(path string, event string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// a file created then modified within the interval is still a create
+	if d.events[path] != FileTriggerEventCreate || event == FileTriggerEventDelete {
+		d.events[path] = event
+	}
+
+	if timer, ok := d.timers[path]; ok {
+		timer.Reset(d.interval)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d.interval, func() {
+		d.lock.Lock()
+		// the timer was reset after it fired, so it fires again once the event has been handled, by which time it
+		// may have been replaced by the timer of a new event for the file
+		if d.timers[path] != timer {
+			d.lock.Unlock()
+			return
+		}
+		event := d.events[path]
+		delete(d.events, path)
+		delete(d.timers, path)
+		d.lock.Unlock()
+
+		d.handler(NewFileTriggerEvent(path, event))
+	})
+	d.timers[path] = timer
+}
@@ -0,0 +1,177 @@
+package modconfig
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	filehelpers "github.com/turbot/go-kit/files"
+)
+
+const testFileTriggerDebounce = 20 * time.Millisecond
+
+// fileTriggerEventRecorder is a file trigger handler recording the events it is called with
+type fileTriggerEventRecorder struct {
+	lock   sync.Mutex
+	events []FileTriggerEvent
+}
+
+func (r *fileTriggerEventRecorder) handle(event FileTriggerEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *fileTriggerEventRecorder) recorded() []FileTriggerEvent {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]FileTriggerEvent(nil), r.events...)
+}
+
+func TestFileTriggerDebouncer(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    []string
+		wantEvent string
+	}{
+		{name: "create then modify", events: []string{FileTriggerEventCreate, FileTriggerEventModify, FileTriggerEventModify}, wantEvent: FileTriggerEventCreate},
+		{name: "create then delete", events: []string{FileTriggerEventCreate, FileTriggerEventModify, FileTriggerEventDelete}, wantEvent: FileTriggerEventDelete},
+		{name: "modify then delete", events: []string{FileTriggerEventModify, FileTriggerEventDelete}, wantEvent: FileTriggerEventDelete},
+		{name: "delete then create", events: []string{FileTriggerEventDelete, FileTriggerEventCreate}, wantEvent: FileTriggerEventCreate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fileTriggerEventRecorder{}
+			debouncer := newFileTriggerDebouncer(testFileTriggerDebounce, recorder.handle)
+
+			path := filepath.Join(t.TempDir(), "data.csv")
+			for _, event := range tt.events {
+				debouncer.add(path, event)
+			}
+
+			assert.Eventually(t, func() bool { return len(recorder.recorded()) > 0 }, time.Second, testFileTriggerDebounce)
+			// the handler is not called again
+			time.Sleep(3 * testFileTriggerDebounce)
+
+			events := recorder.recorded()
+			require.Equal(t, 1, len(events))
+			assert.Equal(t, tt.wantEvent, events[0].Event)
+			assert.Equal(t, path, events[0].Path)
+		})
+	}
+}
+
+func TestFileTriggerDebouncerPerFile(t *testing.T) {
+	recorder := &fileTriggerEventRecorder{}
+	debouncer := newFileTriggerDebouncer(testFileTriggerDebounce, recorder.handle)
+
+	dir := t.TempDir()
+	debouncer.add(filepath.Join(dir, "a.csv"), FileTriggerEventModify)
+	debouncer.add(filepath.Join(dir, "b.csv"), FileTriggerEventDelete)
+
+	assert.Eventually(t, func() bool { return len(recorder.recorded()) == 2 }, time.Second, testFileTriggerDebounce)
+}
+
+func TestFileTriggerDebouncerResetWhileFiring(t *testing.T) {
+	recorder := &fileTriggerEventRecorder{}
+	debouncer := newFileTriggerDebouncer(testFileTriggerDebounce, recorder.handle)
+	path := filepath.Join(t.TempDir(), "data.csv")
+
+	debouncer.add(path, FileTriggerEventCreate)
+
+	// hold the lock until the timer has fired, so the event below resets a timer whose function is already running
+	debouncer.lock.Lock()
+	time.Sleep(3 * testFileTriggerDebounce)
+	timer := debouncer.timers[path]
+	timer.Reset(debouncer.interval)
+	debouncer.events[path] = FileTriggerEventCreate
+	debouncer.lock.Unlock()
+
+	assert.Eventually(t, func() bool { return len(recorder.recorded()) > 0 }, time.Second, testFileTriggerDebounce)
+	// the reset timer fires again, without calling the handler
+	time.Sleep(3 * testFileTriggerDebounce)
+	assert.Equal(t, 1, len(recorder.recorded()))
+
+	// a new event for the file is handled once the reset timer has fired
+	debouncer.add(path, FileTriggerEventDelete)
+	assert.Eventually(t, func() bool { return len(recorder.recorded()) == 2 }, time.Second, testFileTriggerDebounce)
+	time.Sleep(3 * testFileTriggerDebounce)
+	events := recorder.recorded()
+	require.Equal(t, 2, len(events))
+	assert.Equal(t, FileTriggerEventDelete, events[1].Event)
+}
+
+func TestFileTriggerGlobRoot(t *testing.T) {
+	tests := []struct {
+		glob     string
+		wantRoot string
+		wantFlag filehelpers.ListFlag
+	}{
+		{glob: "/data/*.csv", wantRoot: "/data", wantFlag: filehelpers.FilesFlat},
+		{glob: "/data/report.csv", wantRoot: "/data", wantFlag: filehelpers.FilesFlat},
+		{glob: "/data/**/*.csv", wantRoot: "/data", wantFlag: filehelpers.FilesRecursive},
+		{glob: "/data/**", wantRoot: "/data", wantFlag: filehelpers.FilesRecursive},
+		{glob: "/data/2024-*/in/*.csv", wantRoot: "/data", wantFlag: filehelpers.FilesRecursive},
+		{glob: "/data/in/[ab]/*.csv", wantRoot: "/data/in", wantFlag: filehelpers.FilesRecursive},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantRoot, fileTriggerGlobRoot(tt.glob), tt.glob)
+		assert.Equal(t, tt.wantFlag, fileTriggerListFlag(tt.glob), tt.glob)
+	}
+}
+
+func TestNewFileTriggerWatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		wantPaths []string
+	}{
+		{name: "flat", path: "data/*.csv", wantPaths: []string{"data/a.csv"}},
+		{name: "recursive", path: "data/**/*.csv", wantPaths: []string{"data/a.csv", "data/sub/b.csv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "data", "sub"), 0755))
+			files := []string{"data/a.csv", "data/sub/b.csv", "data/notes.txt"}
+			for _, file := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("1"), 0600))
+			}
+
+			recorder := &fileTriggerEventRecorder{}
+			trigger := &TriggerFile{Path: tt.path, Debounce: testFileTriggerDebounce.String()}
+			watcher, err := NewFileTriggerWatcher(trigger, dir, recorder.handle, func(error) {})
+			require.NoError(t, err)
+			watcher.Start()
+			defer watcher.Close()
+
+			// the existing files are watched, so modifying them raises the events immediately
+			for _, file := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("2"), 0600))
+			}
+
+			var wantPaths []string
+			for _, path := range tt.wantPaths {
+				wantPaths = append(wantPaths, filepath.Join(dir, path))
+			}
+			assert.Eventually(t, func() bool { return len(recorder.recorded()) >= len(wantPaths) }, 5*time.Second, 50*time.Millisecond)
+			// the watcher waits before handling the events, give it time to raise unexpected ones
+			time.Sleep(500 * time.Millisecond)
+
+			var gotPaths []string
+			for _, event := range recorder.recorded() {
+				assert.Equal(t, FileTriggerEventModify, event.Event)
+				assert.Equal(t, int64(1), event.Size)
+				gotPaths = append(gotPaths, event.Path)
+			}
+			assert.ElementsMatch(t, wantPaths, gotPaths)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package modconfig
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/turbot/pipe-fittings/webhook"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TriggerHttpVerify is the "verify" block of an HTTP trigger, requests without a valid HMAC signature are rejected.
+//
+// The secret must reference a variable or a connection so it is not stored in the mod. Connection references are
+// only resolved when the request is received, ConnectionDependsOn lists the connections the caller has to add to the
+// eval context passed to GetVerifier.
+type TriggerHttpVerify struct {
+	Type      string `json:"type"`
+	Header    string `json:"header,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Tolerance string `json:"tolerance,omitempty"`
+
+	SecretRaw           hcl.Expression `json:"-"`
+	ConnectionDependsOn []string       `json:"connection_depends_on,omitempty"`
+}
+
+func (v *TriggerHttpVerify) Equals(other *TriggerHttpVerify) bool {
+	if v == nil && other == nil {
+		return true
+	}
+
+	if v == nil && other != nil || v != nil && other == nil {
+		return false
+	}
+
+	return v.Type == other.Type &&
+		v.Header == other.Header &&
+		v.Prefix == other.Prefix &&
+		v.Tolerance == other.Tolerance &&
+		hclhelpers.ExpressionsEqual(v.SecretRaw, other.SecretRaw) &&
+		helpers.StringSliceEqualIgnoreOrder(v.ConnectionDependsOn, other.ConnectionDependsOn)
+}
+
+func (v *TriggerHttpVerify) SetAttributes(verifyBlock *hcl.Block, hclAttributes hcl.Attributes, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	for name, attr := range hclAttributes {
+		switch name {
+		case schema.AttributeTypeType:
+			signatureType, moreDiags := hclhelpers.AttributeToString(attr, evalContext, false)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if !webhook.IsValidSignatureType(*signatureType) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid verify type: " + *signatureType,
+					Detail:   "The verify type must be one of: " + strings.Join(webhook.ValidSignatureTypes, ","),
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			v.Type = *signatureType
+
+		case schema.AttributeTypeSecret:
+			diags = append(diags, v.setSecret(attr, evalContext)...)
+
+		case schema.AttributeTypeHeader, schema.AttributeTypePrefix:
+			val, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			if name == schema.AttributeTypeHeader {
+				v.Header = *val
+			} else {
+				v.Prefix = *val
+			}
+
+		case schema.AttributeTypeTolerance:
+			tolerance, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+			if moreDiags != nil && moreDiags.HasErrors() {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+
+			duration, err := time.ParseDuration(*tolerance)
+			if err != nil || duration <= 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid tolerance: " + *tolerance + ". Specify a duration such as 5m",
+					Subject:  &attr.Range,
+				})
+				continue
+			}
+			v.Tolerance = *tolerance
+
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unsupported attribute for verify block: " + attr.Name,
+				Subject:  &attr.Range,
+			})
+		}
+	}
+
+	if diags.HasErrors() {
+		return diags
+	}
+
+	// header and prefix are fixed by the other signature types
+	if v.Type != webhook.SignatureTypeHmacSha256 && (v.Header != "" || v.Prefix != "") {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The header and prefix attributes are only supported by the " + webhook.SignatureTypeHmacSha256 + " verify type",
+			Subject:  &verifyBlock.DefRange,
+		})
+	}
+
+	if v.Tolerance != "" && v.Type != webhook.SignatureTypeSlack && v.Type != webhook.SignatureTypeStripe {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The tolerance attribute is only supported by the " + webhook.SignatureTypeSlack + " and " + webhook.SignatureTypeStripe + " verify types",
+			Subject:  &verifyBlock.DefRange,
+		})
+	}
+
+	return diags
+}
+
+func (v *TriggerHttpVerify) setSecret(attr *hcl.Attribute, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	traversals := attr.Expr.Variables()
+
+	if len(traversals) == 0 {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The secret attribute of the verify block must reference a variable or a connection",
+			Subject:  &attr.Range,
+		}}
+	}
+
+	for _, traversal := range traversals {
+		root := traversal.RootName()
+		if root != schema.AttributeVar && root != schema.BlockTypeConnection {
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "The secret attribute of the verify block must reference a variable or a connection, found: " + root,
+				Subject:  &attr.Range,
+			}}
+		}
+	}
+
+	_, _, connectionDependsOn := allDependsOnFromVariables(traversals)
+	v.ConnectionDependsOn = connectionDependsOn
+	v.SecretRaw = attr.Expr
+
+	// connections are only available at runtime, a variable can be checked now
+	if len(connectionDependsOn) > 0 {
+		return hcl.Diagnostics{}
+	}
+
+	val, diags := attr.Expr.Value(evalContext)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	if val.Type() != cty.String {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to parse " + attr.Name + " attribute as string",
+			Subject:  &attr.Range,
+		}}
+	}
+
+	return hcl.Diagnostics{}
+}
+
+// GetVerifier resolves the secret and returns the verifier for the requests received by the trigger. The eval
+// context must include the connections listed in ConnectionDependsOn.
+func (v *TriggerHttpVerify) GetVerifier(evalContext *hcl.EvalContext) (*webhook.Verifier, hcl.Diagnostics) {
+	if v.SecretRaw == nil {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Missing secret for verify block",
+		}}
+	}
+
+	val, diags := v.SecretRaw.Value(evalContext)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if val.IsNull() || val.Type() != cty.String || val.AsString() == "" {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "The secret of the verify block must resolve to a non-empty string",
+			Subject:  v.SecretRaw.Range().Ptr(),
+		}}
+	}
+
+	verifier := webhook.NewVerifier(v.Type, val.AsString())
+	verifier.Header = v.Header
+	verifier.Prefix = v.Prefix
+	if v.Tolerance != "" {
+		// validated at parse time
+		verifier.Tolerance, _ = time.ParseDuration(v.Tolerance)
+	}
+
+	return verifier, diags
+}
@@ -0,0 +1,246 @@
+package modconfig
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+const OpenApiVersion = "3.1.0"
+
+// OpenApiDocument is the subset of an OpenAPI 3.1 document needed to describe the HTTP triggers of a mod
+type OpenApiDocument struct {
+	OpenApi    string                      `json:"openapi"`
+	Info       OpenApiInfo                 `json:"info"`
+	Paths      map[string]*OpenApiPathItem `json:"paths"`
+	Components *OpenApiComponents          `json:"components,omitempty"`
+}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenApiPathItem struct {
+	Summary     string            `json:"summary,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Get         *OpenApiOperation `json:"get,omitempty"`
+	Post        *OpenApiOperation `json:"post,omitempty"`
+}
+
+type OpenApiOperation struct {
+	OperationId string                      `json:"operationId"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	RequestBody *OpenApiRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenApiResponse `json:"responses"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*OpenApiMediaType `json:"content"`
+}
+
+type OpenApiResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiMediaType struct {
+	Schema *JsonSchema `json:"schema"`
+}
+
+type OpenApiComponents struct {
+	Schemas map[string]*JsonSchema `json:"schemas,omitempty"`
+}
+
+// NewOpenApiDocument builds an OpenAPI document covering every HTTP trigger in the given trigger map.
+//
+// The request body of each trigger method is the JSON Schema of the params of the pipeline the method
+// targets. Synchronous methods also document the pipeline outputs as the response body.
+//
+// The trigger URL is assigned by Flowpipe at runtime, if it has not been set the path is derived from the
+// trigger name.
+func NewOpenApiDocument(title, version string, pipelines map[string]*Pipeline, triggers map[string]*Trigger) (*OpenApiDocument, error) {
+	doc := &OpenApiDocument{
+		OpenApi: OpenApiVersion,
+		Info: OpenApiInfo{
+			Title:   title,
+			Version: version,
+		},
+		Paths: map[string]*OpenApiPathItem{},
+		Components: &OpenApiComponents{
+			Schemas: map[string]*JsonSchema{},
+		},
+	}
+
+	// need stable order otherwise the document changes on every run
+	triggerNames := make([]string, 0, len(triggers))
+	for name, trigger := range triggers {
+		if _, ok := trigger.Config.(*TriggerHttp); ok {
+			triggerNames = append(triggerNames, name)
+		}
+	}
+	sort.Strings(triggerNames)
+
+	for _, name := range triggerNames {
+		trigger := triggers[name]
+		pathItem, err := openApiPathItemForTrigger(doc, pipelines, trigger)
+		if err != nil {
+			return nil, err
+		}
+
+		path := openApiPathForTrigger(trigger)
+		if doc.Paths[path] != nil {
+			return nil, perr.BadRequestWithMessage(fmt.Sprintf("duplicate path %s for trigger %s", path, trigger.FullName))
+		}
+		doc.Paths[path] = pathItem
+	}
+
+	return doc, nil
+}
+
+// OpenApiDocument builds an OpenAPI document covering every HTTP trigger in the mod
+func (m *Mod) OpenApiDocument() (*OpenApiDocument, error) {
+	title := m.GetTitle()
+	if title == "" {
+		title = m.ShortName
+	}
+
+	version := "0.0.0"
+	if m.Version != nil && m.Version.Version != nil {
+		version = m.Version.Version.String()
+	}
+
+	doc, err := NewOpenApiDocument(title, version, m.ResourceMaps.Pipelines, m.ResourceMaps.Triggers)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Description != nil {
+		doc.Info.Description = *m.Description
+	}
+
+	return doc, nil
+}
+
+func openApiPathForTrigger(trigger *Trigger) string {
+	triggerConfig := trigger.Config.(*TriggerHttp)
+	if triggerConfig.Url != "" {
+		if u, err := url.Parse(triggerConfig.Url); err == nil && u.Path != "" {
+			return u.Path
+		}
+	}
+	return "/hook/" + trigger.FullName
+}
+
+func openApiPathItemForTrigger(doc *OpenApiDocument, pipelines map[string]*Pipeline, trigger *Trigger) (*OpenApiPathItem, error) {
+	triggerConfig := trigger.Config.(*TriggerHttp)
+
+	pathItem := &OpenApiPathItem{}
+	if trigger.Title != nil {
+		pathItem.Summary = *trigger.Title
+	}
+	if trigger.Description != nil {
+		pathItem.Description = *trigger.Description
+	}
+
+	for methodType, method := range triggerConfig.Methods {
+		pipeline, err := pipelineForTriggerMethod(pipelines, trigger, method)
+		if err != nil {
+			return nil, err
+		}
+
+		operation := &OpenApiOperation{
+			OperationId: strings.ReplaceAll(trigger.FullName, ".", "_") + "_" + methodType,
+			Summary:     pipelineSummary(pipeline),
+			Tags:        []string{pipeline.FullName},
+			Responses:   map[string]*OpenApiResponse{},
+		}
+
+		paramsRef := addOpenApiComponentSchema(doc, pipeline.FullName+".params", pipeline.ParamsJsonSchema())
+
+		// GET requests do not have a body, the pipeline args are taken from the trigger args
+		if methodType == HttpMethodPost {
+			operation.RequestBody = &OpenApiRequestBody{
+				Required: hasRequiredParams(pipeline),
+				Content: map[string]*OpenApiMediaType{
+					"application/json": {Schema: paramsRef},
+				},
+			}
+		}
+
+		if method.ExecutionMode == "synchronous" {
+			outputsRef := addOpenApiComponentSchema(doc, pipeline.FullName+".outputs", pipeline.OutputsJsonSchema())
+			operation.Responses["200"] = &OpenApiResponse{
+				Description: "Pipeline outputs",
+				Content: map[string]*OpenApiMediaType{
+					"application/json": {Schema: outputsRef},
+				},
+			}
+		} else {
+			operation.Responses["200"] = &OpenApiResponse{
+				Description: "Pipeline execution queued",
+			}
+		}
+
+		switch methodType {
+		case HttpMethodGet:
+			pathItem.Get = operation
+		case HttpMethodPost:
+			pathItem.Post = operation
+		}
+	}
+
+	return pathItem, nil
+}
+
+func pipelineForTriggerMethod(pipelines map[string]*Pipeline, trigger *Trigger, method *TriggerHTTPMethod) (*Pipeline, error) {
+	if method.Pipeline.IsNull() || !method.Pipeline.Type().IsObjectType() || !method.Pipeline.Type().HasAttribute(schema.AttributeTypeName) {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("trigger %s method %s does not reference a pipeline", trigger.FullName, method.Type))
+	}
+
+	pipelineName := method.Pipeline.GetAttr(schema.AttributeTypeName).AsString()
+	pipeline := pipelines[pipelineName]
+	if pipeline == nil {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("pipeline %s referenced by trigger %s not found", pipelineName, trigger.FullName))
+	}
+	return pipeline, nil
+}
+
+// addOpenApiComponentSchema adds the schema to the document components and returns a reference to it
+func addOpenApiComponentSchema(doc *OpenApiDocument, name string, s *JsonSchema) *JsonSchema {
+	key := strings.ReplaceAll(name, ".", "_")
+	if doc.Components.Schemas[key] == nil {
+		// $schema is implied by the OpenAPI version, $id would change how the $ref is resolved
+		s.Schema = ""
+		s.Id = ""
+		doc.Components.Schemas[key] = s
+	}
+	return &JsonSchema{Ref: "#/components/schemas/" + key}
+}
+
+func hasRequiredParams(pipeline *Pipeline) bool {
+	for i := range pipeline.Params {
+		if pipeline.Params[i].IsRequired() {
+			return true
+		}
+	}
+	return false
+}
+
+func pipelineSummary(pipeline *Pipeline) string {
+	if pipeline.Title != nil {
+		return *pipeline.Title
+	}
+	if pipeline.Description != nil {
+		return *pipeline.Description
+	}
+	return pipeline.FullName
+}
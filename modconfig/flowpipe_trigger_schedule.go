@@ -0,0 +1,346 @@
+package modconfig
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/robfig/cron/v3"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+const (
+	// DefaultQueryTriggerSchedule is the schedule of a query trigger that does not specify one
+	DefaultQueryTriggerSchedule = "15m"
+
+	scheduleDateFormat = "2006-01-02"
+
+	// upper bound on the number of cron ticks inspected when looking for fire times, guards against windows and
+	// exclusions which never allow the trigger to fire
+	maxScheduleIterations = 100000
+)
+
+// TriggerScheduleOptions are the attributes shared by the triggers which run on a schedule (schedule and query
+// triggers) that restrict when the schedule fires.
+//
+// StartTime and EndTime are a daily window (HH:MM, in the trigger timezone) outside which the schedule does not
+// fire, the window may span midnight. ExcludeDates and the dates in the calendar file (YYYY-MM-DD, in the trigger
+// timezone) are days on which the schedule does not fire at all.
+type TriggerScheduleOptions struct {
+	Timezone     string   `json:"timezone,omitempty"`
+	StartTime    string   `json:"start_time,omitempty"`
+	EndTime      string   `json:"end_time,omitempty"`
+	Jitter       string   `json:"jitter,omitempty"`
+	ExcludeDates []string `json:"exclude_dates,omitempty"`
+
+	// Calendar is the path of the calendar file as specified in the trigger, CalendarDates are the dates read from it
+	Calendar      string   `json:"calendar,omitempty"`
+	CalendarDates []string `json:"calendar_dates,omitempty"`
+}
+
+func (o *TriggerScheduleOptions) Equals(other *TriggerScheduleOptions) bool {
+	if o == nil && other == nil {
+		return true
+	}
+
+	if o == nil && other != nil || o != nil && other == nil {
+		return false
+	}
+
+	return o.Timezone == other.Timezone &&
+		o.StartTime == other.StartTime &&
+		o.EndTime == other.EndTime &&
+		o.Jitter == other.Jitter &&
+		helpers.StringSliceEqualIgnoreOrder(o.ExcludeDates, other.ExcludeDates) &&
+		o.Calendar == other.Calendar &&
+		helpers.StringSliceEqualIgnoreOrder(o.CalendarDates, other.CalendarDates)
+}
+
+func (o *TriggerScheduleOptions) SetAttribute(trigger *Trigger, attr *hcl.Attribute, evalContext *hcl.EvalContext) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	switch attr.Name {
+	case schema.AttributeTypeTimezone:
+		timezone, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		if _, err := time.LoadLocation(*timezone); err != nil {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid timezone: " + *timezone,
+				Detail:   err.Error(),
+				Subject:  &attr.Range,
+			})
+		}
+		o.Timezone = *timezone
+
+	case schema.AttributeTypeStartTime, schema.AttributeTypeEndTime:
+		timeOfDay, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		if _, err := parseTimeOfDay(*timeOfDay); err != nil {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid " + attr.Name + ": " + *timeOfDay + ". Specify a time of day in the format HH:MM",
+				Subject:  &attr.Range,
+			})
+		}
+
+		if attr.Name == schema.AttributeTypeStartTime {
+			o.StartTime = *timeOfDay
+		} else {
+			o.EndTime = *timeOfDay
+		}
+
+	case schema.AttributeTypeJitter:
+		jitter, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		duration, err := time.ParseDuration(*jitter)
+		if err != nil || duration < 0 {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid jitter: " + *jitter + ". Specify a duration such as 30s or 5m",
+				Subject:  &attr.Range,
+			})
+		}
+		o.Jitter = *jitter
+
+	case schema.AttributeTypeExcludeDates:
+		dates, moreDiags := hclhelpers.AttributeToStringSlice(attr, evalContext, true)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		for _, date := range dates {
+			if _, err := time.Parse(scheduleDateFormat, date); err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid date in " + attr.Name + ": " + date + ". Specify dates in the format YYYY-MM-DD",
+					Subject:  &attr.Range,
+				})
+			}
+		}
+		o.ExcludeDates = dates
+
+	case schema.AttributeTypeCalendar:
+		calendar, moreDiags := hclhelpers.AttributeToString(attr, evalContext, true)
+		if moreDiags != nil && moreDiags.HasErrors() {
+			return append(diags, moreDiags...)
+		}
+
+		// relative paths are relative to the file declaring the trigger
+		calendarPath := *calendar
+		if !filepath.IsAbs(calendarPath) && trigger != nil {
+			calendarPath = filepath.Join(filepath.Dir(trigger.DeclRange.Filename), calendarPath)
+		}
+
+		dates, err := readScheduleCalendar(calendarPath)
+		if err != nil {
+			return append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to read calendar file: " + *calendar,
+				Detail:   err.Error(),
+				Subject:  &attr.Range,
+			})
+		}
+		o.Calendar = *calendar
+		o.CalendarDates = dates
+	}
+
+	return diags
+}
+
+// validateWindow checks the daily window once all the attributes are set, a window which starts and ends at the same
+// time would always be open
+func (o *TriggerScheduleOptions) validateWindow(hclAttributes hcl.Attributes) hcl.Diagnostics {
+	if o.StartTime == "" && o.EndTime == "" {
+		return hcl.Diagnostics{}
+	}
+	if _, err := newDailyWindow(o.StartTime, o.EndTime, o.GetLocation()); err == nil {
+		return hcl.Diagnostics{}
+	}
+
+	attr := hclAttributes[schema.AttributeTypeEndTime]
+	if attr == nil {
+		attr = hclAttributes[schema.AttributeTypeStartTime]
+	}
+	return hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid " + schema.AttributeTypeEndTime + ": " + o.EndTime + ". The end time can not be the same as the start time",
+		Subject:  &attr.Range,
+	}}
+}
+
+// readScheduleCalendar reads the dates from a calendar file. The file is either a list of dates (YYYY-MM-DD), one
+// per line with # comments, or an iCalendar file in which case the all day events (DTSTART;VALUE=DATE) are read.
+func readScheduleCalendar(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var dates []string
+	lineNumber := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "DTSTART;VALUE=DATE:") {
+			date, err := time.Parse("20060102", strings.TrimPrefix(line, "DTSTART;VALUE=DATE:"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid date on line %d: %s", lineNumber, line)
+			}
+			dates = append(dates, date.Format(scheduleDateFormat))
+			continue
+		}
+
+		// any other iCalendar property, blank lines and comments are ignored
+		if line == "" || strings.HasPrefix(line, "#") || strings.Contains(line, ":") {
+			continue
+		}
+
+		if _, err := time.Parse(scheduleDateFormat, line); err != nil {
+			return nil, fmt.Errorf("invalid date on line %d: %s", lineNumber, line)
+		}
+		dates = append(dates, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return dates, nil
+}
+
+// GetLocation returns the location of the trigger timezone, the local timezone if no timezone is specified
+func (o *TriggerScheduleOptions) GetLocation() *time.Location {
+	if o.Timezone == "" {
+		return time.Local
+	}
+
+	// validated at parse time
+	location, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// GetJitter returns the maximum random delay added to each fire time
+func (o *TriggerScheduleOptions) GetJitter() time.Duration {
+	if o.Jitter == "" {
+		return 0
+	}
+
+	// validated at parse time
+	duration, _ := time.ParseDuration(o.Jitter)
+	return duration
+}
+
+// ApplyJitter delays the fire time by a random duration up to the jitter, so triggers sharing a schedule do not all
+// fire at the same instant
+func (o *TriggerScheduleOptions) ApplyJitter(fireTime time.Time) time.Time {
+	jitter := o.GetJitter()
+	if jitter <= 0 {
+		return fireTime
+	}
+	//nolint:gosec // jitter does not need a cryptographically secure random number
+	return fireTime.Add(time.Duration(rand.Int63n(int64(jitter))))
+}
+
+// IsAllowed returns true if the schedule may fire at the given time, i.e. the time is within the daily window and
+// not on an excluded date
+func (o *TriggerScheduleOptions) IsAllowed(fireTime time.Time) bool {
+	local := fireTime.In(o.GetLocation())
+
+	date := local.Format(scheduleDateFormat)
+	if slices.Contains(o.ExcludeDates, date) || slices.Contains(o.CalendarDates, date) {
+		return false
+	}
+
+	if o.StartTime == "" && o.EndTime == "" {
+		return true
+	}
+
+	// validated at parse time
+	window, err := newDailyWindow(o.StartTime, o.EndTime, o.GetLocation())
+	if err != nil {
+		return true
+	}
+	inside, _ := window.contains(fireTime)
+	return inside
+}
+
+// cronSchedule parses the trigger schedule (an interval or a cron expression) in the trigger timezone
+func (o *TriggerScheduleOptions) cronSchedule(schedule string) (cron.Schedule, error) {
+	spec := schedule
+
+	switch strings.ToLower(schedule) {
+	case "hourly", "daily", "weekly":
+		spec = "@" + strings.ToLower(schedule)
+	default:
+		if slices.Contains(validIntervals, strings.ToLower(schedule)) {
+			spec = "@every " + strings.ToLower(schedule)
+		}
+	}
+
+	return cron.ParseStandard("CRON_TZ=" + o.GetLocation().String() + " " + spec)
+}
+
+// NextFireTimes returns the next n times, after from, at which the schedule fires once the window and the excluded
+// dates have been applied. Jitter is not applied, the actual runs may be delayed by up to the jitter.
+func (o *TriggerScheduleOptions) NextFireTimes(schedule string, from time.Time, n int) ([]time.Time, error) {
+	sched, err := o.cronSchedule(schedule)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("invalid schedule " + schedule + ": " + err.Error())
+	}
+
+	var res []time.Time
+	next := from
+	for i := 0; i < maxScheduleIterations && len(res) < n; i++ {
+		next = sched.Next(next)
+		if next.IsZero() {
+			break
+		}
+
+		if o.IsAllowed(next) {
+			res = append(res, next)
+		}
+	}
+
+	return res, nil
+}
+
+// NextFireTimes returns the next n fire times of a schedule or query trigger after the given time
+func (t *Trigger) NextFireTimes(from time.Time, n int) ([]time.Time, error) {
+	switch config := t.Config.(type) {
+	case *TriggerSchedule:
+		return config.TriggerScheduleOptions.NextFireTimes(config.Schedule, from, n)
+	case *TriggerQuery:
+		schedule := config.Schedule
+		if schedule == "" {
+			schedule = DefaultQueryTriggerSchedule
+		}
+		return config.TriggerScheduleOptions.NextFireTimes(schedule, from, n)
+	}
+
+	return nil, perr.BadRequestWithMessage("trigger " + t.FullName + " does not run on a schedule")
+}
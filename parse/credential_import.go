@@ -48,7 +48,7 @@ func DecodeCredentialImport(configPath string, block *hcl.Block) (*credential.Cr
 
 	// build an eval context just containing functions
 	evalCtx := &hcl.EvalContext{
-		Functions: funcs.ContextFunctions(configPath),
+		Functions: funcs.ConfigContextFunctions(configPath),
 		Variables: make(map[string]cty.Value),
 	}
 
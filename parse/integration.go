@@ -59,7 +59,7 @@ func DecodeIntegration(configPath string, block *hcl.Block) (modconfig.Integrati
 
 	// build an eval context just containing functions
 	evalCtx := &hcl.EvalContext{
-		Functions: funcs.ContextFunctions(configPath),
+		Functions: funcs.ConfigContextFunctions(configPath),
 		Variables: make(map[string]cty.Value),
 	}
 
@@ -121,6 +121,9 @@ func NewModParseContext(workspaceLock *versionmap.WorkspaceLock, rootEvalPath st
 		},
 	}
 
+	// the secret functions are available to the root mod, NewChildModParseContext removes them for dependency mods
+	c.AllowSecretFunctions = true
+
 	// apply options
 	for _, opt := range opts {
 		opt(c)
@@ -144,6 +147,9 @@ func NewChildModParseContext(parent *ModParseContext, modVersion *versionmap.Res
 	child.ParentParseCtx = parent
 	// set the dependency config
 	child.DependencyConfig = NewDependencyConfig(modVersion)
+	// dependency mods are third party code, they must not read files or run commands through the secret functions
+	child.AllowSecretFunctions = false
+	child.buildEvalContext()
 	// set variables if parent has any
 	if parent.Variables != nil {
 		childVars, ok := parent.Variables.DependencyVariables[modVersion.Name]
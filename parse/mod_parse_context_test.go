@@ -0,0 +1,24 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/versionmap"
+)
+
+func TestSecretFunctionsNotInDependencyModEvalContext(t *testing.T) {
+	assert := assert.New(t)
+
+	parseCtx := NewModParseContext(nil, t.TempDir())
+	assert.Contains(parseCtx.EvalCtx.Functions, "exec_secret")
+
+	childCtx := NewChildModParseContext(parseCtx, &versionmap.ResolvedVersionConstraint{Name: "github.com/acme/dep"}, t.TempDir())
+	assert.NotContains(childCtx.EvalCtx.Functions, "exec_secret")
+	assert.NotContains(childCtx.EvalCtx.Functions, "vault_secret")
+	assert.NotContains(childCtx.EvalCtx.Functions, "secret_file")
+
+	// the eval context is rebuilt as the mod is parsed
+	childCtx.buildEvalContext()
+	assert.NotContains(childCtx.EvalCtx.Functions, "exec_secret")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/hclhelpers"
 	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
 )
@@ -19,15 +20,27 @@ var notifierBlockSchema = &hcl.BodySchema{
 			Name:     schema.AttributeTypeTitle,
 			Required: false,
 		},
+		{
+			Name:     schema.AttributeTypeDedupeWindow,
+			Required: false,
+		},
+		{
+			Name:     schema.AttributeTypeDedupeKey,
+			Required: false,
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
 			Type: schema.BlockTypeNotify,
 		},
+		{
+			Type: schema.BlockTypeRateLimit,
+		},
 	},
 }
 
-func DecodeNotifier(configPath string, block *hcl.Block, evalCtx *hcl.EvalContext) (*modconfig.NotifierImpl, hcl.Diagnostics) {
+// DecodeNotifier decodes a notifier block, fileData holds the source of the files the block was parsed from
+func DecodeNotifier(configPath string, block *hcl.Block, evalCtx *hcl.EvalContext, fileData map[string][]byte) (*modconfig.NotifierImpl, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 	if len(block.Labels) != 1 {
 		diags = hcl.Diagnostics{
@@ -74,11 +87,27 @@ func DecodeNotifier(configPath string, block *hcl.Block, evalCtx *hcl.EvalContex
 
 			notifier.Notifies = append(notifier.Notifies, notify)
 
-			validationDiags := notify.Validate()
+			validationDiags := notify.Validate(&b.DefRange)
 			if len(validationDiags) > 0 {
 				diags = append(diags, validationDiags...)
 				continue
 			}
+		case schema.BlockTypeRateLimit:
+			if notifier.RateLimit != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Only one " + schema.BlockTypeRateLimit + " block is allowed in a notifier",
+					Subject:  &b.DefRange,
+				})
+				continue
+			}
+			rateLimit := modconfig.NotifierRateLimit{}
+			moreDiags := gohcl.DecodeBody(b.Body, evalCtx, &rateLimit)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				continue
+			}
+			notifier.RateLimit = &rateLimit
 		default:
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
@@ -93,6 +122,26 @@ func DecodeNotifier(configPath string, block *hcl.Block, evalCtx *hcl.EvalContex
 		diags = append(diags, moreDiags...)
 	}
 
+	if attr, ok := content.Attributes[schema.AttributeTypeDedupeWindow]; ok {
+		notifier.DedupeWindow, moreDiags = hclhelpers.AttributeToString(attr, evalCtx, false)
+		diags = append(diags, moreDiags...)
+	}
+
+	// the dedupe key is evaluated for each message, so the source of the expression is kept
+	if attr, ok := content.Attributes[schema.AttributeTypeDedupeKey]; ok {
+		src, err := expressionSource(attr.Expr, fileData)
+		if err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unable to read the " + schema.AttributeTypeDedupeKey + " expression",
+				Detail:   err.Error(),
+				Subject:  &attr.Range,
+			})
+		} else {
+			notifier.DedupeKey = &src
+		}
+	}
+
 	validationDiags := notifier.Validate()
 	if len(validationDiags) > 0 {
 		diags = append(diags, validationDiags...)
@@ -102,3 +151,16 @@ func DecodeNotifier(configPath string, block *hcl.Block, evalCtx *hcl.EvalContex
 
 	return &notifier, diags
 }
+
+// expressionSource returns the source text of the expression from the data of the file it was parsed from
+func expressionSource(expr hcl.Expression, fileData map[string][]byte) (string, error) {
+	rng := expr.Range()
+	src, ok := fileData[rng.Filename]
+	if !ok {
+		return "", fmt.Errorf("the source of %s is not available", rng.Filename)
+	}
+	if rng.End.Byte > len(src) {
+		return "", fmt.Errorf("the expression is out of the range of %s", rng.Filename)
+	}
+	return extractExpressionString(expr, string(src)), nil
+}
@@ -0,0 +1,49 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+func TestDecodeNotifierDedupeKey(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// the file is only in memory, the dedupe key source is taken from the parsed data
+	fileData := map[string][]byte{
+		"/not/on/disk/notifiers.fpc": []byte(`notifier "alerts" {
+  dedupe_window = "10m"
+  dedupe_key    = "${message.pipeline}/${lower(message.subject)}"
+}
+`),
+	}
+	body, diags := ParseHclFiles(fileData)
+	require.False(diags.HasErrors())
+	content, diags := body.Content(&hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "notifier", LabelNames: []string{"name"}}}})
+	require.False(diags.HasErrors())
+
+	// the notifier has no notify block, only the dedupe key diagnostics are of interest
+	dedupeKeyDiags := func(diags hcl.Diagnostics) hcl.Diagnostics {
+		var res hcl.Diagnostics
+		for _, diag := range diags {
+			if strings.Contains(diag.Summary, schema.AttributeTypeDedupeKey) {
+				res = append(res, diag)
+			}
+		}
+		return res
+	}
+
+	notifier, diags := DecodeNotifier("/not/on/disk", content.Blocks[0], &hcl.EvalContext{}, fileData)
+	assert.Empty(dedupeKeyDiags(diags))
+	require.NotNil(notifier.DedupeKey)
+	assert.Equal(`"${message.pipeline}/${lower(message.subject)}"`, *notifier.DedupeKey)
+
+	// the source of the file is required
+	_, diags = DecodeNotifier("/not/on/disk", content.Blocks[0], &hcl.EvalContext{}, nil)
+	assert.NotEmpty(dedupeKeyDiags(diags))
+}
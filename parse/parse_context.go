@@ -24,6 +24,9 @@ type ParseContext struct {
 	Diags hcl.Diagnostics
 
 	RootEvalPath string
+	// AllowSecretFunctions adds the secret functions, which read files and run commands, to the eval context. It must
+	// only be set for trusted HCL, i.e. not for dependency mods.
+	AllowSecretFunctions bool
 
 	// if set, only decode these blocks
 	blockTypes map[string]struct{}
@@ -252,10 +255,14 @@ func (p *ParseContext) getDependencyOrder() ([]string, error) {
 
 func (p *ParseContext) BuildEvalContext(variables map[string]cty.Value) {
 	// create evaluation context
+	// use the RootEvalPath as the file root for functions
+	functions := funcs.ContextFunctions(p.RootEvalPath)
+	if p.AllowSecretFunctions {
+		functions = funcs.ConfigContextFunctions(p.RootEvalPath)
+	}
 	p.EvalCtx = &hcl.EvalContext{
 		Variables: variables,
-		// use the RootEvalPath as the file root for functions
-		Functions: funcs.ContextFunctions(p.RootEvalPath),
+		Functions: functions,
 	}
 }
 
@@ -776,6 +776,13 @@ func validatePipelineSteps(pipelineHcl *modconfig.Pipeline) hcl.Diagnostics {
 		}
 	}
 
+	// the compensate block can only be validated once all the steps are known
+	for _, step := range pipelineHcl.Steps {
+		if compensateConfig := step.GetCompensateConfig(); compensateConfig != nil {
+			diags = append(diags, compensateConfig.Validate(step, pipelineHcl)...)
+		}
+	}
+
 	return diags
 }
 
@@ -973,6 +980,8 @@ func GetTriggerBlockSchema(triggerType string) *hcl.BodySchema {
 		return modconfig.TriggerQueryBlockSchema
 	case schema.TriggerTypeHttp:
 		return modconfig.TriggerHttpBlockSchema
+	case schema.TriggerTypeFile:
+		return modconfig.TriggerFileBlockSchema
 	default:
 		return nil
 	}
@@ -986,6 +995,8 @@ func GetIntegrationBlockSchema(integrationType string) *hcl.BodySchema {
 		return modconfig.IntegrationEmailBlockSchema
 	case schema.IntegrationTypeMsTeams:
 		return modconfig.IntegrationTeamsBlockSchema
+	case schema.IntegrationTypeWebhook:
+		return modconfig.IntegrationWebhookBlockSchema
 	default:
 		return nil
 	}
@@ -0,0 +1,111 @@
+package parse
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/pipe-fittings/app_specific_connection"
+	"github.com/turbot/pipe-fittings/connection"
+	"github.com/turbot/pipe-fittings/credential"
+)
+
+// the Steampipe plugin arguments which are named differently in the pipeling connection, by connection type. The
+// pipeling connection attribute takes precedence if both are set.
+var steampipeConnectionAttributeAliases = map[string]map[string]string{
+	connection.AwsConnectionType: {
+		"default_region": "region",
+	},
+	connection.GitLabConnectionType: {
+		"baseurl": "base_url",
+	},
+	connection.IPstackConnectionType: {
+		"token": "access_key",
+	},
+	connection.JiraConnectionType: {
+		"token": "api_token",
+	},
+}
+
+// SteampipePluginConnectionType returns the pipeling connection type of a Steampipe plugin, or an empty string if
+// there is none registered
+func SteampipePluginConnectionType(plugin string) string {
+	// the credential types are named after the Steampipe plugins
+	connectionType := credential.ConnectionTypeForCredentialType(plugin)
+	if _, ok := app_specific_connection.ConnectionTypeRegistry[connectionType]; !ok {
+		return ""
+	}
+	return connectionType
+}
+
+// DecodeImportedPipelingConnection decodes the config of a Steampipe connection into a pipeling connection. The
+// plugin arguments the pipeling connection does not support, such as the regions of an aws connection, are ignored.
+func DecodeImportedPipelingConnection(connectionType, shortName string, body hcl.Body, declRange hcl.Range) (connection.PipelingConnection, hcl.Diagnostics) {
+	conn, err := app_specific_connection.NewPipelingConnection(connectionType, shortName, declRange)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("error creating connection: %s", err),
+				Subject:  &declRange,
+			},
+		}
+	}
+
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(conn)
+	supported := make(map[string]bool, len(schema.Attributes))
+	for _, attr := range schema.Attributes {
+		supported[attr.Name] = true
+	}
+
+	// build a body holding the supported attributes only, under their pipeling connection names
+	importBody := &hclsyntax.Body{
+		Attributes: hclsyntax.Attributes{},
+		SrcRange:   declRange,
+		EndRange:   declRange,
+	}
+	aliases := steampipeConnectionAttributeAliases[connectionType]
+	for name, attr := range attrs {
+		if alias, ok := aliases[name]; ok {
+			// the attribute under its pipeling connection name takes precedence
+			if _, ok := attrs[alias]; ok {
+				continue
+			}
+			name = alias
+		}
+		if !supported[name] {
+			continue
+		}
+
+		// the config of the Steampipe connection has been parsed from a string, evaluate the expressions so they can
+		// be placed in the body
+		val, moreDiags := attr.Expr.Value(nil)
+		diags = append(diags, moreDiags...)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		importBody.Attributes[name] = &hclsyntax.Attribute{
+			Name:      name,
+			Expr:      &hclsyntax.LiteralValueExpr{Val: val, SrcRange: attr.Expr.Range()},
+			SrcRange:  attr.Range,
+			NameRange: attr.NameRange,
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	diags = gohcl.DecodeBody(importBody, nil, conn)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	diags = append(diags, conn.Validate()...)
+	return conn, diags
+}
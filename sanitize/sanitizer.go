@@ -22,6 +22,8 @@ var Instance = NewSanitizer(SanitizerOptions{
 		"password",
 		"secretaccesskey",
 		"sessiontoken",
+		"session_token",
+		"client_key_pem",
 		"smtp_password",
 		"api_key",
 		"app_key",
@@ -88,7 +90,8 @@ var Instance = NewSanitizer(SanitizerOptions{
 		`AccountKey=[a-zA-Z0-9+/=]{88}`,              // azure storage account key
 		`(?m)(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9_]{36}`, // github_personal_access_token
 	},
-	ImportCodeMatchers: true,
+	ImportCodeMatchers:    true,
+	RedactSensitiveValues: true,
 })
 
 type SanitizerOptions struct {
@@ -98,12 +101,15 @@ type SanitizerOptions struct {
 	ExcludePatterns []string
 
 	ImportCodeMatchers bool
+	// RedactSensitiveValues redacts the values registered with RegisterSensitiveValue
+	RedactSensitiveValues bool
 }
 
 type Sanitizer struct {
-	regexes             []*regexp.Regexp
-	fieldPatternRegexes []*regexp.Regexp
-	excludeFields       map[string]struct{}
+	regexes               []*regexp.Regexp
+	fieldPatternRegexes   []*regexp.Regexp
+	excludeFields         map[string]struct{}
+	redactSensitiveValues bool
 }
 
 var codePluginExcludedRegex = []string{
@@ -115,7 +121,8 @@ var codePluginExcludedRegex = []string{
 
 func NewSanitizer(opts SanitizerOptions) *Sanitizer {
 	s := &Sanitizer{
-		excludeFields: helpers.SliceToLookup(opts.ExcludeFields),
+		excludeFields:         helpers.SliceToLookup(opts.ExcludeFields),
+		redactSensitiveValues: opts.RedactSensitiveValues,
 	}
 
 	builtInExcludeFields := opts.ExcludeFields
@@ -205,6 +212,16 @@ func (s *Sanitizer) SanitizeString(v string) string {
 		}
 	}
 
+	// and the registered sensitive values
+	if s.redactSensitiveValues {
+		for _, m := range sensitiveValueMatches(v) {
+			replacements = append(replacements, &replacement{
+				start: m[0],
+				end:   m[1],
+			})
+		}
+	}
+
 	// now order replacements and remove overlaps
 	sort.Slice(replacements, func(i, j int) bool {
 		return replacements[i].start < replacements[j].start
@@ -215,7 +232,9 @@ func (s *Sanitizer) SanitizeString(v string) string {
 		if lastReplacement != nil && r.start < lastReplacement.end {
 			slog.Debug("Overlapping replacements", "r1_start", r.start, "r1_end", r.end, "r2_start", lastReplacement.start, "r2_end", lastReplacement.end)
 			// expand previous replacement
-			lastReplacement.end = r.end
+			if r.end > lastReplacement.end {
+				lastReplacement.end = r.end
+			}
 			continue
 		}
 		newReplacements = append(newReplacements, r)
@@ -0,0 +1,93 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// values shorter than this are not registered, redacting them would mangle unrelated output
+const minSensitiveValueLength = 4
+
+// sensitiveValues holds the values registered as sensitive, e.g. by the secret functions, which are redacted wherever
+// they appear by the sanitizers with RedactSensitiveValues set. Each value is mapped to its JSON escaped form, which is
+// empty if it is the same as the value. Values stay registered until they are unregistered or cleared.
+var sensitiveValues = struct {
+	sync.RWMutex
+	values map[string]string
+}{values: make(map[string]string)}
+
+// RegisterSensitiveValue registers a value to be redacted. The value is redacted as is and in its JSON escaped form.
+func RegisterSensitiveValue(v string) {
+	v = strings.TrimSpace(v)
+	if len(v) < minSensitiveValueLength {
+		return
+	}
+
+	sensitiveValues.Lock()
+	defer sensitiveValues.Unlock()
+
+	escapedValue := ""
+	if escaped, err := json.Marshal(v); err == nil {
+		// strip the quotes of the JSON string
+		if e := string(escaped[1 : len(escaped)-1]); e != v {
+			escapedValue = e
+		}
+	}
+	sensitiveValues.values[v] = escapedValue
+}
+
+// UnregisterSensitiveValue removes a value from the registry, e.g. once a secret has been rotated
+func UnregisterSensitiveValue(v string) {
+	sensitiveValues.Lock()
+	defer sensitiveValues.Unlock()
+
+	delete(sensitiveValues.values, strings.TrimSpace(v))
+}
+
+// ClearSensitiveValues removes all the registered values
+func ClearSensitiveValues() {
+	sensitiveValues.Lock()
+	defer sensitiveValues.Unlock()
+
+	sensitiveValues.values = make(map[string]string)
+}
+
+// IsSensitiveValue returns whether the value has been registered as sensitive
+func IsSensitiveValue(v string) bool {
+	sensitiveValues.RLock()
+	defer sensitiveValues.RUnlock()
+
+	_, ok := sensitiveValues.values[strings.TrimSpace(v)]
+	return ok
+}
+
+// sensitiveValueMatches returns the start and end offsets of the registered values found in the string, as is or in
+// their JSON escaped form
+func sensitiveValueMatches(v string) [][2]int {
+	sensitiveValues.RLock()
+	defer sensitiveValues.RUnlock()
+
+	var res [][2]int
+	for value, escaped := range sensitiveValues.values {
+		res = append(res, valueMatches(v, value)...)
+		if escaped != "" {
+			res = append(res, valueMatches(v, escaped)...)
+		}
+	}
+	return res
+}
+
+func valueMatches(v, value string) [][2]int {
+	var res [][2]int
+	for offset := 0; offset < len(v); {
+		i := strings.Index(v[offset:], value)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		res = append(res, [2]int{start, start + len(value)})
+		offset = start + len(value)
+	}
+	return res
+}
@@ -0,0 +1,41 @@
+package sanitize
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveValuesRegistry(t *testing.T) {
+	assert := assert.New(t)
+
+	defer ClearSensitiveValues()
+	ClearSensitiveValues()
+
+	// Case 1: values stay registered however many others are registered
+	RegisterSensitiveValue("secret-1")
+	for i := 0; i < 2000; i++ {
+		RegisterSensitiveValue(fmt.Sprintf("other-secret-%d", i))
+	}
+	RegisterSensitiveValue("secret-4")
+	assert.True(IsSensitiveValue("secret-1"))
+	assert.True(IsSensitiveValue("secret-4"))
+	RegisterSensitiveValue("secret-3")
+	s := NewSanitizer(SanitizerOptions{RedactSensitiveValues: true})
+	assert.Equal(RedactedStr+" "+RedactedStr, s.SanitizeString("secret-1 secret-4"))
+
+	// Case 2: short values are not registered
+	RegisterSensitiveValue("abc")
+	assert.False(IsSensitiveValue("abc"))
+
+	// Case 3: unregister
+	UnregisterSensitiveValue("secret-3")
+	assert.False(IsSensitiveValue("secret-3"))
+	assert.Equal("secret-3 "+RedactedStr, s.SanitizeString("secret-3 secret-4"))
+
+	// Case 4: clear
+	ClearSensitiveValues()
+	assert.False(IsSensitiveValue("secret-1"))
+	assert.Equal("secret-1 secret-4", s.SanitizeString("secret-1 secret-4"))
+}
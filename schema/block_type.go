@@ -44,18 +44,28 @@ const (
 	BlockTypePipelineOutput    = "output"
 	BlockTypeTrigger           = "trigger"
 	BlockTypePipelineBasicAuth = "basic_auth"
+	BlockTypePipelineAuth      = "auth"
+	BlockTypePagination        = "pagination"
+	BlockTypeMount             = "mount"
+	BlockTypeWasm              = "wasm"
+	BlockTypeAttachment        = "attachment"
 	BlockTypeIntegration       = "integration"
 	BlockTypeLoop              = "loop"
 	BlockTypeCredential        = "credential"
 	BlockTypeCredentialImport  = "credential_import" //nolint:gosec // this is not a password
 	BlockTypeNotify            = "notify"
+	BlockTypeMatch             = "match"
+	BlockTypeBusinessHours     = "business_hours"
+	BlockTypeRateLimit         = "rate_limit"
 	BlockTypeNotifier          = "notifier"
 	BlockTypePartition         = "partition"
 	BlockTypeRetry             = "retry"
 	BlockTypeThrow             = "throw"
+	BlockTypeCompensate        = "compensate"
 	BlockTypeOption            = "option"
 	BlockTypeCapture           = "capture"
 	BlockTypeMethod            = "method"
+	BlockTypeVerify            = "verify"
 
 	AttributeTypeValue   = "value"
 	AttributeTypeType    = "type"
@@ -109,11 +119,15 @@ const (
 	AttributeTypeFinishedAt = "finished_at"
 
 	// Used by query step
-	AttributeTypeSql      = "sql"
-	AttributeTypeArgs     = "args"
-	AttributeTypeQuery    = "query"
-	AttributeTypeRows     = "rows"
-	AttributeTypeDatabase = "database"
+	AttributeTypeSql            = "sql"
+	AttributeTypeArgs           = "args"
+	AttributeTypeQuery          = "query"
+	AttributeTypeRows           = "rows"
+	AttributeTypeDatabase       = "database"
+	AttributeTypeStatements     = "statements"
+	AttributeTypeTransaction    = "transaction"
+	AttributeTypeIsolationLevel = "isolation_level"
+	AttributeTypeRowsAffected   = "rows_affected"
 
 	// Used by email step
 	AttributeTypeBcc         = "bcc"
@@ -126,6 +140,13 @@ const (
 	AttributeTypeSenderName  = "sender_name"
 	AttributeTypeSubject     = "subject"
 	AttributeTypeTo          = "to"
+	AttributeTypeHtmlBody    = "html_body"
+	AttributeTypeReplyTo     = "reply_to"
+	AttributeTypeHeaders     = "headers"
+	AttributeTypeAttachments = "attachments"
+	AttributeTypeFilename    = "filename"
+	AttributeTypeContent     = "content"
+	AttributeTypeContentId   = "content_id"
 
 	AttributeTypeToken         = "token"
 	AttributeTypeSigningSecret = "signing_secret"
@@ -151,6 +172,39 @@ const (
 	AttributeTypeStatusCode      = "status_code"
 	AttributeTypeStatus          = "status"
 
+	// Used by the http step and the http based integrations
+	AttributeTypeProxyUrl       = "proxy_url"
+	AttributeTypeNoProxy        = "no_proxy"
+	AttributeTypeConnectTimeout = "connect_timeout"
+	AttributeTypeMaxRedirects   = "max_redirects"
+	AttributeTypeHttp2          = "http2"
+
+	// Used by the webhook integration
+	AttributeTypeAuthConnection = "auth_connection"
+	AttributeTypeBodyTemplate   = "body_template"
+	AttributeTypeTemplateFormat = "template_format"
+	AttributeTypeSuccessCodes   = "success_codes"
+
+	// Used by the auth block of the http step
+	AttributeTypeTokenUrl      = "token_url"
+	AttributeTypeClientId      = "client_id"
+	AttributeTypeClientSecret  = "client_secret" //nolint:gosec // this is not a password
+	AttributeTypeScopes        = "scopes"
+	AttributeTypeAudience      = "audience"
+	AttributeTypeConnection    = "connection"
+	AttributeTypeRegion        = "region"
+	AttributeTypeService       = "service"
+	AttributeTypeClientCertPem = "client_cert_pem"
+	AttributeTypeClientKeyPem  = "client_key_pem"
+
+	// Used by the pagination block of the http step
+	AttributeTypeCursorPath = "cursor_path"
+	AttributeTypeQueryParam = "query_param"
+	AttributeTypeStart      = "start"
+	AttributeTypeIncrement  = "increment"
+	AttributeTypeItemsPath  = "items_path"
+	AttributeTypeMaxPages   = "max_pages"
+
 	// Used byy Pipeline step
 	AttributeTypePipeline = "pipeline"
 
@@ -180,6 +234,10 @@ const (
 	AttributeTypeFunction = "function"
 	AttributeTypeEvent    = "event"
 
+	AttributeTypeMemoryLimit = "memory_limit"
+	AttributeTypeFuelLimit   = "fuel_limit"
+	AttributeTypeAllowedEnv  = "allowed_env"
+
 	AttributeTypeImage             = "image"
 	AttributeTypeSource            = "source"
 	AttributeTypeUser              = "user"
@@ -194,6 +252,13 @@ const (
 	AttributeTypeMemorySwap        = "memory_swap"
 	AttributeTypeMemorySwappiness  = "memory_swappiness"
 	AttributeTypeReadOnly          = "read_only"
+	AttributeTypeTarget            = "target"
+	AttributeTypeMounts            = "mounts"
+	AttributeTypeNetworkMode       = "network_mode"
+	AttributeTypeStdin             = "stdin"
+	AttributeTypePidsLimit         = "pids_limit"
+	AttributeTypeCapDrop           = "cap_drop"
+	AttributeTypeOutputFormat      = "output_format"
 	AttributeTypeExitCode          = "exit_code"
 	AttributeTypeContainerId       = "container_id"
 	AttributeTypeStdout            = "stdout"
@@ -207,24 +272,71 @@ const (
 	AttributeTypePrimaryKey = "primary_key"
 	AttributeTypeEnabled    = "enabled"
 
+	// Schedule and Query Trigger attributes
+	AttributeTypeTimezone     = "timezone"
+	AttributeTypeStartTime    = "start_time"
+	AttributeTypeEndTime      = "end_time"
+	AttributeTypeJitter       = "jitter"
+	AttributeTypeExcludeDates = "exclude_dates"
+	AttributeTypeCalendar     = "calendar"
+
+	// File Trigger attributes
+	AttributeTypePath     = "path"
+	AttributeTypeEvents   = "events"
+	AttributeTypeDebounce = "debounce"
+
 	// HTTP Trigger attributes
 	AttributeTypeExecutionMode = "execution_mode"
+	AttributeTypeSecret        = "secret"
+	AttributeTypeHeader        = "header"
+	AttributeTypePrefix        = "prefix"
+	AttributeTypeTolerance     = "tolerance"
 
 	// Input step attributes
-	AttributeTypePrompt    = "prompt"
-	AttributeTypeSlackType = "slack_type"
-	AttributeTypeText      = "text"
+	AttributeTypePrompt           = "prompt"
+	AttributeTypeSlackType        = "slack_type"
+	AttributeTypeText             = "text"
+	AttributeTypeMin              = "min"
+	AttributeTypeMax              = "max"
+	AttributeTypePattern          = "pattern"
+	AttributeTypePlaceholder      = "placeholder"
+	AttributeTypeValidation       = "validation"
+	AttributeTypeConfirmPhrase    = "confirm_phrase"
+	AttributeTypeDefaultValue     = "default_value"
+	AttributeTypeOnTimeout        = "on_timeout"
+	AttributeTypeReminder         = "reminder"
+	AttributeTypeEscalation       = "escalation"
+	AttributeTypeEscalationWindow = "escalation_window"
+
+	// Used by the message step and the match block of notify
+	AttributeTypeSeverity = "severity"
+	AttributeTypeDays     = "days"
+	AttributeTypeEnd      = "end"
+	AttributeTypeOutside  = "outside"
+
+	// Used by the rich message of the message and input steps
+	AttributeTypeColor    = "color"
+	AttributeTypeSections = "sections"
+
+	// Used by the notifier dedupe and rate limit
+	AttributeTypeDedupeWindow = "dedupe_window"
+	AttributeTypeDedupeKey    = "dedupe_key"
+	AttributeTypeMessages     = "messages"
+	AttributeTypeInterval     = "interval"
+	AttributeTypeDigest       = "digest"
 
 	// All Possible Trigger Types
 	TriggerTypeSchedule = "schedule"
 	TriggerTypeQuery    = "query"
 	TriggerTypeHttp     = "http"
+	TriggerTypeFile     = "file"
 
 	// Integration Types
 	IntegrationTypeSlack   = "slack"
 	IntegrationTypeEmail   = "email"
 	IntegrationTypeMsTeams = "msteams"
 	IntegrationTypeHttp    = "http"
+	IntegrationTypeWebhook = "webhook"
 
 	LabelName = "name"
 	LabelType = "type"
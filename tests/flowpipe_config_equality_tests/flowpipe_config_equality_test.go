@@ -2,11 +2,13 @@ package pipeline_test
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path"
 	"testing"
 
 	"github.com/turbot/pipe-fittings/flowpipeconfig"
+	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/tests/test_init"
 	"github.com/turbot/pipe-fittings/utils"
 
@@ -128,6 +130,60 @@ var flowpipeConfigEqualityTestCases = []flowpipeConfigEqualityTestCase{
 		compare: "./config_notifier_base_f",
 		equal:   false,
 	},
+	{
+		title:   "test: integration_transport == integration_transport",
+		base:    "./config_integration_transport",
+		compare: "./config_integration_transport",
+		equal:   true,
+	},
+	{
+		title:   "test: integration_transport != integration_transport_b",
+		base:    "./config_integration_transport",
+		compare: "./config_integration_transport_b",
+		equal:   false,
+	},
+	{
+		title:   "test: integration_webhook == integration_webhook",
+		base:    "./config_integration_webhook",
+		compare: "./config_integration_webhook",
+		equal:   true,
+	},
+	{
+		title:   "test: integration_webhook != integration_webhook_b",
+		base:    "./config_integration_webhook",
+		compare: "./config_integration_webhook_b",
+		equal:   false,
+	},
+	{
+		title:   "test: notifier_match == notifier_match",
+		base:    "./config_notifier_match",
+		compare: "./config_notifier_match",
+		equal:   true,
+	},
+	{
+		title:   "test: notifier_match != notifier_match_b",
+		base:    "./config_notifier_match",
+		compare: "./config_notifier_match_b",
+		equal:   false,
+	},
+	{
+		title:   "test: notifier_base_b != notifier_match",
+		base:    "./config_notifier_base_b",
+		compare: "./config_notifier_match",
+		equal:   false,
+	},
+	{
+		title:   "test: notifier_dedupe == notifier_dedupe",
+		base:    "./config_notifier_dedupe",
+		compare: "./config_notifier_dedupe",
+		equal:   true,
+	},
+	{
+		title:   "test: notifier_dedupe != notifier_dedupe_b",
+		base:    "./config_notifier_dedupe",
+		compare: "./config_notifier_dedupe_b",
+		equal:   false,
+	},
 }
 
 const (
@@ -156,6 +212,72 @@ func (suite *FlowpipeConfigEqualityTestSuite) TestFlowpipeConfigEquality() {
 	}
 }
 
+func (suite *FlowpipeConfigEqualityTestSuite) TestWebhookIntegration() {
+	assert := assert.New(suite.T())
+
+	flowpipeConfig, err := flowpipeconfig.LoadFlowpipeConfig([]string{"./config_integration_webhook"})
+	if !assert.Nil(err.Error) {
+		return
+	}
+
+	discord, ok := flowpipeConfig.Integrations["webhook.discord"].(*modconfig.WebhookIntegration)
+	if !assert.True(ok, "webhook.discord integration not found") {
+		return
+	}
+	assert.Equal("https://discord.com/api/webhooks/123/abc", *discord.Url)
+	assert.Equal("POST", discord.HttpMethod())
+	assert.Equal([]int{200, 204}, discord.SuccessCodes)
+	assert.True(discord.IsSuccessStatusCode(204))
+	assert.False(discord.IsSuccessStatusCode(201))
+
+	pagerduty, ok := flowpipeConfig.Integrations["webhook.pagerduty"].(*modconfig.WebhookIntegration)
+	if !assert.True(ok, "webhook.pagerduty integration not found") {
+		return
+	}
+	assert.Equal(map[string]string{"Content-Type": "application/json"}, pagerduty.Headers)
+	connectionType, connectionName, connErr := pagerduty.AuthConnectionName()
+	assert.Nil(connErr)
+	assert.Equal("pagerduty", connectionType)
+	assert.Equal("default", connectionName)
+	assert.True(pagerduty.IsSuccessStatusCode(202))
+
+	body, renderErr := pagerduty.RenderBody(&modconfig.RichMessage{Title: "Disk full", Severity: modconfig.RichMessageSeverityError})
+	assert.Nil(renderErr)
+	assert.JSONEq(`{"event_action": "trigger", "payload": {"summary": "Disk full", "severity": "error"}}`, string(body))
+
+	// the integration of a notify survives the JSON round trip
+	notifier := flowpipeConfig.Notifiers["incidents"]
+	if !assert.NotNil(notifier) {
+		return
+	}
+	notifyJson, jsonErr := json.Marshal(notifier.GetNotifies()[0])
+	if !assert.Nil(jsonErr) {
+		return
+	}
+	var notify modconfig.Notify
+	if !assert.Nil(json.Unmarshal(notifyJson, &notify)) {
+		return
+	}
+	fromJson, ok := notify.Integration.(*modconfig.WebhookIntegration)
+	if !assert.True(ok, "notify integration is not a webhook integration") {
+		return
+	}
+	assert.Equal(*pagerduty.Url, *fromJson.Url)
+	assert.Equal(*pagerduty.TemplateFormat, *fromJson.TemplateFormat)
+	assert.Equal(*pagerduty.Timeout, *fromJson.Timeout)
+
+	// and the cty round trip
+	ctyVal, ctyErr := pagerduty.CtyValue()
+	if !assert.Nil(ctyErr) {
+		return
+	}
+	fromCty, ctyErr := modconfig.WebhookIntegrationFromCtyValue(ctyVal)
+	assert.Nil(ctyErr)
+	assert.Equal(pagerduty.Headers, fromCty.Headers)
+	assert.Equal(*pagerduty.BodyTemplate, *fromCty.BodyTemplate)
+	assert.Equal(*pagerduty.AuthConnection, *fromCty.AuthConnection)
+}
+
 // The TearDownSuite method will be run by testify once, at the very
 // end of the testing suite, after all tests have been run.
 func (suite *FlowpipeConfigEqualityTestSuite) TearDownSuite() {
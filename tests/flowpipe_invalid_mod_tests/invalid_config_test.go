@@ -182,6 +182,106 @@ var invalidConfigTests = []invalidConfigTestSetup{
 		ignoreConfigParse: true,
 		containsError:     "Bad Request: notifier value must be a reference to a notifier resource",
 	},
+	{
+		title:             "Input step number min greater than max",
+		modDir:            "./mods/input_step_number_min_max",
+		configDirs:        []string{"./mods/input_step_number_min_max"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid number input: min 10 must not be greater than max 1",
+	},
+	{
+		title:             "Input step confirm without confirm phrase",
+		modDir:            "./mods/input_step_confirm_missing_phrase",
+		configDirs:        []string{"./mods/input_step_confirm_missing_phrase"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid confirm input: confirm_phrase is required for confirm inputs",
+	},
+	{
+		title:             "Input step text with invalid pattern",
+		modDir:            "./mods/input_step_invalid_pattern",
+		configDirs:        []string{"./mods/input_step_invalid_pattern"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid text input: invalid pattern: error parsing regexp",
+	},
+	{
+		title:             "Input step attribute set for another input type",
+		modDir:            "./mods/input_step_attribute_for_type",
+		configDirs:        []string{"./mods/input_step_attribute_for_type"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid number input: pattern can only be set for text inputs",
+	},
+	{
+		title:             "Input step options set for an input type without options",
+		modDir:            "./mods/input_step_options_for_type",
+		configDirs:        []string{"./mods/input_step_options_for_type"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid date input: options can only be set for button, select and multiselect inputs",
+	},
+	{
+		title:             "Input step timeout with a default value and on_timeout fail",
+		modDir:            "./mods/input_step_timeout_fail_default",
+		configDirs:        []string{"./mods/input_step_timeout_fail_default"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid input timeout: default_value can not be set when on_timeout is fail",
+	},
+	{
+		title:             "Input step default value without timeout",
+		modDir:            "./mods/input_step_timeout_missing_timeout",
+		configDirs:        []string{"./mods/input_step_timeout_missing_timeout"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid input timeout: on_timeout and default_value require the timeout attribute",
+	},
+	{
+		title:             "Input step escalation window longer than the timeout",
+		modDir:            "./mods/input_step_timeout_escalation_window",
+		configDirs:        []string{"./mods/input_step_timeout_escalation_window"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid input timeout: escalation_window must be less than the timeout",
+	},
+	{
+		title:             "Input step invalid default value",
+		modDir:            "./mods/input_step_timeout_default_value",
+		configDirs:        []string{"./mods/input_step_timeout_default_value"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid input timeout: default_value: Bad Request: the response 10 must be at most 5",
+	},
+	{
+		title:         "Invalid notify block - invalid match severity",
+		modDir:        "",
+		configDirs:    []string{"./mods/bad_notify_match_severity"},
+		containsError: "Invalid match: invalid severity critical, must be one of info, success, warning, error",
+	},
+	{
+		title:         "Invalid notify block - invalid business hours",
+		modDir:        "",
+		configDirs:    []string{"./mods/bad_notify_match_business_hours"},
+		containsError: "Invalid business_hours: invalid start 9am, must be in the HH:MM format",
+	},
+	{
+		title:             "Message step invalid severity",
+		modDir:            "./mods/message_step_invalid_severity",
+		configDirs:        []string{"./mods/message_step_invalid_severity"},
+		ignoreConfigParse: true,
+		containsError:     "Invalid severity critical: must be one of info, success, warning, error",
+	},
+	{
+		title:         "Invalid notifier - invalid dedupe window",
+		modDir:        "",
+		configDirs:    []string{"./mods/bad_notifier_dedupe_window"},
+		containsError: "Invalid dedupe_window: ten minutes must be a positive duration, e.g. 10m",
+	},
+	{
+		title:         "Invalid notifier - dedupe key referencing a step",
+		modDir:        "",
+		configDirs:    []string{"./mods/bad_notifier_dedupe_key"},
+		containsError: "Invalid dedupe_key: the expression can only reference the message variable, found step.message.alert.text",
+	},
+	{
+		title:         "Invalid notifier - rate limit without messages",
+		modDir:        "",
+		configDirs:    []string{"./mods/bad_notifier_rate_limit"},
+		containsError: "Invalid rate_limit: messages must be at least 1, got 0",
+	},
 }
 
 func (suite *FlowpipeSimpleInvalidConfigTestSuite) TestSimpleInvalidMods() {
@@ -0,0 +1,3 @@
+mod "input_step_attribute_for_type" {
+
+}
@@ -0,0 +1,9 @@
+pipeline "number_input" {
+  step "input" "replicas" {
+    notifier = notifier.default
+
+    type    = "number"
+    prompt  = "How many replicas?"
+    pattern = "[0-9]+"
+  }
+}
@@ -0,0 +1,3 @@
+mod "input_step_confirm_missing_phrase" {
+
+}
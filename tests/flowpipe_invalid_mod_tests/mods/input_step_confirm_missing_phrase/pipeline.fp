@@ -0,0 +1,8 @@
+pipeline "confirm_input" {
+  step "input" "confirm" {
+    notifier = notifier.default
+
+    type   = "confirm"
+    prompt = "Type the name of the environment to delete it"
+  }
+}
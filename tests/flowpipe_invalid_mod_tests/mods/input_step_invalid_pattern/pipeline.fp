@@ -0,0 +1,9 @@
+pipeline "text_input" {
+  step "input" "ticket" {
+    notifier = notifier.default
+
+    type    = "text"
+    prompt  = "Change ticket"
+    pattern = "CHG[0-9"
+  }
+}
@@ -0,0 +1,3 @@
+mod "input_step_number_min_max" {
+
+}
@@ -0,0 +1,10 @@
+pipeline "number_input" {
+  step "input" "replicas" {
+    notifier = notifier.default
+
+    type   = "number"
+    prompt = "How many replicas?"
+    min    = 10
+    max    = 1
+  }
+}
@@ -0,0 +1,3 @@
+mod "input_step_options_for_type" {
+
+}
@@ -0,0 +1,10 @@
+pipeline "date_input" {
+  step "input" "start_date" {
+    notifier = notifier.default
+
+    type   = "date"
+    prompt = "When should the change start?"
+
+    option "today" {}
+  }
+}
@@ -0,0 +1,12 @@
+pipeline "input_timeout" {
+  step "input" "replicas" {
+    notifier = notifier.default
+
+    type   = "number"
+    prompt = "How many replicas?"
+    max    = 5
+
+    timeout       = "1h"
+    default_value = "10"
+  }
+}
@@ -0,0 +1,12 @@
+pipeline "input_timeout" {
+  step "input" "approve" {
+    notifier = notifier.default
+
+    type   = "text"
+    prompt = "Change ticket"
+
+    timeout           = "1h"
+    escalation        = [notifier.default]
+    escalation_window = "2h"
+  }
+}
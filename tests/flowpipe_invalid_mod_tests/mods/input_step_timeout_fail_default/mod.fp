@@ -0,0 +1,3 @@
+mod "input_step_timeout_fail_default" {
+
+}
@@ -0,0 +1,10 @@
+pipeline "input_timeout" {
+  step "input" "approve" {
+    notifier = notifier.default
+
+    type   = "text"
+    prompt = "Change ticket"
+
+    default_value = "CHG000000"
+  }
+}
@@ -0,0 +1,10 @@
+mod "message_step_invalid_severity" {
+}
+
+pipeline "message_step_invalid_severity" {
+  step "message" "alert" {
+    notifier = notifier.default
+    text     = "Disk full"
+    severity = "critical"
+  }
+}
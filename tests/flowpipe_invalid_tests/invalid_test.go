@@ -142,6 +142,26 @@ var tests = []testSetup{
 		file:          "./pipelines/throw_missing_if.fp",
 		containsError: "The argument 'if' is required",
 	},
+	{
+		title:         "compensate - missing pipeline",
+		file:          "./pipelines/compensate_missing_pipeline.fp",
+		containsError: "The argument 'pipeline' is required, but no definition was found.",
+	},
+	{
+		title:         "compensate - invalid attribute",
+		file:          "./pipelines/compensate_invalid_attribute.fp",
+		containsError: "Unsupported argument 'foo' in compensate block",
+	},
+	{
+		title:         "compensate - references a step that runs after",
+		file:          "./pipelines/compensate_invalid_order.fp",
+		containsError: "The compensate block of step transform.one references step transform.two which does not run before it",
+	},
+	{
+		title:         "compensate - multiple compensate blocks",
+		file:          "./pipelines/compensate_multiple_blocks.fp",
+		containsError: "Only one compensate block is allowed per step",
+	},
 	{
 		title:         "invalid pipeline output attribute - sensitive",
 		file:          "./pipelines/output_invalid_attribute.fp",
@@ -184,6 +204,216 @@ var tests = []testSetup{
 		file:          "./pipelines/invalid_http_trigger_method.fp",
 		containsError: "Method block type must be one of: post,get",
 	},
+	{
+		title:         "invalid verify type in http trigger",
+		file:          "./pipelines/invalid_http_trigger_verify_type.fp",
+		containsError: "Invalid verify type: gitlab",
+	},
+	{
+		title:         "literal secret in http trigger verify block",
+		file:          "./pipelines/invalid_http_trigger_verify_secret.fp",
+		containsError: "The secret attribute of the verify block must reference a variable or a connection",
+	},
+	{
+		title:         "header in github http trigger verify block",
+		file:          "./pipelines/invalid_http_trigger_verify_header.fp",
+		containsError: "The header and prefix attributes are only supported by the hmac_sha256 verify type",
+	},
+	{
+		title:         "invalid auth type in http step",
+		file:          "./pipelines/invalid_http_step_auth_type.fp",
+		containsError: "Invalid auth type: digest",
+	},
+	{
+		title:         "missing token_url in http step oauth2 auth",
+		file:          "./pipelines/http_step_auth_missing_token_url.fp",
+		containsError: "The argument 'token_url' is required for auth type oauth2",
+	},
+	{
+		title:         "unsupported attribute in http step bearer auth",
+		file:          "./pipelines/invalid_http_step_auth_attribute.fp",
+		containsError: "Unsupported attribute region for auth type bearer",
+	},
+	{
+		title:         "auth and basic_auth in http step",
+		file:          "./pipelines/invalid_http_step_auth_and_basic_auth.fp",
+		containsError: "The auth and basic_auth blocks can not both be set for step http",
+	},
+	{
+		title:         "invalid pagination type in http step",
+		file:          "./pipelines/invalid_http_step_pagination_type.fp",
+		containsError: "Invalid pagination type: token",
+	},
+	{
+		title:         "missing cursor_path in http step cursor pagination",
+		file:          "./pipelines/http_step_pagination_missing_cursor_path.fp",
+		containsError: "The argument 'cursor_path' is required for pagination type cursor",
+	},
+	{
+		title:         "invalid max_pages in http step pagination",
+		file:          "./pipelines/invalid_http_step_pagination_max_pages.fp",
+		containsError: "The max_pages attribute of the pagination block must be greater than 0",
+	},
+	{
+		title:         "invalid items_path in http step pagination",
+		file:          "./pipelines/invalid_http_step_pagination_items_path.fp",
+		containsError: "Invalid items_path: data.items",
+	},
+	{
+		title:         "loop and pagination in http step",
+		file:          "./pipelines/invalid_http_step_pagination_and_loop.fp",
+		containsError: "The loop and pagination blocks can not both be set for step http",
+	},
+	{
+		title:         "invalid proxy_url in http step",
+		file:          "./pipelines/invalid_http_step_proxy_url.fp",
+		containsError: "Invalid proxy_url: proxy.example.com:3128",
+	},
+	{
+		title:         "invalid connect_timeout in http step",
+		file:          "./pipelines/invalid_http_step_connect_timeout.fp",
+		containsError: "Invalid connect_timeout: 5 seconds. Specify a duration such as 5s",
+	},
+	{
+		title:         "negative max_redirects in http step",
+		file:          "./pipelines/invalid_http_step_max_redirects.fp",
+		containsError: "Invalid max_redirects: the value must not be negative",
+	},
+	{
+		title:         "sql and statements in query step",
+		file:          "./pipelines/invalid_query_step_sql_and_statements.fp",
+		containsError: "The sql and statements attributes are mutually exclusive for Query Step",
+	},
+	{
+		title:         "invalid isolation level in query step",
+		file:          "./pipelines/invalid_query_step_isolation_level.fp",
+		containsError: "Invalid isolation_level: snapshot",
+	},
+	{
+		title:         "isolation level without transaction in query step",
+		file:          "./pipelines/invalid_query_step_isolation_level_without_transaction.fp",
+		containsError: "The isolation_level attribute requires transaction to be set for Query Step",
+	},
+	{
+		title:         "named args not matching the sql in query step",
+		file:          "./pipelines/invalid_query_step_named_args.fp",
+		containsError: "Invalid args for Query Step: missing value for the named parameter :item",
+	},
+	{
+		title:         "invalid statement in query step",
+		file:          "./pipelines/invalid_query_step_statement.fp",
+		containsError: "unsupported attribute query in statement 1",
+	},
+	{
+		title:         "invalid network_mode in container step",
+		file:          "./pipelines/invalid_container_step_network_mode.fp",
+		containsError: "Invalid container settings: invalid network_mode no network",
+	},
+	{
+		title:         "invalid output_format in container step",
+		file:          "./pipelines/invalid_container_step_output_format.fp",
+		containsError: "Invalid container settings: invalid output_format yaml: must be one of text, json",
+	},
+	{
+		title:         "invalid pids_limit in container step",
+		file:          "./pipelines/invalid_container_step_pids_limit.fp",
+		containsError: "Invalid container settings: invalid pids_limit 0: must be greater than 0",
+	},
+	{
+		title:         "relative mount target in container step",
+		file:          "./pipelines/invalid_container_step_mount_target.fp",
+		containsError: "Invalid mount: the target of a mount must be an absolute path: cache",
+	},
+	{
+		title:         "duplicate mount target in container step",
+		file:          "./pipelines/invalid_container_step_duplicate_mount_target.fp",
+		containsError: "Duplicate mount target /cache",
+	},
+	{
+		title:         "mount without target in container step",
+		file:          "./pipelines/invalid_container_step_mount_missing_target.fp",
+		containsError: "Missing required argument",
+	},
+	{
+		title:         "wasm function source is not a wasm module",
+		file:          "./pipelines/invalid_function_step_wasm_source.fp",
+		containsError: "Invalid wasm function: the source of a wasm function must be the path to a .wasm module: ./my-function",
+	},
+	{
+		title:         "wasm block in function step with another runtime",
+		file:          "./pipelines/invalid_function_step_wasm_block_runtime.fp",
+		containsError: "The wasm block can only be set for a function step with runtime wasm",
+	},
+	{
+		title:         "invalid wasm memory limit in function step",
+		file:          "./pipelines/invalid_function_step_wasm_memory_limit.fp",
+		containsError: "Invalid wasm settings: invalid memory_limit 0: must be between 1 and 4096 MB",
+	},
+	{
+		title:         "invalid wasm allowed env in function step",
+		file:          "./pipelines/invalid_function_step_wasm_allowed_env.fp",
+		containsError: "Invalid wasm settings: invalid allowed_env AWS-PROFILE: must be an environment variable name",
+	},
+	{
+		title:         "email attachment with path and content",
+		file:          "./pipelines/invalid_email_step_attachment_path_and_content.fp",
+		containsError: "Invalid attachment: only one of path or content can be set",
+	},
+	{
+		title:         "email attachment with content and no filename",
+		file:          "./pipelines/invalid_email_step_attachment_missing_filename.fp",
+		containsError: "Invalid attachment: filename is required with content",
+	},
+	{
+		title:         "email inline image without html body",
+		file:          "./pipelines/invalid_email_step_inline_without_html.fp",
+		containsError: "Invalid attachment: an attachment with a content_id is an inline image, which requires an html body",
+	},
+	{
+		title:         "email step reserved header",
+		file:          "./pipelines/invalid_email_step_reserved_header.fp",
+		containsError: "Invalid headers: the header Bcc is set by the email step and can not be overridden",
+	},
+	{
+		title:         "invalid event in file trigger",
+		file:          "./pipelines/invalid_file_trigger_event.fp",
+		containsError: "Invalid event: rename. Valid events are: create, modify, delete",
+	},
+	{
+		title:         "invalid debounce in file trigger",
+		file:          "./pipelines/invalid_file_trigger_debounce.fp",
+		containsError: "Invalid debounce interval: soon",
+	},
+	{
+		title:         "missing path in file trigger",
+		file:          "./pipelines/file_trigger_missing_path.fp",
+		containsError: "Missing required argument: The argument \"path\" is required",
+	},
+	{
+		title:         "invalid timezone in schedule trigger",
+		file:          "./pipelines/invalid_schedule_trigger_timezone.fp",
+		containsError: "Invalid timezone: Mars/Olympus",
+	},
+	{
+		title:         "invalid start time in schedule trigger",
+		file:          "./pipelines/invalid_schedule_trigger_start_time.fp",
+		containsError: "Invalid start_time: 9am. Specify a time of day in the format HH:MM",
+	},
+	{
+		title:         "same start and end time in schedule trigger",
+		file:          "./pipelines/invalid_schedule_trigger_same_window.fp",
+		containsError: "Invalid end_time: 9:00. The end time can not be the same as the start time",
+	},
+	{
+		title:         "invalid exclude date in schedule trigger",
+		file:          "./pipelines/invalid_schedule_trigger_exclude_dates.fp",
+		containsError: "Invalid date in exclude_dates: 25/12/2024. Specify dates in the format YYYY-MM-DD",
+	},
+	{
+		title:         "missing calendar file in schedule trigger",
+		file:          "./pipelines/invalid_schedule_trigger_calendar.fp",
+		containsError: "Unable to read calendar file: ./does_not_exist.ics",
+	},
 	{
 		title:         "duplicate method blocks in http trigger",
 		file:          "./pipelines/invalid_http_trigger_duplicate_method.fp",
@@ -0,0 +1,17 @@
+pipeline "undo" {
+    step "transform" "one" {
+        value = "foo"
+    }
+}
+
+pipeline "compensate_invalid_attribute" {
+
+    step "transform" "one" {
+        value = "foo"
+
+        compensate {
+            pipeline = pipeline.undo
+            foo      = "bar"
+        }
+    }
+}
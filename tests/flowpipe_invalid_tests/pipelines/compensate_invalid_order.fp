@@ -0,0 +1,28 @@
+pipeline "undo" {
+    param "value" {
+        type = string
+    }
+
+    step "transform" "one" {
+        value = param.value
+    }
+}
+
+pipeline "compensate_invalid_order" {
+
+    step "transform" "one" {
+        value = "foo"
+
+        compensate {
+            pipeline = pipeline.undo
+            args = {
+                value = step.transform.two.value
+            }
+        }
+    }
+
+    step "transform" "two" {
+        value = "bar"
+        depends_on = [step.transform.one]
+    }
+}
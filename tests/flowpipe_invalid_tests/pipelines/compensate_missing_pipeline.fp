@@ -0,0 +1,12 @@
+pipeline "compensate_missing_pipeline" {
+
+    step "transform" "one" {
+        value = "foo"
+
+        compensate {
+            args = {
+                foo = "bar"
+            }
+        }
+    }
+}
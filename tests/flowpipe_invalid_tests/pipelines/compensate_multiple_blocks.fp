@@ -0,0 +1,20 @@
+pipeline "undo" {
+    step "transform" "one" {
+        value = "foo"
+    }
+}
+
+pipeline "compensate_multiple_blocks" {
+
+    step "transform" "one" {
+        value = "foo"
+
+        compensate {
+            pipeline = pipeline.undo
+        }
+
+        compensate {
+            pipeline = pipeline.undo
+        }
+    }
+}
@@ -0,0 +1,9 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "file" "missing_path" {
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,11 @@
+pipeline "http_step_auth_missing_token_url" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    auth {
+      type          = "oauth2"
+      client_id     = "my-client"
+      client_secret = "secret"
+    }
+  }
+}
@@ -0,0 +1,10 @@
+pipeline "http_step_pagination_missing_cursor_path" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    pagination {
+      type        = "cursor"
+      query_param = "cursor"
+    }
+  }
+}
@@ -0,0 +1,15 @@
+pipeline "invalid_container_step_duplicate_mount_target" {
+  step "container" "my_step" {
+    image = "test/image"
+
+    mount {
+      source = "/tmp/cache"
+      target = "/cache"
+    }
+
+    mount {
+      source = "/tmp/other_cache"
+      target = "/cache"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+pipeline "invalid_container_step_mount_missing_target" {
+  step "container" "my_step" {
+    image = "test/image"
+
+    mount {
+      source = "/tmp/cache"
+    }
+  }
+}
@@ -0,0 +1,10 @@
+pipeline "invalid_container_step_mount_target" {
+  step "container" "my_step" {
+    image = "test/image"
+
+    mount {
+      source = "/tmp/cache"
+      target = "cache"
+    }
+  }
+}
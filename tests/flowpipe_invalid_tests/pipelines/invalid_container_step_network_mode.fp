@@ -0,0 +1,6 @@
+pipeline "invalid_container_step_network_mode" {
+  step "container" "my_step" {
+    image        = "test/image"
+    network_mode = "no network"
+  }
+}
@@ -0,0 +1,6 @@
+pipeline "invalid_container_step_output_format" {
+  step "container" "my_step" {
+    image         = "test/image"
+    output_format = "yaml"
+  }
+}
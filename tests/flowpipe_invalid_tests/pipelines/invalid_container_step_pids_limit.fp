@@ -0,0 +1,6 @@
+pipeline "invalid_container_step_pids_limit" {
+  step "container" "my_step" {
+    image      = "test/image"
+    pids_limit = 0
+  }
+}
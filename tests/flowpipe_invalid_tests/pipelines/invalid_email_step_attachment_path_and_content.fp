@@ -0,0 +1,16 @@
+pipeline "invalid_email_step_attachment_path_and_content" {
+  step "email" "my_step" {
+    smtp_username = "admiring.dijkstra@example.com"
+    smtp_password = "abcdefghijklmnop"
+    port          = 587
+    host          = "smtp.gmail.com"
+    from          = "sleepy.feynman@example.com"
+    to            = ["friendly.curie@example.com"]
+
+    attachment {
+      path     = "./report.csv"
+      content  = "id,amount"
+      filename = "report.csv"
+    }
+  }
+}
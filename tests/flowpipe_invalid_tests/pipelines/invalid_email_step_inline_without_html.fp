@@ -0,0 +1,16 @@
+pipeline "invalid_email_step_inline_without_html" {
+  step "email" "my_step" {
+    smtp_username = "admiring.dijkstra@example.com"
+    smtp_password = "abcdefghijklmnop"
+    port          = 587
+    host          = "smtp.gmail.com"
+    from          = "sleepy.feynman@example.com"
+    to            = ["friendly.curie@example.com"]
+    body = "See the logo"
+
+    attachment {
+      path       = "./logo.png"
+      content_id = "logo"
+    }
+  }
+}
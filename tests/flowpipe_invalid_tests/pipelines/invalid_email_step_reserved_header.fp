@@ -0,0 +1,14 @@
+pipeline "invalid_email_step_reserved_header" {
+  step "email" "my_step" {
+    smtp_username = "admiring.dijkstra@example.com"
+    smtp_password = "abcdefghijklmnop"
+    port          = 587
+    host          = "smtp.gmail.com"
+    from          = "sleepy.feynman@example.com"
+    to            = ["friendly.curie@example.com"]
+
+    headers = {
+      "Bcc" = "eve@example.com"
+    }
+  }
+}
@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "file" "bad_debounce" {
+    path     = "/data/inbox/*.csv"
+    debounce = "soon"
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "file" "bad_event" {
+    path     = "/data/inbox/*.csv"
+    events   = ["create", "rename"]
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,10 @@
+pipeline "invalid_function_step_wasm_allowed_env" {
+  step "function" "my_func" {
+    source  = "./function.wasm"
+    runtime = "wasm"
+
+    wasm {
+      allowed_env = ["HOME", "AWS-PROFILE"]
+    }
+  }
+}
@@ -0,0 +1,11 @@
+pipeline "invalid_function_step_wasm_block_runtime" {
+  step "function" "my_func" {
+    source  = "./my-function"
+    runtime = "nodejs"
+    handler = "my_file.my_handler"
+
+    wasm {
+      memory_limit = 64
+    }
+  }
+}
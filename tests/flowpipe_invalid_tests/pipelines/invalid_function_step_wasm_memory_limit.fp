@@ -0,0 +1,10 @@
+pipeline "invalid_function_step_wasm_memory_limit" {
+  step "function" "my_func" {
+    source  = "./function.wasm"
+    runtime = "wasm"
+
+    wasm {
+      memory_limit = 0
+    }
+  }
+}
@@ -0,0 +1,6 @@
+pipeline "invalid_function_step_wasm_source" {
+  step "function" "my_func" {
+    source  = "./my-function"
+    runtime = "wasm"
+  }
+}
@@ -0,0 +1,15 @@
+pipeline "invalid_http_step_auth_and_basic_auth" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    basic_auth {
+      username = "user"
+      password = "pass"
+    }
+
+    auth {
+      type  = "bearer"
+      token = "abc"
+    }
+  }
+}
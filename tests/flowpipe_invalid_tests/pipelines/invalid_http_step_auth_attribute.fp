@@ -0,0 +1,11 @@
+pipeline "invalid_http_step_auth_attribute" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    auth {
+      type   = "bearer"
+      token  = "abc"
+      region = "us-east-1"
+    }
+  }
+}
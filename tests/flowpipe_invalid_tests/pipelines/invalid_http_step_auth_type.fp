@@ -0,0 +1,10 @@
+pipeline "invalid_http_step_auth_type" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    auth {
+      type  = "digest"
+      token = "abc"
+    }
+  }
+}
@@ -0,0 +1,7 @@
+pipeline "invalid_http_step_connect_timeout" {
+
+  step "http" "list_items" {
+    url             = "https://api.example.com/items"
+    connect_timeout = "5 seconds"
+  }
+}
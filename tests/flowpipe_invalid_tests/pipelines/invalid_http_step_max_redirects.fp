@@ -0,0 +1,7 @@
+pipeline "invalid_http_step_max_redirects" {
+
+  step "http" "list_items" {
+    url           = "https://api.example.com/items"
+    max_redirects = -1
+  }
+}
@@ -0,0 +1,13 @@
+pipeline "invalid_http_step_pagination_and_loop" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    loop {
+      until = loop.index >= 2
+    }
+
+    pagination {
+      type = "link_header"
+    }
+  }
+}
@@ -0,0 +1,10 @@
+pipeline "invalid_http_step_pagination_items_path" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    pagination {
+      type       = "link_header"
+      items_path = "data.items"
+    }
+  }
+}
@@ -0,0 +1,10 @@
+pipeline "invalid_http_step_pagination_max_pages" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    pagination {
+      type      = "link_header"
+      max_pages = 0
+    }
+  }
+}
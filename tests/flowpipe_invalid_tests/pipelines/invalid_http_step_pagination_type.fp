@@ -0,0 +1,9 @@
+pipeline "invalid_http_step_pagination_type" {
+  step "http" "my_step" {
+    url = "https://example.com"
+
+    pagination {
+      type = "token"
+    }
+  }
+}
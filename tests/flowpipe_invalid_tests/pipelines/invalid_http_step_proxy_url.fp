@@ -0,0 +1,7 @@
+pipeline "invalid_http_step_proxy_url" {
+
+  step "http" "list_items" {
+    url       = "https://api.example.com/items"
+    proxy_url = "proxy.example.com:3128"
+  }
+}
@@ -0,0 +1,15 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "http" "invalid_http_trigger_verify_header" {
+    pipeline = pipeline.simple_with_trigger
+
+    verify {
+        type   = "github"
+        secret = connection.github.default.token
+        header = "X-Custom"
+    }
+}
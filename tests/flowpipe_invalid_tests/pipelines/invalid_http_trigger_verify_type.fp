@@ -0,0 +1,14 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "http" "invalid_http_trigger_verify_type" {
+    pipeline = pipeline.simple_with_trigger
+
+    verify {
+        type   = "gitlab"
+        secret = connection.gitlab.default.token
+    }
+}
@@ -0,0 +1,13 @@
+pipeline "invalid_query_step_isolation_level" {
+  step "query" "my_step" {
+    database        = "sqlite:./orders.db"
+    transaction     = true
+    isolation_level = "snapshot"
+
+    statements = [
+      {
+        sql = "delete from orders"
+      }
+    ]
+  }
+}
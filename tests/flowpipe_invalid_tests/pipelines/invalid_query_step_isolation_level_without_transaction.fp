@@ -0,0 +1,7 @@
+pipeline "invalid_query_step_isolation_level_without_transaction" {
+  step "query" "my_step" {
+    database        = "sqlite:./orders.db"
+    sql             = "delete from orders"
+    isolation_level = "serializable"
+  }
+}
@@ -0,0 +1,10 @@
+pipeline "invalid_query_step_named_args" {
+  step "query" "my_step" {
+    database = "sqlite:./orders.db"
+    sql      = "select * from orders where item = :item"
+
+    args = {
+      name = "widget"
+    }
+  }
+}
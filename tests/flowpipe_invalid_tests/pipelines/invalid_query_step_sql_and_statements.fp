@@ -0,0 +1,12 @@
+pipeline "invalid_query_step_sql_and_statements" {
+  step "query" "my_step" {
+    database = "sqlite:./orders.db"
+    sql      = "select * from orders"
+
+    statements = [
+      {
+        sql = "delete from orders"
+      }
+    ]
+  }
+}
@@ -0,0 +1,11 @@
+pipeline "invalid_query_step_statement" {
+  step "query" "my_step" {
+    database = "sqlite:./orders.db"
+
+    statements = [
+      {
+        query = "delete from orders"
+      }
+    ]
+  }
+}
@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "schedule" "bad_calendar" {
+    schedule = "0 9 * * *"
+    calendar = "./does_not_exist.ics"
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "schedule" "bad_exclude_dates" {
+    schedule = "0 9 * * *"
+    exclude_dates = ["25/12/2024"]
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,12 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "schedule" "same_window" {
+    schedule   = "hourly"
+    start_time = "09:00"
+    end_time   = "9:00"
+    pipeline   = pipeline.simple_with_trigger
+}
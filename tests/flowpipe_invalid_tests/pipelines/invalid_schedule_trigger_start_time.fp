@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "schedule" "bad_start_time" {
+    schedule = "0 9 * * *"
+    start_time = "9am"
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,11 @@
+pipeline "simple_with_trigger" {
+    step "transform" "simple_echo" {
+        value = "foo bar"
+    }
+}
+
+trigger "schedule" "bad_timezone" {
+    schedule = "0 9 * * *"
+    timezone = "Mars/Olympus"
+    pipeline = pipeline.simple_with_trigger
+}
@@ -0,0 +1,26 @@
+mod "http_step_auth" {
+  title = "http_step_auth"
+}
+
+pipeline "http_step_auth" {
+
+  step "http" "bearer" {
+    url = "https://api.github.com/user"
+
+    auth {
+      type  = "bearer"
+      token = connection.github.default.token
+    }
+  }
+
+  step "http" "aws_sigv4" {
+    url = "https://abc123.execute-api.us-east-1.amazonaws.com/prod/items"
+
+    auth {
+      type       = "aws_sigv4"
+      connection = connection.aws.default
+      region     = "us-east-1"
+      service    = "execute-api"
+    }
+  }
+}
@@ -0,0 +1,73 @@
+mod "mod_notifier_routing" {
+
+}
+
+pipeline "deploy_api" {
+  tags = {
+    team = "infra"
+  }
+
+  step "message" "failed" {
+    notifier = notifier.oncall
+    text     = "The deployment failed"
+    severity = "error"
+  }
+}
+
+pipeline "report" {
+  step "message" "done" {
+    notifier = notifier.oncall
+    text     = "The report is ready"
+  }
+}
+
+pipeline "loop_alerts" {
+  step "message" "alert" {
+    for_each = ["a", "b", "c", "d"]
+    notifier = notifier.loop_alerts
+    subject  = "Disk Full"
+    text     = "Disk ${each.value} is full"
+  }
+}
+
+pipeline "rich_release" {
+  param "version" {
+    type    = string
+    default = "v1.2.3"
+  }
+
+  step "message" "released" {
+    notifier = notifier.oncall
+    subject  = "Release"
+    text     = "The release is **live**"
+    severity = "success"
+    color    = "#00FF00"
+
+    sections = [
+      {
+        fields = [
+          { title = "Version", value = param.version },
+          { title = "Env", value = "prod" }
+        ]
+        links = [{ text = "Changelog", url = "https://example.com/changelog" }]
+      }
+    ]
+  }
+
+  step "input" "approve" {
+    notifier = notifier.oncall
+    type     = "button"
+    prompt   = "Promote the release?"
+    color    = "#2F80ED"
+
+    sections = [
+      {
+        text  = "The canary is healthy"
+        image = { url = "https://example.com/canary.png", alt_text = "canary" }
+      }
+    ]
+
+    option "promote" {}
+    option "hold" {}
+  }
+}
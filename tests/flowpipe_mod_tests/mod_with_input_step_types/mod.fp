@@ -0,0 +1,119 @@
+mod "mod_with_input_step_types" {
+  title = "mod_with_input_step_types"
+}
+
+pipeline "number_input" {
+  step "input" "replicas" {
+    notifier = notifier.default
+
+    type        = "number"
+    prompt      = "How many replicas?"
+    min         = 1
+    max         = 10
+    placeholder = "3"
+    validation  = value % 1 == 0
+  }
+}
+
+pipeline "date_inputs" {
+  step "input" "start_date" {
+    notifier = notifier.default
+
+    type   = "date"
+    prompt = "When should the change start?"
+  }
+
+  step "input" "window" {
+    notifier = notifier.default
+
+    type        = "datetime"
+    prompt      = "When should the maintenance window start?"
+    placeholder = "2024-01-31T22:00"
+  }
+}
+
+pipeline "confirm_input" {
+  param "environment" {
+    type    = string
+    default = "production"
+  }
+
+  step "input" "confirm" {
+    notifier = notifier.default
+
+    type           = "confirm"
+    prompt         = "Type the name of the environment to delete it"
+    confirm_phrase = param.environment
+  }
+}
+
+pipeline "text_input" {
+  step "input" "ticket" {
+    notifier = notifier.default
+
+    type        = "text"
+    prompt      = "Change ticket"
+    pattern     = "CHG[0-9]{6}"
+    placeholder = "CHG000123"
+    validation  = value != "CHG000000"
+  }
+}
+
+pipeline "dynamic_options" {
+  step "transform" "regions" {
+    value = ["us-east-1", "us-west-2"]
+  }
+
+  step "input" "region" {
+    notifier = notifier.default
+
+    type    = "select"
+    prompt  = "Which region?"
+    options = step.transform.regions.value
+  }
+
+  step "input" "regions" {
+    notifier = notifier.default
+
+    type    = "multiselect"
+    prompt  = "Which regions?"
+    options = [for r in step.transform.regions.value : { value = r, label = upper(r) }]
+  }
+}
+
+pipeline "input_timeout" {
+  step "input" "approve" {
+    notifier = notifier.default
+
+    type   = "button"
+    prompt = "Approve the change?"
+
+    option "approve" {}
+    option "deny" {}
+
+    timeout           = "8h"
+    default_value     = "deny"
+    reminder          = "90m"
+    escalation        = [notifier.managers, notifier.directors]
+    escalation_window = "3h"
+  }
+}
+
+pipeline "input_timeout_param" {
+  param "reminder" {
+    type    = string
+    default = "1h"
+  }
+
+  step "input" "replicas" {
+    notifier = notifier.default
+
+    type   = "number"
+    prompt = "How many replicas?"
+
+    timeout       = 7200000
+    default_value = "3"
+    on_timeout    = "continue"
+    reminder      = param.reminder
+  }
+}
@@ -0,0 +1,8 @@
+mod "trigger_http_verify" {
+  title = "trigger_http_verify"
+}
+
+variable "github_webhook_secret" {
+  type    = string
+  default = "It's a Secret to Everybody"
+}
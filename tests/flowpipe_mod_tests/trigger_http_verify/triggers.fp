@@ -0,0 +1,42 @@
+pipeline "simple" {
+  param "event" {
+    type    = any
+    default = {}
+  }
+
+  step "transform" "echo" {
+    value = param.event
+  }
+}
+
+trigger "http" "github" {
+  pipeline = pipeline.simple
+
+  verify {
+    type   = "github"
+    secret = var.github_webhook_secret
+  }
+}
+
+trigger "http" "slack" {
+  verify {
+    type      = "slack"
+    secret    = connection.slack.default.token
+    tolerance = "2m"
+  }
+
+  method "post" {
+    pipeline = pipeline.simple
+  }
+}
+
+trigger "http" "generic" {
+  pipeline = pipeline.simple
+
+  verify {
+    type   = "hmac_sha256"
+    secret = var.github_webhook_secret
+    header = "X-Acme-Signature"
+    prefix = "sha256="
+  }
+}
@@ -51,3 +51,21 @@ pipeline "github_issue" {
     url = "https://api.github.com/repos/octocat/${param.gh_repo}/issues/2743"
   }
 }
+
+trigger "file" "csv_dropped" {
+  path     = "/data/inbox/**/*.csv"
+  events   = ["create", "modify"]
+  debounce = "5s"
+  ignore   = ["*.tmp", "/data/inbox/archive/**"]
+
+  pipeline = pipeline.github_issue
+
+  args = {
+    gh_repo = self.path
+  }
+}
+
+trigger "file" "any_change" {
+  path     = "inbox/*"
+  pipeline = pipeline.github_issue
+}
@@ -0,0 +1,113 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCompensate(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/compensate.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.provision"]
+	if pipeline == nil {
+		assert.Fail("pipeline not found")
+		return
+	}
+
+	createVm := pipeline.GetStep("transform.create_vm")
+	compensateConfig := createVm.GetCompensateConfig()
+	if compensateConfig == nil {
+		assert.Fail("compensate config not found")
+		return
+	}
+	assert.Equal("local.pipeline.delete_vm", compensateConfig.PipelineName)
+	assert.NotNil(compensateConfig.UnresolvedAttributes[schema.AttributeTypeArgs])
+	assert.Equal(0, len(compensateConfig.DependsOn))
+
+	allocateIp := pipeline.GetStep("transform.allocate_ip")
+	compensateConfig = allocateIp.GetCompensateConfig()
+	if compensateConfig == nil {
+		assert.Fail("compensate config not found")
+		return
+	}
+	assert.Equal("local.pipeline.release_ip", compensateConfig.PipelineName)
+	assert.NotNil(compensateConfig.UnresolvedAttributes[schema.AttributeTypeIf])
+	assert.Equal([]string{"transform.create_vm"}, compensateConfig.DependsOn)
+
+	// the compensate block references must not change the step dependencies
+	assert.Equal([]string{"transform.create_vm"}, allocateIp.GetDependsOn())
+
+	// static args are resolved at parse time
+	compensateConfig = pipeline.GetStep("transform.create_disk").GetCompensateConfig()
+	assert.Equal("static", compensateConfig.Args["id"])
+
+	assert.Nil(pipeline.GetStep("transform.tag_vm").GetCompensateConfig())
+
+	// resolve the compensation at runtime
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"result": cty.ObjectVal(map[string]cty.Value{
+				"value": cty.StringVal("10.0.0.1"),
+			}),
+			"step": cty.ObjectVal(map[string]cty.Value{
+				"transform": cty.ObjectVal(map[string]cty.Value{
+					"create_vm": cty.ObjectVal(map[string]cty.Value{
+						"value": cty.StringVal("vm-one"),
+					}),
+				}),
+			}),
+		},
+	}
+
+	resolved, diags := allocateIp.GetCompensateConfig().Resolve(evalContext)
+	assert.Equal(0, len(diags))
+	assert.True(*resolved.If)
+	assert.Equal("local.pipeline.release_ip", resolved.PipelineName)
+	assert.Equal("10.0.0.1", resolved.Args["ip"])
+	assert.Equal("vm-one", resolved.Args["vm_id"])
+
+	evalContext.Variables["result"] = cty.ObjectVal(map[string]cty.Value{
+		"value": cty.StringVal(""),
+	})
+	resolved, diags = allocateIp.GetCompensateConfig().Resolve(evalContext)
+	assert.Equal(0, len(diags))
+	assert.False(*resolved.If)
+	assert.Equal("", resolved.PipelineName)
+}
+
+func TestCompensationOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/compensate.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.provision"]
+	if pipeline == nil {
+		assert.Fail("pipeline not found")
+		return
+	}
+
+	var names []string
+	for _, step := range pipeline.GetCompensationOrder(nil) {
+		names = append(names, step.GetFullyQualifiedName())
+	}
+	assert.Equal([]string{"transform.create_disk", "transform.allocate_ip", "transform.create_vm"}, names)
+
+	// only the completed steps are compensated
+	names = nil
+	for _, step := range pipeline.GetCompensationOrder([]string{"transform.create_vm", "transform.create_disk"}) {
+		names = append(names, step.GetFullyQualifiedName())
+	}
+	assert.Equal([]string{"transform.create_disk", "transform.create_vm"}, names)
+
+	assert.Equal(0, len(pipeline.GetCompensationOrder([]string{})))
+}
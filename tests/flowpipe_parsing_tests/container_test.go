@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -261,3 +262,91 @@ func TestContainerStep(t *testing.T) {
 	env = inputs[schema.AttributeTypeEnv].(map[string]string)
 	assert.Equal("ap-south-1", env["REGION"])
 }
+
+func TestContainerStepSandbox(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/container_sandbox.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.container_sandbox"]
+	if pipeline == nil {
+		assert.Fail("container_sandbox pipeline not found")
+		return
+	}
+
+	step, ok := pipeline.GetStep("container.scan").(*modconfig.PipelineStepContainer)
+	if !ok {
+		assert.Fail("container.scan step not found")
+		return
+	}
+	assert.Equal("none", *step.NetworkMode)
+	assert.Equal(int64(64), *step.PidsLimit)
+	assert.Equal([]string{"ALL"}, step.CapDrop)
+	assert.Equal("json", *step.OutputFormat)
+	assert.Equal(2, len(step.Mounts))
+
+	// the source of the first mount references a param so it's resolved at runtime
+	assert.Equal("", step.Mounts[0].Source)
+	assert.NotNil(step.Mounts[0].UnresolvedAttributes[schema.AttributeTypeSource])
+	assert.Equal("/mod", step.Mounts[0].Target)
+	assert.True(step.Mounts[0].IsReadOnly())
+	assert.False(step.Mounts[1].IsReadOnly())
+	assert.True(step.Equals(step))
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"mod_dir": cty.StringVal("/work/my_mod"),
+			}),
+		},
+	}
+
+	inputs, err := step.GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs", err)
+		return
+	}
+	assert.Equal("none", inputs[schema.AttributeTypeNetworkMode])
+	assert.Equal(`{"rules": ["all"]}`, inputs[schema.AttributeTypeStdin])
+	assert.Equal(int64(64), inputs[schema.AttributeTypePidsLimit])
+	assert.Equal([]string{"ALL"}, inputs[schema.AttributeTypeCapDrop])
+	assert.Equal("json", inputs[schema.AttributeTypeOutputFormat])
+	assert.Equal([]map[string]interface{}{
+		{"source": "/work/my_mod", "target": "/mod", "read_only": true},
+		{"source": "/tmp/scan-cache", "target": "/cache", "read_only": false},
+	}, inputs[schema.AttributeTypeMounts])
+
+	output, err := modconfig.ParseContainerOutput(inputs[schema.AttributeTypeOutputFormat].(string), `{"findings": 0}`)
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{"findings": float64(0)}, output)
+
+	// the loop overrides the network mode, the pids limit and the mounts
+	loopStep := pipeline.GetStep("container.scan_loop")
+	loopConfig, ok := loopStep.GetLoopConfig().(*modconfig.LoopContainerStep)
+	if !ok {
+		assert.Fail("container.scan_loop loop not found")
+		return
+	}
+	assert.Equal("none", *loopConfig.NetworkMode)
+	assert.Equal(int64(32), *loopConfig.PidsLimit)
+	assert.NotNil(loopConfig.UnresolvedAttributes[schema.AttributeTypeMounts])
+
+	inputs, err = loopStep.GetInputs(nil)
+	assert.Nil(err)
+
+	loopEvalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"loop": cty.ObjectVal(map[string]cty.Value{
+				"index": cty.NumberIntVal(1),
+			}),
+		},
+	}
+	loopInputs, err := loopConfig.UpdateInput(inputs, loopEvalContext)
+	assert.Nil(err)
+	assert.Equal("none", loopInputs[schema.AttributeTypeNetworkMode])
+	assert.Equal(int64(32), loopInputs[schema.AttributeTypePidsLimit])
+	assert.Equal([]map[string]interface{}{
+		{"source": "/tmp/scan-cache-1", "target": "/cache", "read_only": true},
+	}, loopInputs[schema.AttributeTypeMounts])
+}
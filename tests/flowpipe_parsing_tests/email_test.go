@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -16,7 +17,7 @@ func TestEmailStep(t *testing.T) {
 
 	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/email.fp")
 	assert.Nil(err, "error found")
-	assert.Equal(2, len(pipelines), "wrong number of pipelines")
+	assert.Equal(3, len(pipelines), "wrong number of pipelines")
 
 	if pipelines["local.pipeline.pipeline_with_email_step"] == nil {
 		assert.Fail("pipeline_with_email_step pipeline not found")
@@ -154,3 +155,67 @@ func TestEmailStep(t *testing.T) {
 	assert.Equal(1, len(bccRecipients))
 	assert.Equal("elastic.bassi@example.com", bccRecipients[0])
 }
+
+func TestEmailStepAttachments(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/email.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.pipeline_with_email_attachments"]
+	if pipeline == nil {
+		assert.Fail("pipeline_with_email_attachments pipeline not found")
+		return
+	}
+
+	step, ok := pipeline.GetStep("email.with_attachments").(*modconfig.PipelineStepEmail)
+	if !ok {
+		assert.Fail("email step not found")
+		return
+	}
+	assert.Equal(3, len(step.Attachments))
+	assert.Contains(step.GetDependsOn(), "transform.report")
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"step": cty.ObjectVal(map[string]cty.Value{
+				"transform": cty.ObjectVal(map[string]cty.Value{
+					"report": cty.ObjectVal(map[string]cty.Value{
+						"value": cty.StringVal("id,amount\n1,100\n"),
+					}),
+				}),
+			}),
+		},
+	}
+
+	inputs, err := step.GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs: " + err.Error())
+		return
+	}
+
+	assert.Equal("The daily report is attached.", inputs[schema.AttributeTypeBody])
+	assert.Equal("<p>The daily report is attached.</p><img src=\"cid:logo\">", inputs[schema.AttributeTypeHtmlBody])
+	assert.Equal([]string{"support@example.com"}, inputs[schema.AttributeTypeReplyTo])
+	assert.Equal(map[string]string{
+		"X-Priority":       "1",
+		"List-Unsubscribe": "<mailto:unsubscribe@example.com>",
+	}, inputs[schema.AttributeTypeHeaders])
+
+	assert.Equal([]map[string]interface{}{
+		{
+			schema.AttributeTypePath:     "./reports/summary.pdf",
+			schema.AttributeTypeFilename: "summary.pdf",
+		},
+		{
+			schema.AttributeTypeContent:     "id,amount\n1,100\n",
+			schema.AttributeTypeFilename:    "report.csv",
+			schema.AttributeTypeContentType: "text/csv",
+		},
+		{
+			schema.AttributeTypePath:      "./images/logo.png",
+			schema.AttributeTypeFilename:  "logo.png",
+			schema.AttributeTypeContentId: "logo",
+		},
+	}, inputs[schema.AttributeTypeAttachments])
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -16,7 +17,7 @@ func TestFunctionStep(t *testing.T) {
 
 	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/function.fp")
 	assert.Nil(err, "error found")
-	assert.Equal(4, len(pipelines), "wrong number of pipelines")
+	assert.Equal(5, len(pipelines), "wrong number of pipelines")
 
 	if pipelines["local.pipeline.function_step_test"] == nil {
 		assert.Fail("function_step_test pipeline not found")
@@ -130,3 +131,58 @@ func TestFunctionStep(t *testing.T) {
 	assert.Equal("my_file.my_handler", inputs[schema.AttributeTypeHandler])
 	assert.Equal("10s", inputs[schema.AttributeTypeTimeout])
 }
+
+func TestFunctionStepWasm(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/function.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.function_step_test_wasm"]
+	if pipeline == nil {
+		assert.Fail("function_step_test_wasm pipeline not found")
+		return
+	}
+
+	step, ok := pipeline.GetStep("function.my_func").(*modconfig.PipelineStepFunction)
+	if !ok {
+		assert.Fail("function step not found")
+		return
+	}
+
+	assert.NotNil(step.Wasm)
+	assert.Equal(int64(64), *step.Wasm.MemoryLimit)
+	assert.Nil(step.Wasm.FuelLimit)
+	assert.Equal([]string{"HOME", "TZ"}, step.Wasm.AllowedEnv)
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"fuel_limit": cty.NumberIntVal(1000000),
+			}),
+		},
+	}
+
+	inputs, err := step.GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs: " + err.Error())
+		return
+	}
+	assert.Equal("./functions/resize.wasm", inputs[schema.AttributeTypeSource])
+	assert.Equal("wasm", inputs[schema.AttributeTypeRuntime])
+	assert.Equal("resize", inputs[schema.AttributeTypeHandler])
+	assert.Equal(map[string]interface{}{
+		schema.AttributeTypeMemoryLimit: int64(64),
+		schema.AttributeTypeFuelLimit:   int64(1000000),
+		schema.AttributeTypeAllowedEnv:  []string{"HOME", "TZ"},
+	}, inputs[schema.BlockTypeWasm])
+
+	// the handler defaults to the WASI entry point
+	inputs, err = pipeline.GetStep("function.default_handler").GetInputs(nil)
+	if err != nil {
+		assert.Fail("error getting inputs: " + err.Error())
+		return
+	}
+	assert.Equal(modconfig.FunctionWasmDefaultHandler, inputs[schema.AttributeTypeHandler])
+	assert.Nil(inputs[schema.BlockTypeWasm])
+}
@@ -0,0 +1,79 @@
+package pipeline_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHttpStepPagination(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/http_pagination.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.http_pagination"]
+	if pipeline == nil {
+		assert.Fail("http_pagination pipeline not found")
+		return
+	}
+
+	linkHeaderStep, ok := pipeline.GetStep("http.link_header").(*modconfig.PipelineStepHttp)
+	if !ok || linkHeaderStep.PaginationConfig == nil {
+		assert.Fail("http.link_header step pagination not found")
+		return
+	}
+	assert.Equal(modconfig.HttpPaginationTypeLinkHeader, linkHeaderStep.PaginationConfig.Type)
+	assert.Equal(int64(10), linkHeaderStep.PaginationConfig.GetMaxPages())
+
+	cursorStep := pipeline.GetStep("http.cursor").(*modconfig.PipelineStepHttp)
+	assert.Equal("$.response_metadata.next_cursor", *cursorStep.PaginationConfig.CursorPath)
+	assert.Equal("cursor", *cursorStep.PaginationConfig.QueryParam)
+	assert.Equal("$.channels", *cursorStep.PaginationConfig.ItemsPath)
+	assert.Equal(int64(modconfig.DefaultHttpPaginationMaxPages), cursorStep.PaginationConfig.GetMaxPages())
+
+	pageStep := pipeline.GetStep("http.page").(*modconfig.PipelineStepHttp)
+	assert.Equal(int64(1), pageStep.PaginationConfig.GetStart())
+	assert.Equal(int64(1), pageStep.PaginationConfig.GetIncrement())
+
+	// the increment references a param so it's resolved at runtime
+	offsetStep := pipeline.GetStep("http.offset").(*modconfig.PipelineStepHttp)
+	assert.Nil(offsetStep.PaginationConfig.Increment)
+	assert.NotNil(offsetStep.PaginationConfig.UnresolvedAttributes["increment"])
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"page_size": cty.NumberIntVal(50),
+			}),
+		},
+	}
+
+	inputs, err := offsetStep.GetInputs(evalContext)
+	assert.Nil(err)
+	pagination := inputs["pagination"].(map[string]interface{})
+	assert.Equal("offset", pagination["type"])
+	assert.Equal(int64(0), pagination["start"])
+	assert.Equal(int64(50), pagination["increment"])
+	assert.Equal(int64(5), pagination["max_pages"])
+
+	// json round trip
+	for _, step := range []*modconfig.PipelineStepHttp{linkHeaderStep, cursorStep, pageStep} {
+		data, err := json.Marshal(step.PaginationConfig)
+		assert.Nil(err)
+
+		var roundTripped modconfig.HttpPaginationConfig
+		assert.Nil(json.Unmarshal(data, &roundTripped))
+		assert.True(step.PaginationConfig.Equals(&roundTripped), "pagination of step %s does not round trip", step.Name)
+	}
+
+	assert.False(cursorStep.PaginationConfig.Equals(pageStep.PaginationConfig))
+	assert.False(offsetStep.Equals(pageStep))
+	assert.True(offsetStep.Equals(offsetStep))
+}
@@ -0,0 +1,72 @@
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/httpclient"
+	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHttpStepTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/http_transport.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.http_transport"]
+	if pipeline == nil {
+		assert.Fail("http_transport pipeline not found")
+		return
+	}
+
+	staticStep, ok := pipeline.GetStep("http.static").(*modconfig.PipelineStepHttp)
+	if !ok {
+		assert.Fail("http.static step not found")
+		return
+	}
+	assert.Equal("socks5://proxy.example.com:1080", *staticStep.ProxyUrl)
+	assert.Equal([]string{"localhost", ".internal.example.com"}, staticStep.NoProxy)
+	assert.Equal("5s", *staticStep.ConnectTimeout)
+	assert.Equal(int64(0), *staticStep.MaxRedirects)
+	assert.False(*staticStep.Http2)
+
+	inputs, err := staticStep.GetInputs(nil)
+	assert.Nil(err)
+
+	config, err := httpclient.ConfigFromMap(inputs)
+	assert.Nil(err)
+	assert.Equal(5*time.Second, *config.ConnectTimeout)
+	assert.Equal(30*time.Second, *config.Timeout)
+	assert.Equal(0, *config.MaxRedirects)
+	assert.False(*config.Http2)
+
+	// the proxy url references a param so it's resolved at runtime
+	dynamicStep := pipeline.GetStep("http.dynamic").(*modconfig.PipelineStepHttp)
+	assert.Nil(dynamicStep.ProxyUrl)
+	assert.NotNil(dynamicStep.UnresolvedAttributes["proxy_url"])
+	assert.Equal(int64(3), *dynamicStep.MaxRedirects)
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"proxy_url": cty.StringVal("http://proxy.example.com:3128"),
+			}),
+		},
+	}
+
+	inputs, err = dynamicStep.GetInputs(evalContext)
+	assert.Nil(err)
+	assert.Equal("http://proxy.example.com:3128", inputs["proxy_url"])
+
+	evalContext.Variables["param"] = cty.ObjectVal(map[string]cty.Value{
+		"proxy_url": cty.StringVal("proxy.example.com"),
+	})
+	_, err = dynamicStep.GetInputs(evalContext)
+	assert.NotNil(err)
+}
@@ -0,0 +1,121 @@
+package pipeline_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+func TestPipelineParamsJsonSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/json_schema.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.create_user"]
+	if pipeline == nil {
+		assert.Fail("create_user pipeline not found")
+		return
+	}
+
+	paramsSchema := pipeline.ParamsJsonSchema()
+	assert.Equal(modconfig.JsonSchemaDraft, paramsSchema.Schema)
+	assert.Equal("object", paramsSchema.Type)
+	assert.Equal("Create user", paramsSchema.Title)
+	assert.Equal([]string{"name"}, paramsSchema.Required)
+	assert.Equal(false, paramsSchema.AdditionalProperties)
+
+	assert.Equal("string", paramsSchema.Properties["name"].Type)
+	assert.Equal("The user name", paramsSchema.Properties["name"].Description)
+
+	assert.Equal("string", paramsSchema.Properties["role"].Type)
+	assert.Equal("viewer", paramsSchema.Properties["role"].Default)
+	assert.Equal([]any{"viewer", "editor", "admin"}, paramsSchema.Properties["role"].Enum)
+
+	assert.Equal("array", paramsSchema.Properties["groups"].Type)
+	assert.Equal("string", paramsSchema.Properties["groups"].Items.Type)
+
+	profile := paramsSchema.Properties["profile"]
+	assert.Equal("object", profile.Type)
+	assert.Equal([]string{"email"}, profile.Required)
+	assert.Equal("number", profile.Properties["age"].Type)
+
+	labels := paramsSchema.Properties["labels"]
+	assert.Equal("object", labels.Type)
+	assert.Equal("boolean", labels.AdditionalProperties.(*modconfig.JsonSchema).Type)
+
+	outputsSchema := pipeline.OutputsJsonSchema()
+	assert.Equal(1, len(outputsSchema.Properties))
+	assert.Equal("The id of the new user", outputsSchema.Properties["user_id"].Description)
+
+	// must be serialisable
+	_, err = json.Marshal(paramsSchema)
+	assert.Nil(err)
+}
+
+func TestHttpTriggerOpenApiDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, triggers, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/json_schema.fp")
+	assert.Nil(err, "error found")
+
+	doc, err := modconfig.NewOpenApiDocument("test", "1.0.0", pipelines, triggers)
+	if err != nil {
+		assert.Fail("error generating OpenAPI document", err)
+		return
+	}
+
+	assert.Equal(modconfig.OpenApiVersion, doc.OpenApi)
+
+	// the schedule trigger must not be included
+	assert.Equal(1, len(doc.Paths))
+
+	pathItem := doc.Paths["/hook/local.trigger.http.create_user"]
+	if pathItem == nil {
+		assert.Fail("path for create_user trigger not found")
+		return
+	}
+	assert.Equal("Create user webhook", pathItem.Summary)
+
+	post := pathItem.Post
+	if post == nil {
+		assert.Fail("post operation not found")
+		return
+	}
+	assert.True(post.RequestBody.Required)
+	assert.Equal("#/components/schemas/local_pipeline_create_user_params", post.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal("#/components/schemas/local_pipeline_create_user_outputs", post.Responses["200"].Content["application/json"].Schema.Ref)
+
+	get := pathItem.Get
+	if get == nil {
+		assert.Fail("get operation not found")
+		return
+	}
+	assert.Nil(get.RequestBody)
+	assert.Nil(get.Responses["200"].Content)
+
+	paramsSchema := doc.Components.Schemas["local_pipeline_create_user_params"]
+	if paramsSchema == nil {
+		assert.Fail("params schema not found")
+		return
+	}
+	assert.Equal("", paramsSchema.Schema)
+	assert.Equal(5, len(paramsSchema.Properties))
+
+	_, err = json.Marshal(doc)
+	assert.Nil(err)
+}
+
+func TestHttpTriggerOpenApiDocumentMissingPipeline(t *testing.T) {
+	assert := assert.New(t)
+
+	_, triggers, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/json_schema.fp")
+	assert.Nil(err, "error found")
+
+	_, err = modconfig.NewOpenApiDocument("test", "1.0.0", map[string]*modconfig.Pipeline{}, triggers)
+	assert.NotNil(err)
+}
@@ -0,0 +1,80 @@
+pipeline "delete_vm" {
+  param "id" {
+    type = string
+  }
+
+  step "transform" "delete" {
+    value = param.id
+  }
+}
+
+pipeline "release_ip" {
+  param "ip" {
+    type = string
+  }
+
+  param "vm_id" {
+    type     = string
+    optional = true
+  }
+
+  step "transform" "release" {
+    value = param.ip
+  }
+}
+
+pipeline "provision" {
+  param "name" {
+    type    = string
+    default = "vm"
+  }
+
+  step "transform" "create_vm" {
+    value = "vm-${param.name}"
+
+    compensate {
+      pipeline = pipeline.delete_vm
+      args = {
+        id = result.value
+      }
+    }
+  }
+
+  step "transform" "allocate_ip" {
+    value = "10.0.0.1"
+
+    compensate {
+      pipeline = pipeline.release_ip
+      if       = result.value != ""
+      args = {
+        ip    = result.value
+        vm_id = step.transform.create_vm.value
+      }
+    }
+
+    depends_on = [step.transform.create_vm]
+  }
+
+  step "transform" "tag_vm" {
+    value = "tagged"
+
+    depends_on = [step.transform.allocate_ip]
+  }
+
+  step "transform" "create_disk" {
+    value = "disk"
+
+    compensate {
+      pipeline = pipeline.delete_vm
+      args = {
+        id = "static"
+      }
+    }
+  }
+
+  step "transform" "notify" {
+    value = "done"
+
+    depends_on = [step.transform.tag_vm, step.transform.create_disk]
+  }
+}
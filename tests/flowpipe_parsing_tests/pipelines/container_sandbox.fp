@@ -0,0 +1,51 @@
+pipeline "container_sandbox" {
+
+  param "mod_dir" {
+    type    = string
+    default = "/home/flowpipe/mod"
+  }
+
+  step "container" "scan" {
+    image         = "test/scanner"
+    cmd           = ["scan", "/mod"]
+    network_mode  = "none"
+    stdin         = "{\"rules\": [\"all\"]}"
+    pids_limit    = 64
+    cap_drop      = ["ALL"]
+    output_format = "json"
+
+    mount {
+      source    = param.mod_dir
+      target    = "/mod"
+      read_only = true
+    }
+
+    mount {
+      source = "/tmp/scan-cache"
+      target = "/cache"
+    }
+  }
+
+  step "container" "scan_loop" {
+    image        = "test/scanner"
+    network_mode = "bridge"
+
+    mount {
+      source = "/tmp/scan-cache"
+      target = "/cache"
+    }
+
+    loop {
+      until        = loop.index >= 2
+      network_mode = "none"
+      pids_limit   = 32
+      mounts = [
+        {
+          source    = "/tmp/scan-cache-${loop.index}"
+          target    = "/cache"
+          read_only = true
+        }
+      ]
+    }
+  }
+}
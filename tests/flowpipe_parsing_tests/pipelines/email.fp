@@ -109,3 +109,47 @@ pipeline "pipeline_with_unresolved_email_step_attributes" {
     bcc          = param.bcc          // optional
   }
 }
+
+pipeline "pipeline_with_email_attachments" {
+
+  description = "Pipeline with an email step with attachments and inline images"
+
+  step "transform" "report" {
+    value = "id,amount\n1,100\n"
+  }
+
+  step "email" "with_attachments" {
+    smtp_username = "admiring.dijkstra@example.com"
+    smtp_password = "abcdefghijklmnop"
+    port          = 587
+    host          = "smtp.gmail.com"
+
+    from     = "sleepy.feynman@example.com"
+    to       = ["friendly.curie@example.com"]
+    reply_to = ["support@example.com"]
+
+    subject   = "Daily report"
+    body      = "The daily report is attached."
+    html_body = "<p>The daily report is attached.</p><img src=\"cid:logo\">"
+
+    headers = {
+      "X-Priority"       = "1"
+      "List-Unsubscribe" = "<mailto:unsubscribe@example.com>"
+    }
+
+    attachment {
+      path = "./reports/summary.pdf"
+    }
+
+    attachment {
+      content      = step.transform.report.value
+      filename     = "report.csv"
+      content_type = "text/csv"
+    }
+
+    attachment {
+      path       = "./images/logo.png"
+      content_id = "logo"
+    }
+  }
+}
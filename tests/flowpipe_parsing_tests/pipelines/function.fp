@@ -98,3 +98,36 @@ pipeline "function_step_test_string_timeout_with_param" {
     }
   }
 }
+
+pipeline "function_step_test_wasm" {
+  param "fuel_limit" {
+    type    = number
+    default = 1000000
+  }
+
+  step "function" "my_func" {
+    source  = "./functions/resize.wasm"
+    runtime = "wasm"
+    handler = "resize"
+    timeout = "10s"
+
+    event = {
+      width = 100
+    }
+
+    env = {
+      foo = "bar"
+    }
+
+    wasm {
+      memory_limit = 64
+      fuel_limit   = param.fuel_limit
+      allowed_env  = ["HOME", "TZ"]
+    }
+  }
+
+  step "function" "default_handler" {
+    source  = "./functions/echo.wasm"
+    runtime = "wasm"
+  }
+}
@@ -0,0 +1,48 @@
+pipeline "http_pagination" {
+
+  param "page_size" {
+    type    = number
+    default = 50
+  }
+
+  step "http" "link_header" {
+    url = "https://api.github.com/repos/turbot/flowpipe/issues"
+
+    pagination {
+      type      = "link_header"
+      max_pages = 10
+    }
+  }
+
+  step "http" "cursor" {
+    url = "https://slack.com/api/conversations.list"
+
+    pagination {
+      type        = "cursor"
+      cursor_path = "$.response_metadata.next_cursor"
+      query_param = "cursor"
+      items_path  = "$.channels"
+    }
+  }
+
+  step "http" "page" {
+    url = "https://api.example.com/items"
+
+    pagination {
+      type        = "page"
+      query_param = "page"
+      items_path  = "$['data']"
+    }
+  }
+
+  step "http" "offset" {
+    url = "https://api.example.com/items?limit=50"
+
+    pagination {
+      type        = "offset"
+      query_param = "offset"
+      increment   = param.page_size
+      max_pages   = 5
+    }
+  }
+}
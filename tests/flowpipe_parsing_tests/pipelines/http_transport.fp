@@ -0,0 +1,23 @@
+pipeline "http_transport" {
+
+  param "proxy_url" {
+    type    = string
+    default = "http://proxy.example.com:3128"
+  }
+
+  step "http" "static" {
+    url             = "https://api.example.com/items"
+    proxy_url       = "socks5://proxy.example.com:1080"
+    no_proxy        = ["localhost", ".internal.example.com"]
+    connect_timeout = "5s"
+    timeout         = "30s"
+    max_redirects   = 0
+    http2           = false
+  }
+
+  step "http" "dynamic" {
+    url           = "https://api.example.com/items"
+    proxy_url     = param.proxy_url
+    max_redirects = 3
+  }
+}
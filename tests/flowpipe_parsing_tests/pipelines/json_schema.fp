@@ -0,0 +1,72 @@
+pipeline "create_user" {
+  title       = "Create user"
+  description = "Create a user account"
+
+  param "name" {
+    type        = string
+    description = "The user name"
+  }
+
+  param "role" {
+    type    = string
+    default = "viewer"
+    enum    = ["viewer", "editor", "admin"]
+  }
+
+  param "groups" {
+    type     = list(string)
+    optional = true
+  }
+
+  param "profile" {
+    type = object({
+      email = string
+      age   = optional(number)
+    })
+    optional = true
+  }
+
+  param "labels" {
+    type    = map(bool)
+    default = {}
+  }
+
+  step "transform" "echo" {
+    value = param.name
+  }
+
+  output "user_id" {
+    description = "The id of the new user"
+    value       = step.transform.echo.value
+  }
+}
+
+trigger "http" "create_user" {
+  title = "Create user webhook"
+
+  method "post" {
+    pipeline       = pipeline.create_user
+    execution_mode = "synchronous"
+
+    args = {
+      name = self.request_body.name
+    }
+  }
+
+  method "get" {
+    pipeline = pipeline.create_user
+
+    args = {
+      name = "anonymous"
+    }
+  }
+}
+
+trigger "schedule" "every_hour" {
+  schedule = "hourly"
+  pipeline = pipeline.create_user
+
+  args = {
+    name = "scheduled"
+  }
+}
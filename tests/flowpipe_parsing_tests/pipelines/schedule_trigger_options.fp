@@ -0,0 +1,47 @@
+pipeline "simple_with_trigger" {
+  description = "simple pipeline that will be referred to by a trigger"
+
+  step "transform" "simple_echo" {
+    value = "foo bar"
+  }
+}
+
+trigger "schedule" "weekday_morning" {
+  schedule      = "0 9 * * 1-5"
+  timezone      = "Europe/London"
+  jitter        = "5m"
+  exclude_dates = ["2024-12-25", "2024-12-26"]
+  calendar      = "./uk_bank_holidays.ics"
+
+  pipeline = pipeline.simple_with_trigger
+}
+
+trigger "schedule" "business_hours" {
+  schedule   = "hourly"
+  timezone   = "America/New_York"
+  start_time = "09:00"
+  end_time   = "17:00"
+
+  pipeline = pipeline.simple_with_trigger
+}
+
+trigger "schedule" "overnight" {
+  schedule   = "0 * * * *"
+  timezone   = "UTC"
+  start_time = "22:00"
+  end_time   = "02:00"
+
+  pipeline = pipeline.simple_with_trigger
+}
+
+trigger "query" "query_weekdays" {
+  timezone      = "Asia/Tokyo"
+  exclude_dates = ["2024-01-01"]
+  database      = "postgres://steampipe:@host.docker.internal:9193/steampipe"
+  sql           = "select 1 as id"
+  primary_key   = "id"
+
+  capture "insert" {
+    pipeline = pipeline.simple_with_trigger
+  }
+}
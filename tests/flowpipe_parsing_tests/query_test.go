@@ -2,11 +2,16 @@ package pipeline_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/backend"
 	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
 	"github.com/turbot/pipe-fittings/schema"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestQueryStep(t *testing.T) {
@@ -76,3 +81,115 @@ func TestQueryStepWithArgs(t *testing.T) {
 	assert.Equal("two", args[0])
 	assert.Equal(10, args[1])
 }
+
+func TestQueryStepWithNamedArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/query.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.query_with_named_args"]
+	if pipeline == nil {
+		assert.Fail("query_with_named_args pipeline not found")
+		return
+	}
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("gadget"),
+			}),
+		},
+	}
+
+	// postgres reuses the $n placeholder of a parameter used twice
+	inputs, err := pipeline.GetStep("query.query_1").GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs", err)
+		return
+	}
+	assert.Equal("select * from foo where name = $1 and (owner = $2 or creator = $2) and kind::text = 'a:b'", inputs[schema.AttributeTypeSql])
+	assert.Equal([]interface{}{"gadget", "bob"}, inputs[schema.AttributeTypeArgs])
+
+	// mysql binds positionally
+	step := pipeline.GetStep("query.query_2").(*modconfig.PipelineStepQuery)
+	assert.Equal(map[string]interface{}{"name": "widget", "owner": "bob"}, step.NamedArgs)
+
+	inputs, err = step.GetInputs(nil)
+	if err != nil {
+		assert.Fail("error getting inputs", err)
+		return
+	}
+	assert.Equal("select * from foo where name = ? and owner = ?", inputs[schema.AttributeTypeSql])
+	assert.Equal([]interface{}{"widget", "bob"}, inputs[schema.AttributeTypeArgs])
+}
+
+func TestQueryStepWithStatements(t *testing.T) {
+	assert := assert.New(t)
+
+	pipelines, _, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/query.fp")
+	assert.Nil(err, "error found")
+
+	pipeline := pipelines["local.pipeline.query_with_statements"]
+	if pipeline == nil {
+		assert.Fail("query_with_statements pipeline not found")
+		return
+	}
+
+	// the statements reference a param so they're resolved at runtime
+	step := pipeline.GetStep("query.transfer").(*modconfig.PipelineStepQuery)
+	assert.Nil(step.Statements)
+	assert.NotNil(step.UnresolvedAttributes[schema.AttributeTypeStatements])
+	assert.True(*step.Transaction)
+	assert.Equal("serializable", *step.IsolationLevel)
+
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"param": cty.ObjectVal(map[string]cty.Value{
+				"quantity": cty.NumberIntVal(3),
+			}),
+		},
+	}
+
+	inputs, err := step.GetInputs(evalContext)
+	if err != nil {
+		assert.Fail("error getting inputs", err)
+		return
+	}
+	assert.Equal(true, inputs[schema.AttributeTypeTransaction])
+	assert.Equal("serializable", inputs[schema.AttributeTypeIsolationLevel])
+
+	statements, ok := inputs[schema.AttributeTypeStatements].([]backend.Statement)
+	if !ok {
+		assert.Fail("statements not found")
+		return
+	}
+	assert.Equal(3, len(statements))
+	assert.Equal("insert into orders (item, quantity) values ($1, $2)", statements[0].Sql)
+	assert.Equal([]interface{}{"widget", 3}, statements[0].Args)
+	assert.Equal("update stock set quantity = quantity - $1 where item = $2", statements[1].Sql)
+	assert.Equal([]interface{}{3, "widget"}, statements[1].Args)
+	assert.Equal("select count(*) as count from orders", statements[2].Sql)
+	assert.Nil(statements[2].Args)
+
+	staticStep := pipeline.GetStep("query.static").(*modconfig.PipelineStepQuery)
+	assert.Equal([]modconfig.QueryStatement{
+		{Sql: "delete from orders where item = :item", NamedArgs: map[string]interface{}{"item": "widget"}},
+	}, staticStep.Statements)
+	assert.True(staticStep.Equals(staticStep))
+	assert.False(staticStep.Equals(step))
+
+	inputs, err = staticStep.GetInputs(nil)
+	if err != nil {
+		assert.Fail("error getting inputs", err)
+		return
+	}
+	assert.Equal([]backend.Statement{{Sql: "delete from orders where item = ?", Args: []interface{}{"widget"}}}, inputs[schema.AttributeTypeStatements])
+
+	// the statements round trip through json
+	jsonBytes, err := json.Marshal(staticStep)
+	assert.Nil(err)
+	var decoded modconfig.PipelineStepQuery
+	assert.Nil(json.Unmarshal(jsonBytes, &decoded))
+	assert.Equal(staticStep.Statements, decoded.Statements)
+}
@@ -0,0 +1,96 @@
+package pipeline_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/turbot/pipe-fittings/load_mod"
+	"github.com/turbot/pipe-fittings/modconfig"
+)
+
+func TestScheduleTriggerOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	_, triggers, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/schedule_trigger_options.fp")
+	assert.Nil(err, "error found")
+
+	trigger := triggers["local.trigger.schedule.weekday_morning"]
+	if trigger == nil {
+		assert.Fail("weekday_morning trigger not found")
+		return
+	}
+
+	config, ok := trigger.Config.(*modconfig.TriggerSchedule)
+	if !ok {
+		assert.Fail("weekday_morning trigger is not a schedule trigger")
+		return
+	}
+
+	assert.Equal("Europe/London", config.Timezone)
+	assert.Equal(5*time.Minute, config.GetJitter())
+	assert.Equal([]string{"2024-12-25", "2024-12-26"}, config.ExcludeDates)
+	assert.Equal("./uk_bank_holidays.ics", config.Calendar)
+	assert.Equal([]string{"2024-08-26", "2024-05-27"}, config.CalendarDates)
+
+	jittered := config.ApplyJitter(time.Date(2024, 8, 23, 8, 0, 0, 0, time.UTC))
+	assert.False(jittered.Before(time.Date(2024, 8, 23, 8, 0, 0, 0, time.UTC)))
+	assert.True(jittered.Before(time.Date(2024, 8, 23, 8, 5, 0, 0, time.UTC)))
+
+	// Friday 23 August 2024, the Monday after is a bank holiday
+	london, _ := time.LoadLocation("Europe/London")
+	fireTimes, err := trigger.NextFireTimes(time.Date(2024, 8, 23, 10, 0, 0, 0, london), 3)
+	assert.Nil(err)
+	assert.Equal([]time.Time{
+		time.Date(2024, 8, 27, 9, 0, 0, 0, london),
+		time.Date(2024, 8, 28, 9, 0, 0, 0, london),
+		time.Date(2024, 8, 29, 9, 0, 0, 0, london),
+	}, fireTimes)
+
+	// excluded dates
+	fireTimes, err = trigger.NextFireTimes(time.Date(2024, 12, 24, 10, 0, 0, 0, london), 1)
+	assert.Nil(err)
+	assert.Equal([]time.Time{time.Date(2024, 12, 27, 9, 0, 0, 0, london)}, fireTimes)
+
+	// must be serialisable
+	_, err = json.Marshal(config)
+	assert.Nil(err)
+}
+
+func TestScheduleTriggerWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	_, triggers, err := load_mod.LoadPipelines(context.TODO(), "./pipelines/schedule_trigger_options.fp")
+	assert.Nil(err, "error found")
+
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	fireTimes, err := triggers["local.trigger.schedule.business_hours"].NextFireTimes(time.Date(2024, 3, 1, 15, 30, 0, 0, newYork), 3)
+	assert.Nil(err)
+	assert.Equal([]time.Time{
+		time.Date(2024, 3, 1, 16, 0, 0, 0, newYork),
+		time.Date(2024, 3, 2, 9, 0, 0, 0, newYork),
+		time.Date(2024, 3, 2, 10, 0, 0, 0, newYork),
+	}, fireTimes)
+
+	// window spanning midnight
+	fireTimes, err = triggers["local.trigger.schedule.overnight"].NextFireTimes(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 5)
+	assert.Nil(err)
+	assert.Equal([]time.Time{
+		time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 22, 0, 0, 0, time.UTC),
+	}, fireTimes)
+
+	// query trigger without a schedule runs every 15 minutes
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	queryTrigger := triggers["local.trigger.query.query_weekdays"]
+	fireTimes, err = queryTrigger.NextFireTimes(time.Date(2023, 12, 31, 23, 50, 0, 0, tokyo), 2)
+	assert.Nil(err)
+	assert.Equal(2, len(fireTimes))
+	assert.Equal("2024-01-02", fireTimes[0].In(tokyo).Format("2006-01-02"))
+}
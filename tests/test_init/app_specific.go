@@ -50,6 +50,7 @@ func SetAppSpecificConstants() {
 		connection.NewJumpCloudConnection,
 		connection.NewMastodonConnection,
 		connection.NewMicrosoftTeamsConnection,
+		connection.NewMysqlConnection,
 		connection.NewOktaConnection,
 		connection.NewOpenAIConnection,
 		connection.NewOpsgenieConnection,
@@ -0,0 +1,283 @@
+// Package wasmhost is a reference host for function steps with the wasm runtime. It runs a WASI preview 1 module
+// in-process with wazero, a pure Go WebAssembly runtime, so functions can run where no container daemon is available.
+//
+// The module is run as a WASI command: the step event is written to its stdin as JSON and its stdout is the response,
+// decoded as JSON when it is valid JSON. The module fails the step by exiting with a non-zero exit code.
+package wasmhost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/perr"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+const (
+	StatusFinished = "finished"
+	StatusFailed   = "failed"
+)
+
+// wasm memory is allocated in 64KiB pages
+const pagesPerMb = 16
+
+var errFuelExhausted = errors.New("fuel exhausted")
+
+// Config is a run of a wasm module, built from the inputs of a function step
+type Config struct {
+	// Name is the fully qualified name of the step
+	Name string
+	// Source is the path of the .wasm module
+	Source string
+	// Handler is the exported function to call, defaults to the WASI _start entry point
+	Handler string
+	Event   map[string]interface{}
+	Env     map[string]string
+
+	// MemoryLimit is the maximum memory of the module in MB
+	MemoryLimit *int64
+	// FuelLimit is the maximum number of function calls the module may make
+	FuelLimit *int64
+	// AllowedEnv are the names of the host environment variables passed through to the module
+	AllowedEnv []string
+}
+
+// ConfigFromInput returns the run configuration of the inputs of a function step with the wasm runtime
+func ConfigFromInput(input modconfig.Input) (*Config, error) {
+	if runtime, _ := input[schema.AttributeTypeRuntime].(string); runtime != modconfig.FunctionRuntimeWasm {
+		return nil, perr.BadRequestWithMessage(fmt.Sprintf("unsupported runtime %s: the wasm host only runs the %s runtime", runtime, modconfig.FunctionRuntimeWasm))
+	}
+
+	c := &Config{
+		Handler: modconfig.FunctionWasmDefaultHandler,
+	}
+	c.Name, _ = input[schema.LabelName].(string)
+
+	source, ok := input[schema.AttributeTypeSource].(string)
+	if !ok {
+		return nil, perr.BadRequestWithMessage("source must be the path to a .wasm module")
+	}
+	if err := modconfig.ValidateFunctionWasmSource(source); err != nil {
+		return nil, perr.BadRequestWithMessage(err.Error())
+	}
+	c.Source = source
+
+	if handler, ok := input[schema.AttributeTypeHandler].(string); ok && handler != "" {
+		c.Handler = handler
+	}
+	if event, ok := input[schema.AttributeTypeEvent].(map[string]interface{}); ok {
+		c.Event = event
+	}
+	if env, ok := input[schema.AttributeTypeEnv].(map[string]string); ok {
+		c.Env = env
+	}
+
+	if wasm, ok := input[schema.BlockTypeWasm].(map[string]interface{}); ok {
+		if v, ok := wasm[schema.AttributeTypeMemoryLimit].(int64); ok {
+			c.MemoryLimit = &v
+		}
+		if v, ok := wasm[schema.AttributeTypeFuelLimit].(int64); ok {
+			c.FuelLimit = &v
+		}
+		if v, ok := wasm[schema.AttributeTypeAllowedEnv].([]string); ok {
+			c.AllowedEnv = v
+		}
+	}
+
+	return c, nil
+}
+
+// Run runs the module of a function step with the wasm runtime against the step inputs
+func Run(ctx context.Context, input modconfig.Input) (*modconfig.Output, error) {
+	c, err := ConfigFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	return c.Run(ctx)
+}
+
+// ModuleEnv returns the environment of the module: the allowed host environment variables which are set, overridden
+// by the env of the step
+func (c *Config) ModuleEnv() map[string]string {
+	env := map[string]string{}
+	for _, name := range c.AllowedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	for name, value := range c.Env {
+		env[name] = value
+	}
+	return env
+}
+
+// Run runs the module. An error is returned when the module can not be loaded or compiled, a module which traps,
+// exits with a non-zero exit code, runs out of fuel or is interrupted by the context returns a failed output.
+func (c *Config) Run(ctx context.Context) (*modconfig.Output, error) {
+	module, err := os.ReadFile(c.Source)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("unable to read wasm module " + c.Source + ": " + err.Error())
+	}
+
+	event := c.Event
+	if event == nil {
+		event = map[string]interface{}{}
+	}
+	eventJson, err := json.Marshal(event)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("unable to encode the event as json: " + err.Error())
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if c.FuelLimit != nil {
+		ctx = experimental.WithFunctionListenerFactory(ctx, &fuelMeter{remaining: *c.FuelLimit, cancel: cancel})
+	}
+
+	// closing the module when the context is done interrupts a module which loops forever
+	runtimeConfig := wazero.NewRuntimeConfig().WithCloseOnContextDone(true)
+	if c.MemoryLimit != nil {
+		runtimeConfig = runtimeConfig.WithMemoryLimitPages(uint32(*c.MemoryLimit * pagesPerMb)) //nolint:gosec // the memory limit is validated to be at most 4096 MB
+	}
+
+	r := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+	defer r.Close(ctx)
+
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+
+	compiled, err := r.CompileModule(ctx, module)
+	if err != nil {
+		return nil, perr.BadRequestWithMessage("unable to compile wasm module " + c.Source + ": " + err.Error())
+	}
+
+	if c.Handler != modconfig.FunctionWasmDefaultHandler {
+		fn, ok := compiled.ExportedFunctions()[c.Handler]
+		if !ok {
+			return nil, perr.BadRequestWithMessage("the wasm module " + c.Source + " does not export the handler " + c.Handler)
+		}
+		if len(fn.ParamTypes()) > 0 {
+			return nil, perr.BadRequestWithMessage("the handler " + c.Handler + " must not take parameters, the event is read from stdin")
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(c.Source).
+		WithStdin(bytes.NewReader(eventJson)).
+		WithStdout(&stdout).
+		WithStderr(&stderr)
+
+	env := c.ModuleEnv()
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		moduleConfig = moduleConfig.WithEnv(name, env[name])
+	}
+
+	// a handler other than _start is called once the module is instantiated
+	if c.Handler != modconfig.FunctionWasmDefaultHandler {
+		moduleConfig = moduleConfig.WithStartFunctions()
+	}
+
+	mod, err := r.InstantiateModule(ctx, compiled, moduleConfig)
+	if err == nil && c.Handler != modconfig.FunctionWasmDefaultHandler {
+		_, err = mod.ExportedFunction(c.Handler).Call(ctx)
+	}
+
+	exitCode := uint32(0)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+		if exitCode == 0 {
+			err = nil
+		}
+	}
+
+	output := &modconfig.Output{
+		Status: StatusFinished,
+		Data: modconfig.OutputData{
+			schema.AttributeTypeResponse: parseResponse(stdout.String()),
+			schema.AttributeTypeStderr:   stderr.String(),
+			schema.AttributeTypeExitCode: exitCode,
+		},
+	}
+
+	if err != nil {
+		output.Status = StatusFailed
+		output.Errors = []modconfig.StepError{
+			{
+				Step:  c.Name,
+				Error: perr.ExecutionErrorWithMessage(c.failureMessage(ctx, err, exitCode)),
+			},
+		}
+	}
+
+	return output, nil
+}
+
+func (c *Config) failureMessage(ctx context.Context, err error, exitCode uint32) string {
+	switch {
+	case errors.Is(context.Cause(ctx), errFuelExhausted):
+		return fmt.Sprintf("the wasm module exhausted its fuel limit of %d", *c.FuelLimit)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "the wasm module timed out"
+	case ctx.Err() != nil:
+		return "the wasm module was cancelled"
+	case exitCode != 0:
+		return fmt.Sprintf("the wasm module exited with exit code %d", exitCode)
+	}
+	return "the wasm module failed: " + err.Error()
+}
+
+// parseResponse returns the stdout of the module decoded as json, or as is when it is not json
+func parseResponse(stdout string) interface{} {
+	trimmed := strings.TrimSpace(stdout)
+	if trimmed == "" {
+		return nil
+	}
+
+	var response interface{}
+	if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
+		return stdout
+	}
+	return response
+}
+
+// fuelMeter stops the module once it has made more function calls than its fuel limit, wazero has no instruction
+// metering so a function call is one unit of fuel
+type fuelMeter struct {
+	remaining int64
+	cancel    context.CancelCauseFunc
+}
+
+func (f *fuelMeter) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return f
+}
+
+func (f *fuelMeter) Before(context.Context, api.Module, api.FunctionDefinition, []uint64, experimental.StackIterator) {
+	if atomic.AddInt64(&f.remaining, -1) < 0 {
+		f.cancel(errFuelExhausted)
+	}
+}
+
+func (f *fuelMeter) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+func (f *fuelMeter) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
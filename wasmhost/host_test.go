@@ -0,0 +1,273 @@
+package wasmhost
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/schema"
+)
+
+// testModule returns a WASI module with the given minimum memory in pages, which exports:
+//   - _start, which echoes stdin to stdout
+//   - fail, which exits with exit code 3
+//   - burn, which calls an empty function forever
+func testModule(memoryPages byte) []byte {
+	const (
+		i32Const = 0x41
+		i32Load  = 0x28
+		i32Store = 0x36
+		call     = 0x10
+		drop     = 0x1a
+		loop     = 0x03
+		br       = 0x0c
+		end      = 0x0b
+	)
+	const (
+		fdRead = iota
+		fdWrite
+		procExit
+		start
+		fail
+		burn
+		nop
+	)
+
+	echo := []byte{
+		// iovec at 0: buf 1024, len 4096
+		i32Const, 0, i32Const, 0x80, 0x08, i32Store, 2, 0,
+		i32Const, 4, i32Const, 0x80, 0x20, i32Store, 2, 0,
+		// fd_read(stdin, iovs 0, 1, nread 8)
+		i32Const, 0, i32Const, 0, i32Const, 1, i32Const, 8, call, fdRead, drop,
+		// iovec len = nread
+		i32Const, 4, i32Const, 8, i32Load, 2, 0, i32Store, 2, 0,
+		// fd_write(stdout, iovs 0, 1, nwritten 12)
+		i32Const, 1, i32Const, 0, i32Const, 1, i32Const, 12, call, fdWrite, drop,
+		end,
+	}
+	exit := []byte{i32Const, 3, call, procExit, end}
+	spin := []byte{loop, 0x40, call, nop, br, 0, end, end}
+	empty := []byte{end}
+
+	var module []byte
+	module = append(module, 0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00)
+
+	// types: 0 (i32, i32, i32, i32) -> i32, 1 () -> (), 2 (i32) -> ()
+	module = appendSection(module, 1, vector(
+		[]byte{0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7f},
+		[]byte{0x60, 0, 0},
+		[]byte{0x60, 1, 0x7f, 0},
+	))
+	module = appendSection(module, 2, vector(
+		append(append(name("wasi_snapshot_preview1"), name("fd_read")...), 0x00, 0),
+		append(append(name("wasi_snapshot_preview1"), name("fd_write")...), 0x00, 0),
+		append(append(name("wasi_snapshot_preview1"), name("proc_exit")...), 0x00, 2),
+	))
+	module = appendSection(module, 3, vector([]byte{1}, []byte{1}, []byte{1}, []byte{1}))
+	module = appendSection(module, 5, vector([]byte{0x00, memoryPages}))
+	module = appendSection(module, 7, vector(
+		append(name("memory"), 0x02, 0),
+		append(name("_start"), 0x00, start),
+		append(name("fail"), 0x00, fail),
+		append(name("burn"), 0x00, burn),
+	))
+	module = appendSection(module, 10, vector(
+		functionBody(echo), functionBody(exit), functionBody(spin), functionBody(empty),
+	))
+
+	return module
+}
+
+func appendSection(module []byte, id byte, content []byte) []byte {
+	module = append(module, id)
+	module = appendUleb(module, len(content))
+	return append(module, content...)
+}
+
+func vector(items ...[]byte) []byte {
+	v := appendUleb(nil, len(items))
+	for _, item := range items {
+		v = append(v, item...)
+	}
+	return v
+}
+
+func name(s string) []byte {
+	return append(appendUleb(nil, len(s)), s...)
+}
+
+func functionBody(code []byte) []byte {
+	// no locals
+	body := append([]byte{0}, code...)
+	return append(appendUleb(nil, len(body)), body...)
+}
+
+func appendUleb(b []byte, v int) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b = append(b, c|0x80)
+			continue
+		}
+		return append(b, c)
+	}
+}
+
+func writeModule(t *testing.T, module []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "function.wasm")
+	if err := os.WriteFile(path, module, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	source := writeModule(t, testModule(1))
+
+	output, err := Run(context.Background(), modconfig.Input{
+		schema.LabelName:            "local.pipeline.wasm.function.echo",
+		schema.AttributeTypeRuntime: modconfig.FunctionRuntimeWasm,
+		schema.AttributeTypeSource:  source,
+		schema.AttributeTypeEvent:   map[string]interface{}{"order": "widget", "quantity": 2},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if output.Status != StatusFinished {
+		t.Fatalf("Run() status = %s, errors %v", output.Status, output.Errors)
+	}
+
+	want := map[string]interface{}{"order": "widget", "quantity": float64(2)}
+	if !reflect.DeepEqual(output.Data[schema.AttributeTypeResponse], want) {
+		t.Errorf("Run() response = %v, want %v", output.Data[schema.AttributeTypeResponse], want)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	source := writeModule(t, testModule(1))
+	fuelLimit := int64(1000)
+
+	tests := []struct {
+		name         string
+		config       Config
+		timeout      time.Duration
+		wantExitCode uint32
+		wantError    string
+	}{
+		{
+			name:         "non-zero exit code",
+			config:       Config{Handler: "fail"},
+			wantExitCode: 3,
+			wantError:    "exit code 3",
+		},
+		{
+			name:      "fuel limit",
+			config:    Config{Handler: "burn", FuelLimit: &fuelLimit},
+			wantError: "fuel limit of 1000",
+		},
+		{
+			name:      "timeout",
+			config:    Config{Handler: "burn"},
+			timeout:   100 * time.Millisecond,
+			wantError: "timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			c := tt.config
+			c.Source = source
+			output, err := c.Run(ctx)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if output.Status != StatusFailed || len(output.Errors) != 1 {
+				t.Fatalf("Run() status = %s, errors %v, expected a failure", output.Status, output.Errors)
+			}
+			if !strings.Contains(output.Errors[0].Error.Detail, tt.wantError) {
+				t.Errorf("Run() error = %s, want %s", output.Errors[0].Error.Detail, tt.wantError)
+			}
+			if tt.wantExitCode != 0 && output.Data[schema.AttributeTypeExitCode] != tt.wantExitCode {
+				t.Errorf("Run() exit code = %v, want %d", output.Data[schema.AttributeTypeExitCode], tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestRunMemoryLimit(t *testing.T) {
+	memoryLimit := int64(1)
+
+	// 17 pages is more than 1MB
+	c := Config{Source: writeModule(t, testModule(17)), Handler: modconfig.FunctionWasmDefaultHandler, MemoryLimit: &memoryLimit}
+	if _, err := c.Run(context.Background()); err == nil {
+		t.Errorf("Run() expected an error for a module over the memory limit")
+	}
+
+	c.Source = writeModule(t, testModule(16))
+	output, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if output.Status != StatusFinished {
+		t.Errorf("Run() status = %s, errors %v", output.Status, output.Errors)
+	}
+}
+
+func TestModuleEnv(t *testing.T) {
+	t.Setenv("WASMHOST_TEST_ALLOWED", "host")
+	t.Setenv("WASMHOST_TEST_OVERRIDDEN", "host")
+	t.Setenv("WASMHOST_TEST_DENIED", "host")
+
+	c := Config{
+		AllowedEnv: []string{"WASMHOST_TEST_ALLOWED", "WASMHOST_TEST_OVERRIDDEN", "WASMHOST_TEST_UNSET"},
+		Env:        map[string]string{"WASMHOST_TEST_OVERRIDDEN": "step", "FOO": "bar"},
+	}
+
+	want := map[string]string{
+		"WASMHOST_TEST_ALLOWED":    "host",
+		"WASMHOST_TEST_OVERRIDDEN": "step",
+		"FOO":                      "bar",
+	}
+	if got := c.ModuleEnv(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ModuleEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigFromInput(t *testing.T) {
+	if _, err := ConfigFromInput(modconfig.Input{
+		schema.AttributeTypeRuntime: "nodejs",
+		schema.AttributeTypeSource:  "./my-function",
+	}); err == nil {
+		t.Errorf("ConfigFromInput() expected an error for the nodejs runtime")
+	}
+
+	c, err := ConfigFromInput(modconfig.Input{
+		schema.AttributeTypeRuntime: modconfig.FunctionRuntimeWasm,
+		schema.AttributeTypeSource:  "./function.wasm",
+		schema.BlockTypeWasm: map[string]interface{}{
+			schema.AttributeTypeMemoryLimit: int64(64),
+			schema.AttributeTypeFuelLimit:   int64(1000000),
+			schema.AttributeTypeAllowedEnv:  []string{"HOME"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ConfigFromInput() error = %v", err)
+	}
+	if c.Handler != modconfig.FunctionWasmDefaultHandler || *c.MemoryLimit != 64 || *c.FuelLimit != 1000000 || !reflect.DeepEqual(c.AllowedEnv, []string{"HOME"}) {
+		t.Errorf("ConfigFromInput() = %+v", c)
+	}
+}
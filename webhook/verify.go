@@ -0,0 +1,207 @@
+// Package webhook verifies the signatures of the webhook requests received by HTTP triggers.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const (
+	SignatureTypeGithub     = "github"
+	SignatureTypeSlack      = "slack"
+	SignatureTypeStripe     = "stripe"
+	SignatureTypeHmacSha256 = "hmac_sha256"
+
+	HeaderGithubSignature = "X-Hub-Signature-256"
+	HeaderSlackSignature  = "X-Slack-Signature"
+	HeaderSlackTimestamp  = "X-Slack-Request-Timestamp"
+	HeaderStripeSignature = "Stripe-Signature"
+
+	// DefaultHmacSha256Header is the header holding the signature of the generic HMAC SHA-256 verifier
+	DefaultHmacSha256Header = "X-Signature-256"
+
+	// DefaultTolerance is the maximum age of a signed timestamp (Slack and Stripe) to protect against replay attacks
+	DefaultTolerance = 5 * time.Minute
+)
+
+var ValidSignatureTypes = []string{SignatureTypeGithub, SignatureTypeSlack, SignatureTypeStripe, SignatureTypeHmacSha256}
+
+func IsValidSignatureType(signatureType string) bool {
+	return slices.Contains(ValidSignatureTypes, signatureType)
+}
+
+// Verifier checks the HMAC SHA-256 signature of a webhook request
+type Verifier struct {
+	Type   string
+	Secret string
+
+	// Header and Prefix only apply to the generic hmac_sha256 type, the signature is the hex (or base64) encoded
+	// HMAC of the body, optionally prefixed, e.g. "sha256="
+	Header string
+	Prefix string
+
+	// Tolerance only applies to the types which sign a timestamp (slack and stripe)
+	Tolerance time.Duration
+
+	// Now returns the current time, it can be overridden for testing
+	Now func() time.Time
+}
+
+func NewVerifier(signatureType, secret string) *Verifier {
+	return &Verifier{
+		Type:      signatureType,
+		Secret:    secret,
+		Tolerance: DefaultTolerance,
+		Now:       time.Now,
+	}
+}
+
+// Verify returns an unauthorized error if the request headers do not carry a valid signature for the body
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	if v.Secret == "" {
+		return perr.InternalWithMessage("webhook signature secret is not set")
+	}
+
+	switch v.Type {
+	case SignatureTypeGithub:
+		return v.verifyPrefixed(header.Get(HeaderGithubSignature), "sha256=", false, body)
+	case SignatureTypeSlack:
+		return v.verifySlack(header, body)
+	case SignatureTypeStripe:
+		return v.verifyStripe(header, body)
+	case SignatureTypeHmacSha256:
+		headerName := v.Header
+		if headerName == "" {
+			headerName = DefaultHmacSha256Header
+		}
+		return v.verifyPrefixed(header.Get(headerName), v.Prefix, true, body)
+	}
+
+	return perr.BadRequestWithMessage("unsupported webhook signature type: " + v.Type)
+}
+
+func (v *Verifier) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(v.Secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// verifyPrefixed checks a hex encoded signature following the prefix, or a base64 encoded one if allowBase64 is set
+func (v *Verifier) verifyPrefixed(signature, prefix string, allowBase64 bool, body []byte) error {
+	if signature == "" {
+		return perr.UnauthorizedWithMessage("missing webhook signature")
+	}
+
+	if !strings.HasPrefix(signature, prefix) {
+		return perr.UnauthorizedWithMessage("invalid webhook signature")
+	}
+	signature = strings.TrimPrefix(signature, prefix)
+
+	expected := v.sign(body)
+
+	if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return nil
+	}
+	if !allowBase64 {
+		return perr.UnauthorizedWithMessage("invalid webhook signature")
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return nil
+	}
+
+	return perr.UnauthorizedWithMessage("invalid webhook signature")
+}
+
+func (v *Verifier) verifySlack(header http.Header, body []byte) error {
+	timestamp := header.Get(HeaderSlackTimestamp)
+	signature := header.Get(HeaderSlackSignature)
+	if timestamp == "" || signature == "" {
+		return perr.UnauthorizedWithMessage("missing webhook signature")
+	}
+
+	if err := v.checkTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	payload := []byte("v0:" + timestamp + ":" + string(body))
+	expected := "v0=" + hex.EncodeToString(v.sign(payload))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return perr.UnauthorizedWithMessage("invalid webhook signature")
+	}
+
+	return nil
+}
+
+// verifyStripe checks the Stripe-Signature header, e.g. "t=1492774577,v1=5257a869...,v1=...". Any of the v1
+// signatures may match, Stripe sends more than one while a secret is being rolled.
+func (v *Verifier) verifyStripe(header http.Header, body []byte) error {
+	signatureHeader := header.Get(HeaderStripeSignature)
+	if signatureHeader == "" {
+		return perr.UnauthorizedWithMessage("missing webhook signature")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return perr.UnauthorizedWithMessage("invalid webhook signature")
+	}
+
+	if err := v.checkTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	expected := v.sign([]byte(timestamp + "." + string(body)))
+	for _, signature := range signatures {
+		if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return perr.UnauthorizedWithMessage("invalid webhook signature")
+}
+
+func (v *Verifier) checkTimestamp(timestamp string) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return perr.UnauthorizedWithMessage("invalid webhook signature timestamp")
+	}
+
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	age := now().Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return perr.UnauthorizedWithMessage("webhook signature timestamp is outside the tolerance")
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/perr"
+)
+
+const slackExampleBody = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+
+func testSign(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Unix(1531420618, 0)
+	stripeSignature := hex.EncodeToString(testSign("whsec_test", "1531420618.{\"id\":\"evt_1\"}"))
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		header   map[string]string
+		body     string
+		wantErr  bool
+	}{
+		{
+			name:     "github",
+			verifier: NewVerifier(SignatureTypeGithub, "It's a Secret to Everybody"),
+			header:   map[string]string{HeaderGithubSignature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"},
+			body:     "Hello, World!",
+		},
+		{
+			name:     "github - tampered body",
+			verifier: NewVerifier(SignatureTypeGithub, "It's a Secret to Everybody"),
+			header:   map[string]string{HeaderGithubSignature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"},
+			body:     "Hello, World?",
+			wantErr:  true,
+		},
+		{
+			name:     "github - base64",
+			verifier: NewVerifier(SignatureTypeGithub, "secret"),
+			header:   map[string]string{HeaderGithubSignature: "sha256=" + base64.StdEncoding.EncodeToString(testSign("secret", "payload"))},
+			body:     "payload",
+			wantErr:  true,
+		},
+		{
+			name:     "github - missing header",
+			verifier: NewVerifier(SignatureTypeGithub, "It's a Secret to Everybody"),
+			body:     "Hello, World!",
+			wantErr:  true,
+		},
+		{
+			name:     "slack",
+			verifier: &Verifier{Type: SignatureTypeSlack, Secret: "8f742231b10e8888abcd99yyyzzz85a5", Now: func() time.Time { return now }},
+			header: map[string]string{
+				HeaderSlackTimestamp: "1531420618",
+				HeaderSlackSignature: "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503",
+			},
+			body: slackExampleBody,
+		},
+		{
+			name:     "slack - replayed",
+			verifier: &Verifier{Type: SignatureTypeSlack, Secret: "8f742231b10e8888abcd99yyyzzz85a5", Now: func() time.Time { return now.Add(10 * time.Minute) }},
+			header: map[string]string{
+				HeaderSlackTimestamp: "1531420618",
+				HeaderSlackSignature: "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503",
+			},
+			body:    slackExampleBody,
+			wantErr: true,
+		},
+		{
+			name:     "stripe",
+			verifier: &Verifier{Type: SignatureTypeStripe, Secret: "whsec_test", Now: func() time.Time { return now }},
+			header:   map[string]string{HeaderStripeSignature: "t=1531420618,v1=" + hex.EncodeToString(testSign("old_secret", "x")) + ",v1=" + stripeSignature + ",v0=abc"},
+			body:     `{"id":"evt_1"}`,
+		},
+		{
+			name:     "stripe - wrong secret",
+			verifier: &Verifier{Type: SignatureTypeStripe, Secret: "whsec_other", Now: func() time.Time { return now }},
+			header:   map[string]string{HeaderStripeSignature: "t=1531420618,v1=" + stripeSignature},
+			body:     `{"id":"evt_1"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "hmac_sha256 - default header",
+			verifier: NewVerifier(SignatureTypeHmacSha256, "secret"),
+			header:   map[string]string{DefaultHmacSha256Header: hex.EncodeToString(testSign("secret", "payload"))},
+			body:     "payload",
+		},
+		{
+			name:     "hmac_sha256 - custom header, prefix and base64",
+			verifier: &Verifier{Type: SignatureTypeHmacSha256, Secret: "secret", Header: "X-Acme-Signature", Prefix: "sha256="},
+			header:   map[string]string{"X-Acme-Signature": "sha256=" + base64.StdEncoding.EncodeToString(testSign("secret", "payload"))},
+			body:     "payload",
+		},
+		{
+			name:     "hmac_sha256 - missing prefix",
+			verifier: &Verifier{Type: SignatureTypeHmacSha256, Secret: "secret", Header: "X-Acme-Signature", Prefix: "sha256="},
+			header:   map[string]string{"X-Acme-Signature": hex.EncodeToString(testSign("secret", "payload"))},
+			body:     "payload",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+
+			err := tt.verifier.Verify(header, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !perr.IsUnauthorized(err) {
+				t.Errorf("Verify() error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
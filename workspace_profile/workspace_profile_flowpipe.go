@@ -2,12 +2,14 @@ package workspace_profile
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/spf13/cobra"
 	"github.com/turbot/pipe-fittings/constants"
 	"github.com/turbot/pipe-fittings/cty_helpers"
 	"github.com/turbot/pipe-fittings/hclhelpers"
+	"github.com/turbot/pipe-fittings/httpclient"
 	"github.com/turbot/pipe-fittings/options"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -35,6 +37,14 @@ type FlowpipeWorkspaceProfile struct {
 	ProcessRetention        *int    `hcl:"process_retention" cty:"process_retention"`
 	BaseUrl                 *string `hcl:"base_url" cty:"base_url"`
 
+	// transport settings shared by the http steps, the http based integrations and the credential resolvers
+	HttpProxyUrl       *string  `hcl:"http_proxy_url" cty:"http_proxy_url"`
+	HttpNoProxy        []string `hcl:"http_no_proxy" cty:"http_no_proxy"`
+	HttpConnectTimeout *string  `hcl:"http_connect_timeout" cty:"http_connect_timeout"`
+	HttpTimeout        *string  `hcl:"http_timeout" cty:"http_timeout"`
+	HttpMaxRedirects   *int     `hcl:"http_max_redirects" cty:"http_max_redirects"`
+	Http2              *bool    `hcl:"http2" cty:"http2"`
+
 	DeclRange hcl.Range
 }
 
@@ -52,7 +62,45 @@ func (p *FlowpipeWorkspaceProfile) CtyValue() (cty.Value, error) {
 
 func (p *FlowpipeWorkspaceProfile) OnDecoded() hcl.Diagnostics {
 	p.setBaseProperties()
-	return nil
+	return p.validateHttpTransport()
+}
+
+func (p *FlowpipeWorkspaceProfile) validateHttpTransport() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	if p.HttpProxyUrl != nil {
+		if err := httpclient.ValidateProxyUrl(*p.HttpProxyUrl); err != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid http_proxy_url in %s", p.Name()),
+				Detail:   err.Error(),
+				Subject:  &p.DeclRange,
+			})
+		}
+	}
+
+	for name, timeout := range map[string]*string{"http_connect_timeout": p.HttpConnectTimeout, "http_timeout": p.HttpTimeout} {
+		if timeout == nil {
+			continue
+		}
+		if d, err := time.ParseDuration(*timeout); err != nil || d < 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid %s in %s: %s. Specify a duration such as 5s or 1m", name, p.Name(), *timeout),
+				Subject:  &p.DeclRange,
+			})
+		}
+	}
+
+	if p.HttpMaxRedirects != nil && *p.HttpMaxRedirects < 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid http_max_redirects in %s: must not be negative", p.Name()),
+			Subject:  &p.DeclRange,
+		})
+	}
+
+	return diags
 }
 
 func (p *FlowpipeWorkspaceProfile) setBaseProperties() {
@@ -114,6 +162,24 @@ func (p *FlowpipeWorkspaceProfile) setBaseProperties() {
 	if p.BaseUrl == nil {
 		p.BaseUrl = p.Base.BaseUrl
 	}
+	if p.HttpProxyUrl == nil {
+		p.HttpProxyUrl = p.Base.HttpProxyUrl
+	}
+	if p.HttpNoProxy == nil {
+		p.HttpNoProxy = p.Base.HttpNoProxy
+	}
+	if p.HttpConnectTimeout == nil {
+		p.HttpConnectTimeout = p.Base.HttpConnectTimeout
+	}
+	if p.HttpTimeout == nil {
+		p.HttpTimeout = p.Base.HttpTimeout
+	}
+	if p.HttpMaxRedirects == nil {
+		p.HttpMaxRedirects = p.Base.HttpMaxRedirects
+	}
+	if p.Http2 == nil {
+		p.Http2 = p.Base.Http2
+	}
 }
 
 // ConfigMap creates a config map containing all options to pass to viper
@@ -138,6 +204,12 @@ func (p *FlowpipeWorkspaceProfile) ConfigMap(cmd *cobra.Command) map[string]inte
 	res.SetIntItem(p.MaxConcurrencyQuery, constants.ArgMaxConcurrencyQuery)
 	res.SetIntItem(p.ProcessRetention, constants.ArgProcessRetention)
 	res.SetStringItem(p.BaseUrl, constants.ArgBaseUrl)
+	res.SetStringItem(p.HttpProxyUrl, constants.ArgHttpProxyUrl)
+	res.SetStringSliceItem(p.HttpNoProxy, constants.ArgHttpNoProxy)
+	res.SetStringItem(p.HttpConnectTimeout, constants.ArgHttpConnectTimeout)
+	res.SetStringItem(p.HttpTimeout, constants.ArgHttpTimeout)
+	res.SetIntItem(p.HttpMaxRedirects, constants.ArgHttpMaxRedirects)
+	res.SetBoolItem(p.Http2, constants.ArgHttp2)
 
 	return res
 }